@@ -0,0 +1,43 @@
+package assets
+
+import (
+	"embed"
+	"path"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+//go:embed icons/*.svg
+var icons embed.FS
+
+func load(name string) fyne.Resource {
+	data, err := icons.ReadFile(path.Join("icons", name))
+	if err != nil {
+		panic(err)
+	}
+	return fyne.NewStaticResource(name, data)
+}
+
+var priorityFlag = load("priority.svg")
+
+// Symbole der Anwendung. Termin und Aufgabe folgen der Textfarbe des
+// Farbschemas, die Tray-Symbole sind fest eingefärbt.
+var (
+	AppIcon         = load("app.svg")
+	AppointmentIcon = theme.NewThemedResource(load("appointment.svg"))
+	TaskIcon        = theme.NewThemedResource(load("task.svg"))
+
+	TrayIcon      = load("tray.svg")       // keine Erinnerung in Kürze
+	TraySoonIcon  = load("tray-soon.svg")  // nächster Termin beginnt bald
+	TrayQuietIcon = load("tray-quiet.svg") // Nicht stören oder Pause
+)
+
+// PriorityIcon liefert ein Fähnchen in der Farbe der Prioritätsstufe, ohne
+// Priorität nil
+func PriorityIcon(p *int) fyne.Resource {
+	if p == nil {
+		return nil
+	}
+	return theme.NewColoredResource(priorityFlag, PriorityColorName(*p))
+}
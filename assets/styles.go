@@ -0,0 +1,130 @@
+// Package assets enthält das Farbschema der Oberfläche und die
+// eingebetteten Symbole
+package assets
+
+import (
+	"image/color"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/priority"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Farben der Prioritätsstufen, z.B. für die Fähnchen in den Listen
+const (
+	ColorNamePriorityLow      fyne.ThemeColorName = "priorityLow"
+	ColorNamePriorityNormal   fyne.ThemeColorName = "priorityNormal"
+	ColorNamePriorityHigh     fyne.ThemeColorName = "priorityHigh"
+	ColorNamePriorityCritical fyne.ThemeColorName = "priorityCritical"
+)
+
+// PriorityColorName liefert die Farbe zu einem Prioritätswert; eigene
+// Stufen zwischen den Standardwerten bekommen die nächstniedrigere
+func PriorityColorName(value int) fyne.ThemeColorName {
+	switch {
+	case value >= priority.Critical:
+		return ColorNamePriorityCritical
+	case value >= priority.High:
+		return ColorNamePriorityHigh
+	case value >= priority.Normal:
+		return ColorNamePriorityNormal
+	}
+	return ColorNamePriorityLow
+}
+
+func rgb(r, g, b uint8) color.NRGBA {
+	return color.NRGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+// Prioritätsfarben je Schema: hell, dunkel, hoher Kontrast
+var priorityColors = map[fyne.ThemeColorName][3]color.Color{
+	ColorNamePriorityLow:      {rgb(0x60, 0x7d, 0x8b), rgb(0x90, 0xa4, 0xae), rgb(0xc0, 0xc0, 0xc0)},
+	ColorNamePriorityNormal:   {rgb(0x1e, 0x88, 0xe5), rgb(0x64, 0xb5, 0xf6), rgb(0x00, 0xbf, 0xff)},
+	ColorNamePriorityHigh:     {rgb(0xf5, 0x7c, 0x00), rgb(0xff, 0xb7, 0x4d), rgb(0xff, 0xa5, 0x00)},
+	ColorNamePriorityCritical: {rgb(0xd3, 0x2f, 0x2f), rgb(0xef, 0x53, 0x50), rgb(0xff, 0x30, 0x30)},
+}
+
+// Hoher Kontrast: Weiß auf Schwarz, Gelb für Auswahl und Fokus. Nicht
+// aufgeführte Farben kommen aus dem dunklen Standardschema.
+var highContrastColors = map[fyne.ThemeColorName]color.Color{
+	theme.ColorNameBackground:          color.Black,
+	theme.ColorNameForeground:          color.White,
+	theme.ColorNameButton:              rgb(0x33, 0x33, 0x33),
+	theme.ColorNameDisabledButton:      rgb(0x26, 0x26, 0x26),
+	theme.ColorNameDisabled:            rgb(0xa0, 0xa0, 0xa0),
+	theme.ColorNamePlaceHolder:         rgb(0xc0, 0xc0, 0xc0),
+	theme.ColorNameInputBackground:     color.Black,
+	theme.ColorNameInputBorder:         color.White,
+	theme.ColorNameHeaderBackground:    rgb(0x1a, 0x1a, 0x1a),
+	theme.ColorNameMenuBackground:      color.Black,
+	theme.ColorNameOverlayBackground:   color.Black,
+	theme.ColorNamePrimary:             rgb(0xff, 0xd7, 0x00),
+	theme.ColorNameForegroundOnPrimary: color.Black,
+	theme.ColorNameFocus:               color.NRGBA{R: 0xff, G: 0xd7, A: 0xaa},
+	theme.ColorNameSelection:           color.NRGBA{R: 0xff, G: 0xd7, A: 0x66},
+	theme.ColorNameHover:               color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x33},
+	theme.ColorNamePressed:             color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x66},
+	theme.ColorNameSeparator:           color.White,
+	theme.ColorNameScrollBar:           color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x99},
+	theme.ColorNameHyperlink:           rgb(0x00, 0xff, 0xff),
+	theme.ColorNameError:               rgb(0xff, 0x60, 0x60),
+	theme.ColorNameSuccess:             rgb(0x00, 0xff, 0x7f),
+	theme.ColorNameWarning:             rgb(0xff, 0xa5, 0x00),
+}
+
+// appTheme ist das Standardschema von Fyne mit fester Variante (oder der
+// des Desktops), den Prioritätsfarben und dem Schema mit hohem Kontrast
+type appTheme struct {
+	name string
+}
+
+// NewTheme liefert das Farbschema zu einem Namen aus der Konfiguration
+// (config.ThemeSystem, …); unbekannte Namen folgen dem Desktop
+func NewTheme(name string) fyne.Theme {
+	return appTheme{name: name}
+}
+
+func (t appTheme) Color(n fyne.ThemeColorName, v fyne.ThemeVariant) color.Color {
+	switch t.name {
+	case config.ThemeLight:
+		v = theme.VariantLight
+	case config.ThemeDark, config.ThemeHighContrast:
+		v = theme.VariantDark
+	}
+	if colors, ok := priorityColors[n]; ok {
+		switch {
+		case t.name == config.ThemeHighContrast:
+			return colors[2]
+		case v == theme.VariantDark:
+			return colors[1]
+		}
+		return colors[0]
+	}
+	if t.name == config.ThemeHighContrast {
+		if c, ok := highContrastColors[n]; ok {
+			return c
+		}
+	}
+	return theme.DefaultTheme().Color(n, v)
+}
+
+func (t appTheme) Font(s fyne.TextStyle) fyne.Resource {
+	return theme.DefaultTheme().Font(s)
+}
+
+func (t appTheme) Icon(n fyne.ThemeIconName) fyne.Resource {
+	return theme.DefaultTheme().Icon(n)
+}
+
+func (t appTheme) Size(n fyne.ThemeSizeName) float32 {
+	// Bei hohem Kontrast dickere Rahmen und Trennlinien
+	if t.name == config.ThemeHighContrast {
+		switch n {
+		case theme.SizeNameInputBorder, theme.SizeNameSeparatorThickness:
+			return 2 * theme.DefaultTheme().Size(n)
+		}
+	}
+	return theme.DefaultTheme().Size(n)
+}
@@ -0,0 +1,954 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/api"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/davsync"
+	"Reminder_Erinnerungs_App/internal/exchange"
+	"Reminder_Erinnerungs_App/internal/feeds"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/ics"
+	"Reminder_Erinnerungs_App/internal/mail"
+	"Reminder_Erinnerungs_App/internal/paths"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/push"
+	"Reminder_Erinnerungs_App/internal/quiet"
+	"Reminder_Erinnerungs_App/internal/reminder"
+	"Reminder_Erinnerungs_App/internal/sound"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/BurntSushi/toml"
+)
+
+const usage = `Verwendung: reminderctl [-config DATEI] [-db PFAD] <befehl> [optionen]
+
+Befehle:
+  appointments   Termine suchen und auflisten
+  tasks          Aufgaben suchen und auflisten
+  priorities     Prioritätsstufen anzeigen oder ändern
+  ack ID         Erinnerung an einen Termin bestätigen
+  sound          Lautstärke und Töne für Tags einstellen
+  dnd            Nicht stören und Ruhezeit anzeigen oder einstellen
+  config         Pfad und Inhalt der Konfiguration anzeigen
+  subscriptions  abonnierte Kalender (ICS-Dateien oder URLs) anzeigen oder ändern
+  sync           mit dem CalDAV-Server abgleichen
+  mail           Test-E-Mail über den SMTP-Server aus [mail] senden
+  push           Testnachricht über ntfy, Gotify oder Matrix aus [push] senden
+  import DATEI   Termine und Aufgaben aus einer .ics- oder .csv-Datei übernehmen
+  export DATEI   Termine und Aufgaben als .ics, .csv oder .json speichern ("-" = Standardausgabe)
+  undo           letzte Änderungen an Terminen und Aufgaben rückgängig machen
+  redo           rückgängig gemachte Änderungen wiederherstellen
+  history        letzte Änderungen an Terminen und Aufgaben anzeigen
+
+"reminderctl <befehl> -h" zeigt die Optionen eines Befehls.
+`
+
+// Prioritätsstufen aus der Datenbank, für Ein- und Ausgabe von Namen
+var levels = priority.Defaults()
+
+// Pfad der Konfigurationsdatei, für Befehle, die sie ändern
+var configPath string
+
+func main() {
+	log.SetFlags(0)
+
+	flags := config.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	i18n.SetLanguage(cfg.Locale)
+	configPath = flags.Path()
+
+	dbPath, err := paths.Database(cfg.DBPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := store.Open(dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	if l, err := s.PriorityLevels(); err == nil {
+		levels = l
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	switch cmd {
+	case "appointments":
+		err = listAppointments(s, args)
+	case "tasks":
+		err = listTasks(s, args)
+	case "priorities":
+		err = priorities(s, args)
+	case "ack":
+		err = acknowledge(s, args)
+	case "sound":
+		err = soundSettings(s, args)
+	case "dnd":
+		err = doNotDisturb(s, args)
+	case "config":
+		err = showConfig(cfg, dbPath)
+	case "subscriptions":
+		err = subscriptions(s, args)
+	case "sync":
+		err = syncCalDAV(s, cfg.CalDAV, args)
+	case "mail":
+		err = testMail(s, cfg, args)
+	case "push":
+		err = testPush(cfg.Push, args)
+	case "import":
+		err = importFile(s, args)
+	case "export":
+		err = exportFile(s, args)
+	case "undo":
+		err = undoRedo(s, "undo", args)
+	case "redo":
+		err = undoRedo(s, "redo", args)
+	case "history":
+		err = history(s, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// filterFlags registriert die gemeinsamen Such- und Filteroptionen
+func filterFlags(fs *flag.FlagSet) func() (store.Filter, error) {
+	text := fs.String("q", "", "Suchbegriffe (Titel und Notizen)")
+	from := fs.String("from", "", "Datum ab (YYYY-MM-DD oder TT.MM.JJJJ)")
+	to := fs.String("to", "", "Datum bis (YYYY-MM-DD oder TT.MM.JJJJ)")
+	prio := fs.String("priority", "", "nur diese Priorität (Name oder Zahl)")
+	tag := fs.String("tag", "", "nur Einträge mit diesem Tag")
+	sortBy := fs.String("sort", "", "Sortierung: title, date, time, priority, completed")
+	desc := fs.Bool("desc", false, "absteigend sortieren")
+
+	return func() (store.Filter, error) {
+		f := store.Filter{Text: *text, Tag: *tag, SortBy: *sortBy, Desc: *desc}
+		var err error
+		if f.From, err = parseDate(*from); err != nil {
+			return f, err
+		}
+		if f.To, err = parseDate(*to); err != nil {
+			return f, err
+		}
+		if *prio != "" {
+			p, err := levels.Parse(*prio)
+			if err != nil {
+				return f, err
+			}
+			f.Priority = &p
+		}
+		return f, nil
+	}
+}
+
+// parseDate akzeptiert ISO- und deutsches Datumsformat
+func parseDate(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("Ungültiges Datum: %s", s)
+}
+
+func listAppointments(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("appointments", flag.ExitOnError)
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+	appointments, err := s.QueryAppointments(f)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITEL\tDATUM\tUHRZEIT\tPRIORITÄT\tTAGS")
+	for _, a := range appointments {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			a.ID, a.Title, a.Date, a.Time, formatPriority(a.Priority), strings.Join(a.Tags, ","))
+	}
+	return w.Flush()
+}
+
+func listTasks(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("tasks", flag.ExitOnError)
+	filter := filterFlags(fs)
+	completed := fs.String("completed", "", "nur abgeschlossene (true) oder offene (false) Aufgaben")
+	fs.Parse(args)
+
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+	if *completed != "" {
+		c, err := strconv.ParseBool(*completed)
+		if err != nil {
+			return fmt.Errorf("Ungültiger Wert für -completed: %s", *completed)
+		}
+		f.Completed = &c
+	}
+	tasks, err := s.QueryTasks(f)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITEL\tSTATUS\tFÄLLIG\tPRIORITÄT\tTAGS")
+	for _, t := range tasks {
+		status := "offen"
+		if t.Completed {
+			status = "erledigt"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Title, status, t.DueDate, formatPriority(t.Priority), strings.Join(t.Tags, ","))
+	}
+	return w.Flush()
+}
+
+func formatPriority(p *int) string {
+	if p == nil {
+		return "-"
+	}
+	return levels.Lookup(p).Name
+}
+
+// priorities listet die Prioritätsstufen oder ändert mit -set eine davon
+func priorities(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("priorities", flag.ExitOnError)
+	set := fs.String("set", "", "zu ändernde Stufe (Name oder Zahl)")
+	name := fs.String("name", "", "neuer Name")
+	urgency := fs.String("urgency", "", "Dringlichkeit: low, normal, critical")
+	sticky := fs.String("sticky", "", "Erinnerung bleibt offen bis zur Bestätigung (true/false)")
+	nag := fs.Int("nag", -1, "Erinnerung alle N Minuten wiederholen, bis sie bestätigt wird (0 = aus)")
+	escalation := fs.String("escalation", "", "Ablauf der Wiederholungen, z.B. popup,sound,overlay")
+	early := fs.String("early", "", "zusätzliche Vorwarnungen in Minuten, z.B. 60,15 oder 60:mail,15 (\"-\" = keine)")
+	weight := fs.Int("weight", -1, "Gewicht beim Sortieren")
+	soundFile := fs.String("sound", "", "Tondatei (WAV/OGG) für diese Stufe (\"-\" = eingebauter Ton)")
+	fs.Parse(args)
+
+	if *set != "" {
+		value, err := levels.Parse(*set)
+		if err != nil {
+			return err
+		}
+		level := levels.Lookup(&value)
+		if *name != "" {
+			level.Name = *name
+		}
+		switch *urgency {
+		case "":
+		case priority.UrgencyLow, priority.UrgencyNormal, priority.UrgencyCritical:
+			level.Urgency = *urgency
+		default:
+			return fmt.Errorf("Ungültige Dringlichkeit: %s", *urgency)
+		}
+		if *sticky != "" {
+			if level.Sticky, err = strconv.ParseBool(*sticky); err != nil {
+				return fmt.Errorf("Ungültiger Wert für -sticky: %s", *sticky)
+			}
+		}
+		if *nag >= 0 {
+			level.NagInterval = time.Duration(*nag) * time.Minute
+		}
+		if *escalation != "" {
+			if level.Escalation, err = priority.ParseEscalation(*escalation); err != nil {
+				return err
+			}
+		}
+		if *early == "-" {
+			level.EarlyAlarms = nil
+		} else if *early != "" {
+			if level.EarlyAlarms, err = priority.ParseAlarms(*early); err != nil {
+				return err
+			}
+		}
+		if *weight >= 0 {
+			level.SortWeight = *weight
+		}
+		if *soundFile == "-" {
+			level.Sound = ""
+		} else if *soundFile != "" {
+			if _, err := sound.Load(*soundFile); err != nil {
+				return err
+			}
+			level.Sound = *soundFile
+		}
+		if err := s.SavePriorityLevel(level); err != nil {
+			return err
+		}
+		if levels, err = s.PriorityLevels(); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WERT\tNAME\tDRINGLICHKEIT\tDAUERHAFT\tWIEDERHOLEN\tVORWARNUNGEN\tGEWICHT\tTON")
+	for _, l := range levels {
+		nagText := "-"
+		if l.NagInterval > 0 {
+			nagText = fmt.Sprintf("%d min (%s)", int(l.NagInterval.Minutes()), strings.Join(l.Steps(), " → "))
+		}
+		soundText := l.Sound
+		if soundText == "" {
+			soundText = "(eingebaut)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\t%d\t%s\n",
+			l.Value, l.Name, l.Urgency, l.Sticky, nagText, priority.FormatAlarms(l.EarlyAlarms), l.SortWeight, soundText)
+	}
+	return w.Flush()
+}
+
+// acknowledge bestätigt die Erinnerung an einen Termin, damit nicht weiter nachgehakt wird
+func acknowledge(s *store.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Verwendung: reminderctl ack ID")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Ungültige ID: %s", args[0])
+	}
+	a, err := s.GetAppointment(id)
+	if err != nil {
+		return fmt.Errorf("Termin ID=%d nicht gefunden: %v", id, err)
+	}
+	due, err := a.Due()
+	if err != nil {
+		return err
+	}
+	if err := s.Acknowledge(id, due); err != nil {
+		return err
+	}
+	fmt.Printf("Erinnerung an \"%s\" (%s) bestätigt\n", a.Title, due.Format("02.01.2006 15:04"))
+	return nil
+}
+
+// soundSettings zeigt oder ändert die Toneinstellungen; mit -test wird der
+// Ton einer Stufe sofort abgespielt
+func soundSettings(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("sound", flag.ExitOnError)
+	volume := fs.Int("volume", -1, "Lautstärke in Prozent (0-100)")
+	tag := fs.String("tag", "", "Ton für einen Tag: TAG=DATEI (TAG= entfernt ihn)")
+	test := fs.String("test", "", "Ton dieser Stufe abspielen (Name oder Zahl)")
+	fs.Parse(args)
+
+	if *volume > 100 {
+		return fmt.Errorf("Ungültige Lautstärke: %d", *volume)
+	}
+	if *volume >= 0 {
+		if err := s.SetSetting(reminder.SettingVolume, strconv.Itoa(*volume)); err != nil {
+			return err
+		}
+	}
+	if *tag != "" {
+		name, file, ok := strings.Cut(*tag, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("Ungültige Angabe für -tag: %s (erwartet TAG=DATEI)", *tag)
+		}
+		if file != "" {
+			if _, err := sound.Load(file); err != nil {
+				return err
+			}
+		}
+		if err := s.SetSetting(reminder.SettingTagSound+strings.ToLower(strings.TrimSpace(name)), file); err != nil {
+			return err
+		}
+	}
+
+	if *test != "" {
+		value, err := levels.Parse(*test)
+		if err != nil {
+			return err
+		}
+		r := reminder.NewReminderService(s, nil)
+		r.PlaySound(store.Appointment{Priority: &value}, levels.Lookup(&value))
+	}
+
+	volumeText, _ := s.Setting(reminder.SettingVolume, "100")
+	fmt.Printf("Lautstärke: %s %%\n", volumeText)
+
+	tagSounds, err := s.Settings(reminder.SettingTagSound)
+	if err != nil {
+		return err
+	}
+	if len(tagSounds) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nTAG\tTON")
+		tags := make([]string, 0, len(tagSounds))
+		for tag := range tagSounds {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Fprintf(w, "%s\t%s\n", tag, tagSounds[tag])
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// doNotDisturb schaltet Nicht stören ein ("on [DAUER]") oder aus ("off")
+// und zeigt den aktuellen Stand. Nicht kritische Erinnerungen werden
+// währenddessen gesammelt und danach zusammengefasst angezeigt.
+func doNotDisturb(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("dnd", flag.ExitOnError)
+	quietHours := fs.String("quiet-hours", "", "tägliche Ruhezeit, z.B. 22:00-07:00 (\"-\" = keine)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Verwendung: reminderctl dnd [-quiet-hours HH:MM-HH:MM] [on [DAUER] | off]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// Die Ruhezeit steht in der Konfigurationsdatei; der Daemon übernimmt sie nach SIGHUP
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		return err
+	}
+	if *quietHours != "" {
+		cfg.QuietHours = ""
+		if *quietHours != "-" {
+			hours, err := quiet.Parse(*quietHours)
+			if err != nil {
+				return err
+			}
+			cfg.QuietHours = hours.String()
+		}
+		if err := config.Save(configPath, cfg); err != nil {
+			return err
+		}
+	}
+
+	switch fs.Arg(0) {
+	case "":
+	case "on":
+		var d time.Duration
+		if fs.NArg() > 1 {
+			var err error
+			if d, err = time.ParseDuration(fs.Arg(1)); err != nil || d <= 0 {
+				return fmt.Errorf("Ungültige Dauer: %s (z.B. 90m oder 2h)", fs.Arg(1))
+			}
+		}
+		if err := reminder.SetDoNotDisturb(s, true, d); err != nil {
+			return err
+		}
+	case "off":
+		if err := reminder.SetDoNotDisturb(s, false, 0); err != nil {
+			return err
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	on, until, err := reminder.DoNotDisturb(s, time.Now())
+	if err != nil {
+		return err
+	}
+	status := "aus"
+	if on && until.IsZero() {
+		status = "an"
+	} else if on {
+		status = "an bis " + until.Format("02.01.2006 15:04")
+	}
+	hoursText := cfg.QuietHours
+	if hoursText == "" {
+		hoursText = "-"
+	}
+	fmt.Printf("Nicht stören: %s\nRuhezeit:     %s\n", status, hoursText)
+	return nil
+}
+
+// showConfig zeigt die wirksame Konfiguration samt Umgebung und -db
+func showConfig(cfg config.Config, dbPath string) error {
+	fmt.Printf("# %s\n# Datenbank: %s\n", configPath, dbPath)
+	if cfg.API.Listen != config.APIOff {
+		_, address := api.Address(cfg.API)
+		fmt.Printf("# Schnittstelle: %s\n", address)
+		if cfg.API.Token == "" {
+			fmt.Printf("# Token: %s\n", paths.APIToken())
+		}
+	}
+	// Zugangsdaten nicht ausgeben
+	secrets := []*string{&cfg.CalDAV.Password, &cfg.API.Token, &cfg.Mail.Password,
+		&cfg.Push.Ntfy.Token, &cfg.Push.Gotify.Token, &cfg.Push.Matrix.Token}
+	for i := range cfg.Hooks {
+		secrets = append(secrets, &cfg.Hooks[i].Secret)
+	}
+	for _, secret := range secrets {
+		if *secret != "" {
+			*secret = "***"
+		}
+	}
+	return toml.NewEncoder(os.Stdout).Encode(cfg)
+}
+
+// subscriptions legt abonnierte Kalender an, ändert oder entfernt sie und
+// ruft sie mit -refresh sofort ab
+func subscriptions(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("subscriptions", flag.ExitOnError)
+	add := fs.String("add", "", "Kalender abonnieren (Dateipfad oder URL)")
+	set := fs.Int64("set", 0, "zu änderndes Abo (ID)")
+	remove := fs.Int64("remove", 0, "Abo samt seiner Termine entfernen (ID)")
+	name := fs.String("name", "", "Name des Kalenders")
+	source := fs.String("source", "", "neue Quelle bei -set")
+	every := fs.Int("every", 0, "Abruf alle N Minuten (Standard bei -add: 60)")
+	colorText := fs.String("color", "", "Farbe in der Liste, z.B. #3070c0 (\"-\" = Standard)")
+	remind := fs.String("remind", "", "an Termine des Kalenders erinnern (true/false)")
+	refresh := fs.Bool("refresh", false, "sofort abrufen (alle oder das mit -add/-set angegebene)")
+	fs.Parse(args)
+
+	if *remove != 0 {
+		if err := s.DeleteSubscription(*remove); err != nil {
+			return err
+		}
+	}
+
+	var sub store.Subscription
+	switch {
+	case *add != "":
+		sub = store.Subscription{Source: *add, Name: feeds.Name(*add), Refresh: time.Hour}
+	case *set != 0:
+		var err error
+		if sub, err = s.GetSubscription(*set); err != nil {
+			return fmt.Errorf("Kein Abo mit ID %d", *set)
+		}
+		if *source != "" {
+			sub.Source = *source
+		}
+	}
+	if sub.Source != "" {
+		if *name != "" {
+			sub.Name = *name
+		}
+		if *every < 0 {
+			return fmt.Errorf("Ungültiger Abstand: %d", *every)
+		} else if *every > 0 {
+			sub.Refresh = time.Duration(*every) * time.Minute
+		}
+		if *colorText == "-" {
+			sub.Color = ""
+		} else if *colorText != "" {
+			if _, err := feeds.ParseColor(*colorText); err != nil {
+				return err
+			}
+			sub.Color = *colorText
+		}
+		if *remind != "" {
+			var err error
+			if sub.Remind, err = strconv.ParseBool(*remind); err != nil {
+				return fmt.Errorf("Ungültiger Wert für -remind: %s", *remind)
+			}
+		}
+
+		var err error
+		if sub.ID == 0 {
+			err = s.AddSubscription(&sub)
+		} else {
+			err = s.UpdateSubscription(sub)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if *refresh {
+		subs, err := s.Subscriptions()
+		if err != nil {
+			return err
+		}
+		for _, other := range subs {
+			if sub.ID != 0 && other.ID != sub.ID {
+				continue
+			}
+			if err := feeds.Refresh(context.Background(), s, other); err != nil {
+				log.Printf("%s: %v", other.Name, err)
+			}
+		}
+	}
+
+	subs, err := s.Subscriptions()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tQUELLE\tABRUF\tFARBE\tERINNERN\tSTAND")
+	for _, sub := range subs {
+		status := "noch nicht abgerufen"
+		if !sub.LastRefresh.IsZero() {
+			status = sub.LastRefresh.Local().Format("02.01.2006 15:04")
+		}
+		if sub.LastError != "" {
+			status += ": " + sub.LastError
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d min\t%s\t%t\t%s\n",
+			sub.ID, sub.Name, sub.Source, int(sub.Refresh.Minutes()), sub.Color, sub.Remind, status)
+	}
+	return w.Flush()
+}
+
+// syncCalDAV gleicht einmal mit dem CalDAV-Server ab oder zeigt mit
+// -status nur den Stand des letzten Abgleichs
+func syncCalDAV(s *store.Store, cfg config.CalDAV, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	status := fs.Bool("status", false, "nur den Stand anzeigen, nicht abgleichen")
+	fs.Parse(args)
+
+	if !*status {
+		result, err := davsync.SyncNow(context.Background(), s, cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Println(result)
+	}
+	st, err := davsync.ReadStatus(s)
+	if err != nil {
+		return err
+	}
+	server := cfg.URL
+	if server == "" {
+		server = "(keiner)"
+	}
+	fmt.Printf("Server: %s\nStand: %s\nAusstehend: %d\n", server, st, st.Pending)
+	return nil
+}
+
+// testMail sendet eine Test-E-Mail oder mit -id die Erinnerung an einen
+// Termin, wie reminderd sie verschickt
+func testMail(s *store.Store, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("mail", flag.ExitOnError)
+	id := fs.Int64("id", 0, "Erinnerung an diesen Termin senden")
+	minutes := fs.Int("minutes", 15, "mit -id: Minuten bis zum Termin (0 = zum Termin)")
+	fs.Parse(args)
+
+	language := cfg.Mail.Language
+	if language == "" {
+		language = i18n.Match(cfg.Locale)
+	}
+	m := mail.Message{
+		Subject: "Test-E-Mail der Reminder-Erinnerungs-App",
+		Body:    "Der Versand von Erinnerungen per E-Mail ist richtig eingerichtet.\n",
+	}
+	if *id != 0 {
+		a, err := s.GetAppointment(*id)
+		if err != nil {
+			return fmt.Errorf("Kein Termin mit ID %d", *id)
+		}
+		level := levels.Lookup(a.Priority)
+		name := ""
+		if level.Value != 0 {
+			name = level.Name
+		}
+		if m, err = mail.Reminder(language, a, name, *minutes); err != nil {
+			return err
+		}
+	}
+	if err := mail.Send(cfg.Mail, m); err != nil {
+		return err
+	}
+	fmt.Printf("E-Mail an %s gesendet\n", strings.Join(cfg.Mail.To, ", "))
+	return nil
+}
+
+// testPush sendet eine Testnachricht über die eingerichteten Push-Dienste
+func testPush(cfg config.Push, args []string) error {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	via := fs.String("via", "", "nur über diesen Dienst senden (ntfy, gotify, matrix)")
+	level := fs.String("priority", "", "Dringlichkeit wie bei dieser Prioritätsstufe")
+	fs.Parse(args)
+
+	senders := map[string]push.Sender{}
+	if cfg.Ntfy.Enabled() {
+		senders["ntfy"] = push.NewNtfy(cfg.Ntfy)
+	}
+	if cfg.Gotify.Enabled() {
+		senders["gotify"] = push.NewGotify(cfg.Gotify)
+	}
+	if cfg.Matrix.Enabled() {
+		senders["matrix"] = push.NewMatrix(cfg.Matrix)
+	}
+	if *via != "" {
+		sender, ok := senders[*via]
+		if !ok {
+			return fmt.Errorf("Push-Dienst %s ist nicht eingerichtet", *via)
+		}
+		senders = map[string]push.Sender{*via: sender}
+	}
+	if len(senders) == 0 {
+		return fmt.Errorf("Kein Push-Dienst eingerichtet")
+	}
+
+	m := push.Message{
+		Title:    "Test der Reminder-Erinnerungs-App",
+		Body:     "Push-Benachrichtigungen sind richtig eingerichtet.",
+		Priority: push.Normal,
+	}
+	if *level != "" {
+		v, err := levels.Parse(*level)
+		if err != nil {
+			return err
+		}
+		l := levels.Lookup(&v)
+		m.Priority = push.ForLevel(l)
+		m.Body += " Priorität: " + l.Name
+	}
+	failed := false
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := senders[name].Send(context.Background(), m); err != nil {
+			log.Print(err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Nachricht über %s gesendet\n", name)
+	}
+	if failed {
+		return fmt.Errorf("Nicht alle Nachrichten wurden gesendet")
+	}
+	return nil
+}
+
+// importFile übernimmt Termine und Aufgaben aus einer .ics-Datei oder
+// Termine aus einer .csv-Datei
+func importFile(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	mapping := fs.String("map", "", "CSV-Spalten zuordnen, z.B. titel=Betreff,datum=Start,uhrzeit=3")
+	dryRun := fs.Bool("dry-run", false, "CSV-Zeilen nur prüfen und anzeigen, nichts speichern")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Verwendung: reminderctl import [-map ZUORDNUNG] [-dry-run] DATEI.ics|DATEI.csv")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(fs.Arg(0)), ".csv") {
+		return importCSV(s, f, *mapping, *dryRun)
+	}
+
+	result, err := ics.Import(s, f)
+	for _, e := range result.Errors {
+		log.Printf("Übersprungen: %v", e)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(result)
+	return nil
+}
+
+func importCSV(s *store.Store, r io.Reader, spec string, dryRun bool) error {
+	table, err := exchange.ReadCSV(r)
+	if err != nil {
+		return err
+	}
+	m := table.GuessMapping()
+	if err := table.ParseMapping(m, spec); err != nil {
+		return err
+	}
+	rows, err := table.Appointments(m, levels)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if dryRun {
+		fmt.Fprintln(w, "ZEILE\tTITEL\tDATUM\tUHRZEIT\tPRIORITÄT\tTAGS\tFEHLER")
+	}
+	for _, row := range rows {
+		if row.Err != nil {
+			failed++
+		}
+		if !dryRun {
+			if row.Err != nil {
+				log.Printf("Zeile %d übersprungen: %v", row.Line, row.Err)
+			}
+			continue
+		}
+		a, problem := row.Appointment, ""
+		if row.Err != nil {
+			problem = row.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Line, a.Title, a.Date, a.Time, formatPriority(a.Priority), strings.Join(a.Tags, ","), problem)
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Printf("%d Termine würden übernommen, %d Zeilen fehlerhaft\n", len(rows)-failed, failed)
+		return nil
+	}
+	imported, err := exchange.Import(s, rows)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d Termine übernommen, %d Zeilen fehlerhaft\n", imported.Appointments, failed)
+	return nil
+}
+
+// exportFile schreibt Termine und Aufgaben als iCalendar, CSV oder JSON.
+// Das Format ergibt sich aus der Dateiendung oder aus -format.
+func exportFile(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "ics, csv oder json (Standard: nach Dateiendung, sonst ics)")
+	what := fs.String("what", "appointments", "bei CSV: appointments oder tasks")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Verwendung: reminderctl export [-format ics|csv|json] [-what appointments|tasks] DATEI|-")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	name := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	}
+
+	var write func(io.Writer) error
+	switch *format {
+	case "", "ics":
+		write = func(w io.Writer) error { return ics.Export(s, w) }
+	case "csv":
+		switch *what {
+		case "appointments":
+			write = func(w io.Writer) error {
+				appointments, err := s.QueryAppointments(store.Filter{OwnOnly: true})
+				if err != nil {
+					return err
+				}
+				return exchange.WriteAppointmentsCSV(w, appointments, levels)
+			}
+		case "tasks":
+			write = func(w io.Writer) error {
+				tasks, err := s.QueryTasks(store.Filter{})
+				if err != nil {
+					return err
+				}
+				return exchange.WriteTasksCSV(w, tasks, levels)
+			}
+		default:
+			return fmt.Errorf("Ungültiger Wert für -what: %s", *what)
+		}
+	case "json":
+		write = func(w io.Writer) error {
+			appointments, err := s.QueryAppointments(store.Filter{OwnOnly: true})
+			if err != nil {
+				return err
+			}
+			tasks, err := s.QueryTasks(store.Filter{})
+			if err != nil {
+				return err
+			}
+			return exchange.WriteJSON(w, appointments, tasks, levels)
+		}
+	default:
+		return fmt.Errorf("Unbekanntes Format: %s", *format)
+	}
+
+	if name == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// undoRedo macht die letzten -n Änderungen rückgängig oder stellt sie wieder
+// her, mit -id genau die Änderung aus "reminderctl history"
+func undoRedo(s *store.Store, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	n := fs.Int("n", 1, "Anzahl der Änderungen")
+	id := fs.Int64("id", 0, "genau diese Änderung (ID aus history)")
+	fs.Parse(args)
+
+	step, stepOp, done := s.Undo, s.UndoOp, "undo.undone"
+	if cmd == "redo" {
+		step, stepOp, done = s.Redo, s.RedoOp, "undo.redone"
+	}
+	if *id != 0 {
+		step = func() (store.Operation, error) { return stepOp(*id) }
+		*n = 1
+	}
+	for i := 0; i < *n; i++ {
+		op, err := step()
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.T(done, "What", op.String()))
+	}
+	return nil
+}
+
+// history listet die letzten Änderungen aus dem Journal, die neueste zuerst
+func history(s *store.Store, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	n := fs.Int("n", 20, "Anzahl der Änderungen")
+	fs.Parse(args)
+
+	ops, err := s.Journal(*n)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tZEIT\tÄNDERUNG\tSTATUS")
+	for _, op := range ops {
+		status := "-"
+		if op.Undone {
+			status = "rückgängig gemacht"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", op.ID, op.Created.Local().Format("02.01.2006 15:04"), op, status)
+	}
+	return w.Flush()
+}
@@ -1,69 +1,135 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	"Reminder_Erinnerungs_App/internal/api"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/davsync"
+	"Reminder_Erinnerungs_App/internal/feeds"
+	"Reminder_Erinnerungs_App/internal/hooks"
+	"Reminder_Erinnerungs_App/internal/paths"
 	"Reminder_Erinnerungs_App/internal/reminder"
-
-	_ "github.com/mattn/go-sqlite3"
+	"Reminder_Erinnerungs_App/internal/store"
 )
 
-// InitDB initialisiert die Datenbank und erstellt die benötigten Tabellen
-func initDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+func main() {
+	flags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := flags.Load()
 	if err != nil {
-		return nil, err
+		log.Fatal(err)
 	}
-
-	// Tabellen erstellen, falls sie nicht existieren
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS appointments (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT,
-		date TEXT,
-		time TEXT,
-		priority INTEGER
-	);
-	CREATE TABLE IF NOT EXISTS tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT,
-		completed BOOLEAN
-	);
-	`
-	if _, err := db.Exec(createTableSQL); err != nil {
-		return nil, err
+	dbPath, err := paths.Database(cfg.DBPath)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	return db, nil
-}
-
-func main() {
-	// Verwende den korrekten Pfad zur Datenbank
-	dbPath := "/home/alex/PycharmProjects/Reminder_Erinnerungs_App/reminder.db"
-
 	// Initialisiere die Datenbank mit Tabellen
-	db, err := initDB(dbPath)
+	s, err := store.Open(dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
+	defer s.Close()
+
+	unregister, err := paths.Register("reminderd", dbPath)
+	if err != nil {
+		log.Printf("Fehler beim Anmelden des Daemons: %v", err)
+	}
+	defer unregister()
+	if warning := paths.CheckShared("gui", dbPath); warning != "" {
+		log.Printf("Warnung: %s", warning)
+	}
 
 	// Erstelle einen minimalen ReminderService ohne GUI-Fenster
-	reminderService := reminder.NewReminderService(db, nil)
+	reminderService := reminder.NewReminderService(s, nil)
+	if err := reminderService.SetConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
 	reminderService.Start()
 	defer reminderService.Stop()
 
-	// Warte auf Beendigungssignal
+	// Abonnierte Kalender im Hintergrund aktuell halten
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go feeds.Run(ctx, s)
+
+	// Abgleich mit dem CalDAV-Server; die GUI gleicht nur ab, wenn kein Daemon läuft
+	var cfgMu sync.Mutex
+	caldav := func() config.CalDAV {
+		cfgMu.Lock()
+		defer cfgMu.Unlock()
+		return cfg.CalDAV
+	}
+	go davsync.Run(ctx, s, caldav)
+
+	// Schnittstelle für Skripte und andere Programme, auch für die GUI:
+	// Ist sie verbunden, zeigt sie die Erinnerungen an, geplant werden sie
+	// weiterhin hier. Beim Beenden wird gewartet, bis der Socket entfernt ist.
+	hub := api.NewHub(s)
+	reminderService.Forward(func(e reminder.Event) bool {
+		return hub.PublishReminder(e.Kind, e.Appointment, e.Due, e.Minutes)
+	})
+	// Änderungen durch reminderctl und die GUI ebenfalls melden
+	if err := s.Watch(2 * time.Second); err != nil {
+		log.Printf("Fehler beim Überwachen der Datenbank: %v", err)
+	}
+	go hub.Run(ctx)
+
+	// Webhooks und Programme bei Ereignissen, auch ohne Schnittstelle
+	go hooks.Run(ctx, hub, func() []config.Hook {
+		cfgMu.Lock()
+		defer cfgMu.Unlock()
+		return cfg.Hooks
+	})
+	apiCfg, apiDone := cfg.API, make(chan struct{})
+	go func() {
+		defer close(apiDone)
+		if err := api.Serve(ctx, hub, dbPath, apiCfg, caldav); err != nil {
+			log.Printf("Schnittstelle nicht verfügbar: %v", err)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-apiDone
+	}()
+
+	// Warte auf Beendigungssignal, SIGHUP lädt die Konfiguration neu
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	log.Printf("Reminder-Daemon gestartet. Datenbank: %s", dbPath)
+	log.Printf("Reminder-Daemon gestartet. Datenbank: %s, Konfiguration: %s", dbPath, flags.Path())
 
-	// Blockiere, bis ein Signal empfangen wird
-	<-sigChan
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		newCfg, err := flags.Load()
+		if err == nil {
+			err = reminderService.SetConfig(newCfg)
+		}
+		if err != nil {
+			log.Printf("Konfiguration nicht übernommen: %v", err)
+			continue
+		}
+		cfgMu.Lock()
+		cfg = newCfg
+		cfgMu.Unlock()
+		if newPath, err := paths.Database(cfg.DBPath); err == nil && newPath != dbPath {
+			log.Printf("Neuer Datenbankpfad %s wird erst nach einem Neustart verwendet", newPath)
+		}
+		if cfg.API != apiCfg {
+			log.Printf("Geänderte Einstellungen der Schnittstelle gelten erst nach einem Neustart")
+		}
+		log.Printf("Konfiguration neu geladen")
+	}
 	log.Println("Beende Reminder-Daemon...")
 }
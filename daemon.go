@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/api"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/davsync"
+	"Reminder_Erinnerungs_App/internal/feeds"
+	"Reminder_Erinnerungs_App/internal/paths"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/reminder"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Abstand zwischen zwei Verbindungsversuchen zu reminderd
+const daemonRetry = 30 * time.Second
+
+// Schreibzugriffe der GUI auf Termine, Aufgaben, Abos und Einstellungen
+// und der Abgleich mit dem CalDAV-Server: direkt in der Datenbank
+// (localBackend) oder über reminderd (*api.Client)
+type dataBackend interface {
+	reminder.Actions
+	AddAppointment(a *store.Appointment) error
+	UpdateAppointment(a store.Appointment) error
+	DeleteAppointment(id int64) (store.Operation, error)
+	AddTask(t *store.Task) error
+	UpdateTask(t store.Task) error
+	DeleteTask(id int64) (store.Operation, error)
+	store.Importer
+	store.SettingWriter
+	AddSubscription(sub *store.Subscription) error
+	UpdateSubscription(sub store.Subscription) error
+	DeleteSubscription(id int64) error
+	RefreshSubscription(id int64) error
+	SyncNow() (davsync.Result, error)
+	Undo() (store.Operation, error)
+	Redo() (store.Operation, error)
+	UndoOp(id int64) (store.Operation, error)
+	RedoOp(id int64) (store.Operation, error)
+}
+
+var (
+	backendMu sync.Mutex
+	current   dataBackend
+	watchOnce sync.Once
+)
+
+// backend liefert, worüber die GUI gerade schreibt
+func backend() dataBackend {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	if current == nil {
+		return localBackend{dataStore}
+	}
+	return current
+}
+
+// localBackend schreibt ohne reminderd direkt in die Datenbank
+type localBackend struct {
+	*store.Store
+}
+
+// RefreshSubscription ruft ein Abo sofort ab
+func (b localBackend) RefreshSubscription(id int64) error {
+	sub, err := b.GetSubscription(id)
+	if err != nil {
+		return err
+	}
+	return feeds.Refresh(context.Background(), b.Store, sub)
+}
+
+// SyncNow gleicht sofort mit dem CalDAV-Server ab, außer ein nicht
+// erreichbarer reminderd gleicht dieselbe Datenbank ab
+func (b localBackend) SyncNow() (davsync.Result, error) {
+	if daemonRunning() {
+		return davsync.Result{}, fmt.Errorf("reminderd gleicht ab, ist aber nicht erreichbar")
+	}
+	return davsync.SyncNow(context.Background(), b.Store, appConfig.CalDAV)
+}
+
+// daemonRunning meldet, ob reminderd mit derselben Datenbank läuft, auch
+// wenn die GUI ihn nicht erreicht
+func daemonRunning() bool {
+	daemonDB, running := paths.Registered("reminderd")
+	return running && daemonDB == dbPath
+}
+
+func useBackend(b dataBackend) {
+	backendMu.Lock()
+	current = b
+	backendMu.Unlock()
+	reminderService.SetActions(b)
+}
+
+// connectDaemon verbindet die GUI mit reminderd, solange er dieselbe
+// Datenbank verwendet. Dann plant nur der Daemon die Erinnerungen, ruft die
+// Abos ab, gleicht mit dem CalDAV-Server ab und ändert Termine und
+// Aufgaben; die GUI zeigt seine Erinnerungen an und lädt bei seinen
+// Änderungen neu. Ohne Daemon erinnert die GUI selbst, übernimmt Abos und
+// Abgleich und erkennt Änderungen anderer Prozesse an der Datenbank.
+func connectDaemon(ctx context.Context) {
+	local := false
+	var stopJobs context.CancelFunc // beendet Abos und Abgleich der GUI
+	setJobs := func(run bool) {
+		switch {
+		case run && stopJobs == nil:
+			var jobs context.Context
+			jobs, stopJobs = context.WithCancel(ctx)
+			go feeds.Run(jobs, dataStore)
+			go davsync.Run(jobs, dataStore, func() config.CalDAV { return appConfig.CalDAV })
+		case !run && stopJobs != nil:
+			stopJobs()
+			stopJobs = nil
+		}
+	}
+	defer func() {
+		setJobs(false)
+		if local {
+			reminderService.Stop()
+		}
+	}()
+
+	for {
+		err := followDaemon(ctx, func() {
+			setJobs(false)
+			if local {
+				reminderService.Stop()
+				local = false
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		// Ein Daemon ohne erreichbare Schnittstelle ruft trotzdem die Abos
+		// ab und gleicht ab; er kann auch erst später gestartet werden
+		setJobs(!daemonRunning())
+		if !local {
+			log.Printf("Ohne reminderd, die GUI erinnert selbst: %v", err)
+			useBackend(localBackend{dataStore})
+			watchOnce.Do(func() {
+				if err := dataStore.Watch(2 * time.Second); err != nil {
+					log.Printf("Fehler beim Überwachen der Datenbank: %v", err)
+				}
+			})
+			reminderService.Start()
+			local = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(daemonRetry):
+		}
+	}
+}
+
+// followDaemon verbindet mit reminderd und verarbeitet seine Ereignisse,
+// bis die Verbindung endet. connected wird nach dem Verbinden aufgerufen.
+func followDaemon(ctx context.Context, connected func()) error {
+	client, err := api.Dial(ctx, appConfig.API, dbPath, func() priority.Set { return priorities })
+	if err != nil {
+		return err
+	}
+	events, err := client.Events(ctx, true)
+	if err != nil {
+		return err
+	}
+	connected()
+	useBackend(client)
+	defer useBackend(localBackend{dataStore})
+	log.Printf("Mit reminderd verbunden, der Daemon plant die Erinnerungen")
+
+	// Was bis jetzt geändert wurde, ist nicht gemeldet worden
+	dataStore.Notify(store.Change{})
+	for e := range events {
+		switch e.Name {
+		case api.EventChanged:
+			var c api.Changed
+			if err := json.Unmarshal(e.Data, &c); err != nil {
+				log.Printf("Ungültiges Ereignis von reminderd: %v", err)
+				continue
+			}
+			dataStore.Notify(store.Change{Table: c.Table, ID: c.ID})
+		case api.EventReminder:
+			showDaemonReminder(e.Data)
+		}
+	}
+	return fmt.Errorf("Verbindung zu reminderd unterbrochen")
+}
+
+// showDaemonReminder zeigt eine von reminderd ausgelöste Erinnerung an
+func showDaemonReminder(data json.RawMessage) {
+	var r api.Reminder
+	if err := json.Unmarshal(data, &r); err != nil {
+		log.Printf("Ungültige Erinnerung von reminderd: %v", err)
+		return
+	}
+	a, err := dataStore.GetAppointment(r.Appointment.ID)
+	if err != nil {
+		log.Printf("Termin ID=%d der Erinnerung nicht gefunden: %v", r.Appointment.ID, err)
+		return
+	}
+	due, err := time.ParseInLocation("2006-01-02 15:04", r.Due, time.Local)
+	if err != nil {
+		log.Printf("Ungültiger Zeitpunkt der Erinnerung: %v", err)
+		return
+	}
+	go reminderService.Show(reminder.Event{
+		Kind:        r.Kind,
+		Appointment: a,
+		Due:         due,
+		Level:       priorities.Lookup(a.Priority),
+		Minutes:     r.Minutes,
+	})
+}
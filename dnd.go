@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/reminder"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+// Schalter für Nicht stören in der Werkzeugleiste. refresh übernimmt
+// Änderungen durch andere Prozesse und über das Tray-Menü; eine Pause
+// schaltet er an ihrem Ende selbst aus.
+func newDoNotDisturbToggle() (toggle *widget.Check, refresh func()) {
+	toggle = widget.NewCheck(i18n.T("common.doNotDisturb"), setDoNotDisturb)
+	var mu sync.Mutex
+	var expire *time.Timer
+	refresh = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		on, until, err := reminder.DoNotDisturb(dataStore, time.Now())
+		if err != nil {
+			log.Printf("%v", err)
+		}
+		// Ohne OnChanged, sonst würde eine Pause zu Nicht stören ohne Ende
+		toggle.OnChanged = nil
+		toggle.SetChecked(on)
+		toggle.OnChanged = setDoNotDisturb
+
+		if expire != nil {
+			expire.Stop()
+			expire = nil
+		}
+		if on && !until.IsZero() {
+			expire = time.AfterFunc(time.Until(until), refresh)
+		}
+	}
+	refresh()
+	return toggle, refresh
+}
+
+// setDoNotDisturb schaltet Nicht stören ohne Ende ein oder aus
+func setDoNotDisturb(on bool) {
+	if err := reminder.SetDoNotDisturb(backend(), on, 0); err != nil {
+		log.Printf("%v", err)
+	}
+}
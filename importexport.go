@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/exchange"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/ics"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Menü "Datei" mit Import und Export
+func newFileMenu(w fyne.Window) *fyne.Menu {
+	return fyne.NewMenu(i18n.T("importexport.menu"),
+		fyne.NewMenuItem(i18n.T("importexport.importICS"), func() { importICS(w) }),
+		fyne.NewMenuItem(i18n.T("importexport.importCSV"), func() { importCSV(w) }),
+		fyne.NewMenuItem(i18n.T("importexport.subscriptions"), func() { showSubscriptions(w) }),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("importexport.exportICS"), func() { exportICS(w) }),
+		fyne.NewMenuItem(i18n.T("importexport.exportAppointmentsCSV"), func() {
+			exportFile(w, i18n.T("importexport.appointmentsFile")+".csv", func(wc io.Writer) error {
+				appointments, err := dataStore.QueryAppointments(store.Filter{OwnOnly: true})
+				if err != nil {
+					return err
+				}
+				return exchange.WriteAppointmentsCSV(wc, appointments, priorities)
+			})
+		}),
+		fyne.NewMenuItem(i18n.T("importexport.exportTasksCSV"), func() {
+			exportFile(w, i18n.T("importexport.tasksFile")+".csv", func(wc io.Writer) error {
+				tasks, err := dataStore.QueryTasks(store.Filter{})
+				if err != nil {
+					return err
+				}
+				return exchange.WriteTasksCSV(wc, tasks, priorities)
+			})
+		}),
+		fyne.NewMenuItem(i18n.T("importexport.exportJSON"), func() {
+			exportFile(w, "reminder.json", func(wc io.Writer) error {
+				appointments, err := dataStore.QueryAppointments(store.Filter{OwnOnly: true})
+				if err != nil {
+					return err
+				}
+				tasks, err := dataStore.QueryTasks(store.Filter{})
+				if err != nil {
+					return err
+				}
+				return exchange.WriteJSON(wc, appointments, tasks, priorities)
+			})
+		}),
+	)
+}
+
+// Übernimmt Termine und Aufgaben aus einer .ics-Datei
+func importICS(w fyne.Window) {
+	d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if r == nil {
+			return // abgebrochen
+		}
+		defer r.Close()
+
+		result, err := ics.Import(backend(), r)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		message := i18n.T("importexport.icsResult", "Appointments", result.Appointments,
+			"Tasks", result.Tasks, "Duplicates", result.Duplicates, "Failed", len(result.Errors))
+		for _, e := range result.Errors {
+			log.Printf("Import: %v", e)
+		}
+		if len(result.Errors) > 0 {
+			var lines []string
+			for i, e := range result.Errors {
+				if i == 10 {
+					lines = append(lines, i18n.T("importexport.moreErrors", "Count", len(result.Errors)-10))
+					break
+				}
+				lines = append(lines, e.Error())
+			}
+			message += "\n\n" + strings.Join(lines, "\n")
+		}
+		dialog.ShowInformation(i18n.T("importexport.import"), message, w)
+		if result.Operation.ID != 0 {
+			showUndoToast(result.Operation.String(), result.Operation, w)
+		}
+	}, w)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".ics"}))
+	d.Show()
+}
+
+// Liest eine .csv-Datei und zeigt den Dialog zur Spaltenzuordnung
+func importCSV(w fyne.Window) {
+	d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if r == nil {
+			return
+		}
+		defer r.Close()
+
+		table, err := exchange.ReadCSV(r)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showCSVMapping(w, table)
+	}, w)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	d.Show()
+}
+
+// Dialog zur Zuordnung der CSV-Spalten mit Vorschau. Die Vorschau wird bei
+// jeder Änderung neu berechnet, gespeichert wird erst mit "Importieren".
+func showCSVMapping(w fyne.Window, table *exchange.Table) {
+	none := i18n.T("importexport.noColumn")
+	mapping := table.GuessMapping()
+	var rows []exchange.Row
+
+	summary := widget.NewLabel("")
+	preview := widget.NewTable(
+		func() (int, int) { return len(rows) + 1, 6 },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			if id.Row == 0 {
+				label.SetText([]string{i18n.T("importexport.line"), i18n.T("common.title"), i18n.T("common.date"),
+					i18n.T("common.time"), i18n.T("common.priority"), i18n.T("common.error")}[id.Col])
+				return
+			}
+			row := rows[id.Row-1]
+			a := row.Appointment
+			text := ""
+			switch id.Col {
+			case 0:
+				text = fmt.Sprint(row.Line)
+			case 1:
+				text = a.Title
+			case 2:
+				text = dates.Format(a.Date)
+			case 3:
+				text = dates.FormatTime(a.Time)
+			case 4:
+				if a.Priority != nil {
+					text = priorities.Lookup(a.Priority).Name
+				}
+			case 5:
+				if row.Err != nil {
+					text = row.Err.Error()
+				}
+			}
+			label.SetText(text)
+		},
+	)
+	for col, width := range []float32{50, 180, 90, 70, 80, 240} {
+		preview.SetColumnWidth(col, width)
+	}
+
+	var importButton *widget.Button
+	update := func() {
+		var err error
+		rows, err = table.Appointments(mapping, priorities)
+		if err != nil {
+			rows = nil
+			summary.SetText(err.Error())
+			importButton.Disable()
+		} else {
+			failed := 0
+			for _, row := range rows {
+				if row.Err != nil {
+					failed++
+				}
+			}
+			summary.SetText(i18n.T("importexport.csvSummary", "Count", len(rows)-failed, "Failed", failed))
+			if failed == len(rows) {
+				importButton.Disable()
+			} else {
+				importButton.Enable()
+			}
+		}
+		preview.Refresh()
+	}
+
+	columns := append([]string{none}, table.Header...)
+	form := widget.NewForm()
+	for _, field := range exchange.Fields {
+		field := field
+		sel := widget.NewSelect(columns, nil)
+		if col, ok := mapping[field]; ok {
+			sel.SetSelectedIndex(col + 1)
+		} else {
+			sel.SetSelected(none)
+		}
+		sel.OnChanged = func(string) {
+			if i := sel.SelectedIndex(); i > 0 {
+				mapping[field] = i - 1
+			} else {
+				delete(mapping, field)
+			}
+			update()
+		}
+		form.Append(i18n.T("common."+field), sel)
+	}
+
+	var d dialog.Dialog
+	importButton = widget.NewButton(i18n.T("importexport.importButton"), func() {
+		imported, err := exchange.Import(backend(), rows)
+		d.Hide()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		showUndoToast(i18n.T("importexport.csvImported", "Count", imported.Appointments), imported.Operation, w)
+	})
+	importButton.Importance = widget.HighImportance
+	update()
+
+	content := container.NewBorder(
+		container.NewVBox(form, summary), importButton, nil, nil, preview)
+	d = dialog.NewCustom(i18n.T("importexport.csvTitle"), i18n.T("common.cancel"), content, w)
+	d.Resize(fyne.NewSize(800, 550))
+	d.Show()
+}
+
+// Speichert das Ergebnis von write in eine vom Benutzer gewählte Datei
+func exportFile(w fyne.Window, name string, write func(io.Writer) error) {
+	d := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return
+		}
+		if err := write(wc); err != nil {
+			wc.Close()
+			dialog.ShowError(err, w)
+			return
+		}
+		if err := wc.Close(); err != nil {
+			dialog.ShowError(err, w)
+		}
+	}, w)
+	d.SetFileName(name)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{filepath.Ext(name)}))
+	d.Show()
+}
+
+// Speichert alle Termine und Aufgaben als .ics-Datei
+func exportICS(w fyne.Window) {
+	exportFile(w, i18n.T("importexport.appointmentsFile")+".ics", func(wc io.Writer) error { return ics.Export(dataStore, wc) })
+}
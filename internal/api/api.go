@@ -0,0 +1,236 @@
+// Package api ist die lokale HTTP/JSON-Schnittstelle von reminderd. Sie ist
+// über einen Unix-Socket oder eine lokale TCP-Adresse erreichbar und
+// verlangt bei jeder Anfrage außer /health das Token als
+// "Authorization: Bearer …".
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/paths"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Server beantwortet die Anfragen an die Schnittstelle
+type Server struct {
+	store    *store.Store
+	hub      *Hub
+	database string
+	token    string
+	caldav   func() config.CalDAV // nil: kein Abgleich über /sync
+	started  time.Time
+	mux      *http.ServeMux
+}
+
+// New erstellt den Server für einen Store mit der Datenbank database.
+// caldav liefert die aktuellen Einstellungen für den Abgleich über /sync.
+func New(hub *Hub, database, token string, caldav func() config.CalDAV) *Server {
+	srv := &Server{
+		store:    hub.store,
+		hub:      hub,
+		database: database,
+		token:    token,
+		caldav:   caldav,
+		started:  time.Now(),
+		mux:      http.NewServeMux(),
+	}
+
+	srv.mux.HandleFunc("GET /health", srv.health)
+	srv.handle("GET /events", srv.events)
+
+	srv.handle("GET /appointments", srv.listAppointments)
+	srv.handle("POST /appointments", srv.createAppointment)
+	srv.handle("DELETE /appointments", srv.deleteAllAppointments)
+	srv.handle("GET /appointments/{id}", srv.getAppointment)
+	srv.handle("PUT /appointments/{id}", srv.updateAppointment)
+	srv.handle("PATCH /appointments/{id}", srv.updateAppointment)
+	srv.handle("DELETE /appointments/{id}", srv.deleteAppointment)
+	srv.handle("POST /appointments/{id}/snooze", srv.snooze)
+	srv.handle("POST /appointments/{id}/ack", srv.acknowledge)
+
+	srv.handle("GET /tasks", srv.listTasks)
+	srv.handle("POST /tasks", srv.createTask)
+	srv.handle("GET /tasks/{id}", srv.getTask)
+	srv.handle("PUT /tasks/{id}", srv.updateTask)
+	srv.handle("PATCH /tasks/{id}", srv.updateTask)
+	srv.handle("DELETE /tasks/{id}", srv.deleteTask)
+
+	srv.handle("POST /import", srv.importEntries)
+
+	srv.handle("GET /subscriptions", srv.listSubscriptions)
+	srv.handle("POST /subscriptions", srv.createSubscription)
+	srv.handle("PUT /subscriptions/{id}", srv.updateSubscription)
+	srv.handle("DELETE /subscriptions/{id}", srv.deleteSubscription)
+	srv.handle("POST /subscriptions/{id}/refresh", srv.refreshSubscription)
+	srv.handle("PUT /settings/{key}", srv.putSetting)
+	srv.handle("POST /sync", srv.sync)
+
+	srv.handle("POST /undo", srv.undo)
+	srv.handle("POST /undo/{id}", srv.undo)
+	srv.handle("POST /redo", srv.redo)
+	srv.handle("POST /redo/{id}", srv.redo)
+	return srv
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+// handle registriert einen Endpunkt, der das Token verlangt
+func (srv *Server) handle(pattern string, h http.HandlerFunc) {
+	srv.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(srv.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="reminderd"`)
+			writeError(w, http.StatusUnauthorized, errors.New("Token fehlt oder ist ungültig"))
+			return
+		}
+		h(w, r)
+	})
+}
+
+// Health ist die Antwort auf /health
+type Health struct {
+	Status   string `json:"status"`
+	Started  string `json:"started"`
+	Database string `json:"database"`
+}
+
+func (srv *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Health{
+		Status:   "ok",
+		Started:  srv.started.Format(time.RFC3339),
+		Database: srv.database,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Fehler beim Senden der Antwort: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// Token liefert das Token aus der Konfiguration oder, wenn dort keins
+// steht, das in paths.APIToken gespeicherte. Fehlt die Datei, wird ein
+// zufälliges Token erzeugt und nur für den Benutzer lesbar abgelegt.
+func Token(cfg config.API) (string, error) {
+	if token, err := ReadToken(cfg); err == nil {
+		return token, nil
+	}
+	file := paths.APIToken()
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", fmt.Errorf("Fehler beim Anlegen von %s: %v", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("Fehler beim Speichern des Tokens: %v", err)
+	}
+	log.Printf("Neues Token für die Schnittstelle in %s gespeichert", file)
+	return token, nil
+}
+
+// ReadToken liefert das Token wie Token, erzeugt aber keins
+func ReadToken(cfg config.API) (string, error) {
+	if cfg.Token != "" {
+		return cfg.Token, nil
+	}
+	data, err := os.ReadFile(paths.APIToken())
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("Kein Token in %s", paths.APIToken())
+	}
+	return token, nil
+}
+
+// Address liefert Netzwerk und Adresse für api.listen aus der Konfiguration
+func Address(cfg config.API) (network, address string) {
+	switch {
+	case cfg.Listen == "":
+		return "unix", paths.Socket()
+	case strings.HasPrefix(cfg.Listen, "unix:"):
+		return "unix", strings.TrimPrefix(cfg.Listen, "unix:")
+	}
+	return "tcp", cfg.Listen
+}
+
+// Serve nimmt Anfragen an, bis ctx beendet wird. Die Ereignisse meldet der
+// Hub, der dazu mit Run laufen muss. Ein Unix-Socket ist nur für den
+// Benutzer zugänglich und wird danach wieder entfernt.
+func Serve(ctx context.Context, hub *Hub, database string, cfg config.API, caldav func() config.CalDAV) error {
+	if cfg.Listen == config.APIOff {
+		return nil
+	}
+	token, err := Token(cfg)
+	if err != nil {
+		return err
+	}
+
+	network, address := Address(cfg)
+	if network == "unix" {
+		if err := os.MkdirAll(filepath.Dir(address), 0o700); err != nil {
+			return fmt.Errorf("Fehler beim Anlegen von %s: %v", filepath.Dir(address), err)
+		}
+		// Übrig gebliebener Socket eines beendeten Daemons
+		if c, err := net.Dial("unix", address); err == nil {
+			c.Close()
+			return fmt.Errorf("Die Schnittstelle %s wird bereits verwendet", address)
+		}
+		os.Remove(address)
+	}
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Öffnen der Schnittstelle %s: %v", address, err)
+	}
+	if network == "unix" {
+		defer os.Remove(address)
+		if err := os.Chmod(address, 0o600); err != nil {
+			l.Close()
+			return err
+		}
+	}
+
+	server := &http.Server{
+		Handler:           New(hub, database, token, caldav),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	log.Printf("Schnittstelle bereit: %s", address)
+	if err := server.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+const testToken = "geheim"
+
+// newTest startet die Schnittstelle für einen leeren Store
+func newTest(t *testing.T) (*store.Store, *Hub, *httptest.Server) {
+	t.Helper()
+	database := filepath.Join(t.TempDir(), "test.db")
+	s, err := store.Open(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	hub := NewHub(s)
+	srv := httptest.NewServer(New(hub, database, testToken, nil))
+	t.Cleanup(srv.Close)
+	return s, hub, srv
+}
+
+func get(t *testing.T, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestAuth(t *testing.T) {
+	_, _, srv := newTest(t)
+
+	tests := []struct {
+		path  string
+		token string
+		want  int
+	}{
+		{"/health", "", http.StatusOK},
+		{"/appointments", "", http.StatusUnauthorized},
+		{"/appointments", "falsch", http.StatusUnauthorized},
+		{"/appointments", testToken + "x", http.StatusUnauthorized},
+		{"/events", "", http.StatusUnauthorized},
+		{"/appointments", testToken, http.StatusOK},
+		{"/tasks", testToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := get(t, srv.URL+tt.path, tt.token)
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s mit Token %q: %s, erwartet %d", tt.path, tt.token, resp.Status, tt.want)
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			var e struct {
+				Error string `json:"error"`
+			}
+			if resp.Header.Get("WWW-Authenticate") == "" || json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+				t.Errorf("%s: Antwort ohne WWW-Authenticate oder Fehlermeldung", tt.path)
+			}
+		}
+	}
+
+	// Ohne Authorization-Header, aber mit Token an anderer Stelle
+	resp := get(t, srv.URL+"/appointments?token="+testToken, "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Token als Parameter angenommen: %s", resp.Status)
+	}
+}
+
+// dial verbindet einen Client mit der Schnittstelle
+func dial(t *testing.T, srv *httptest.Server, s *store.Store, token string) (*Client, error) {
+	t.Helper()
+	database, err := health(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.API{Listen: strings.TrimPrefix(srv.URL, "http://"), Token: token}
+	return Dial(context.Background(), cfg, database, func() priority.Set {
+		levels, _ := s.PriorityLevels()
+		return levels
+	})
+}
+
+func health(srv *httptest.Server) (string, error) {
+	resp, err := http.Get(srv.URL + "/health")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var h Health
+	err = json.NewDecoder(resp.Body).Decode(&h)
+	return h.Database, err
+}
+
+func TestClient(t *testing.T) {
+	s, _, srv := newTest(t)
+
+	// Dial prüft nur /health; das falsche Token fällt beim ersten Zugriff auf
+	bad, err := dial(t, srv, s, "falsch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bad.AddAppointment(&store.Appointment{Title: "Arzt", Date: "2030-03-14"}); err == nil ||
+		!strings.Contains(err.Error(), "Token") {
+		t.Errorf("Falsches Token: %v", err)
+	}
+
+	c, err := dial(t, srv, s, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := store.Appointment{Title: "Arzt", Date: "2030-03-14", Time: "09:30", Tags: []string{"Gesundheit"}}
+	if err := c.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetAppointment(a.ID)
+	if err != nil || got.Title != "Arzt" || got.Time != "09:30" || len(got.Tags) != 1 {
+		t.Fatalf("Im Store: %+v, %v", got, err)
+	}
+
+	op, err := c.DeleteAppointment(a.ID)
+	if err != nil || op.ID == 0 {
+		t.Fatalf("Löschen: %+v, %v", op, err)
+	}
+	if _, err := c.UndoOp(op.ID); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := s.QueryAppointments(store.Filter{}); len(all) != 1 {
+		t.Errorf("Nach Rückgängig: %+v", all)
+	}
+	if _, err := c.UndoOp(op.ID); err != store.ErrNothingToUndo {
+		t.Errorf("Zweites Rückgängig: %v", err)
+	}
+
+	// newTest richtet keinen CalDAV-Server ein
+	if _, err := c.SyncNow(); err == nil || !strings.Contains(err.Error(), "Kein CalDAV-Server") {
+		t.Errorf("Abgleich ohne Server: %v", err)
+	}
+
+	if _, err := Dial(context.Background(), config.API{Listen: strings.TrimPrefix(srv.URL, "http://"), Token: testToken},
+		"andere.db", nil); err == nil {
+		t.Error("Andere Datenbank ohne Fehler verbunden")
+	}
+}
+
+// post sendet body als JSON mit dem Test-Token
+func post(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestImport(t *testing.T) {
+	s, _, srv := newTest(t)
+	body := `{"appointments": [{"title": "Arzt", "date": "2030-03-14", "time": "09:30", "priority": "Hoch", "uid": "arzt@example.org"}],
+		"tasks": [{"title": "Einkaufen"}]}`
+
+	resp := post(t, srv.URL+"/import", body)
+	var imported Imported
+	if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Import: %s, %v", resp.Status, err)
+	}
+	if imported.Appointments != 1 || imported.Tasks != 1 || imported.Duplicates != 0 {
+		t.Errorf("Import lieferte %+v", imported)
+	}
+	all, err := s.QueryAppointments(store.Filter{})
+	if err != nil || len(all) != 1 || all[0].Priority == nil || *all[0].Priority != priority.High {
+		t.Errorf("Im Store: %+v, %v", all, err)
+	}
+
+	// Bekannte UIDs werden übersprungen, ungültige Einträge abgelehnt
+	resp = post(t, srv.URL+"/import", body)
+	if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil || imported.Duplicates != 1 || imported.Tasks != 1 {
+		t.Errorf("Zweiter Import: %+v, %v", imported, err)
+	}
+	resp = post(t, srv.URL+"/import", `{"appointments": [{"title": "Kaputt", "date": "gestern"}]}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Ungültiger Termin: %s", resp.Status)
+	}
+}
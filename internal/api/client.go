@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Client spricht mit der Schnittstelle eines laufenden reminderd. Er
+// bietet die Schreibzugriffe des Stores, die die GUI braucht, sodass der
+// Daemon als einziger Prozess Termine und Aufgaben ändert.
+type Client struct {
+	http   *http.Client
+	stream *http.Client // ohne Zeitlimit, für /events
+	base   string
+	token  string
+	levels func() priority.Set
+}
+
+// Dial verbindet mit reminderd und prüft, dass er die Datenbank database
+// verwendet. levels liefert die Prioritätsstufen für die Umwandlung der
+// Antworten.
+func Dial(ctx context.Context, cfg config.API, database string, levels func() priority.Set) (*Client, error) {
+	if cfg.Listen == config.APIOff {
+		return nil, fmt.Errorf("Die Schnittstelle von reminderd ist abgeschaltet")
+	}
+	token, err := ReadToken(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Kein Token für reminderd: %v", err)
+	}
+
+	network, address := Address(cfg)
+	transport := &http.Transport{}
+	base := "http://" + address
+	if network == "unix" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", address)
+		}
+		base = "http://reminderd"
+	}
+	c := &Client{
+		http:   &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		stream: &http.Client{Transport: transport},
+		base:   base,
+		token:  token,
+		levels: levels,
+	}
+
+	var h Health
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &h); err != nil {
+		return nil, err
+	}
+	if h.Database != database {
+		return nil, fmt.Errorf("reminderd verwendet die Datenbank %s statt %s", h.Database, database)
+	}
+	return c, nil
+}
+
+// do sendet eine Anfrage mit in als JSON-Inhalt und liest die Antwort nach out
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return c.send(ctx, c.http, method, path, in, out)
+}
+
+// send ist do mit dem HTTP-Client hc, z.B. ohne Zeitlimit für lange Anfragen
+func (c *Client) send(ctx context.Context, hc *http.Client, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("reminderd nicht erreichbar: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		if resp.StatusCode == http.StatusForbidden {
+			return store.ErrReadOnly
+		}
+		if resp.StatusCode == http.StatusConflict {
+			for _, known := range []error{store.ErrNothingToUndo, store.ErrNothingToRedo, store.ErrUndoConflict} {
+				if e.Error == known.Error() {
+					return known
+				}
+			}
+		}
+		if e.Error == "" {
+			e.Error = resp.Status
+		}
+		return errors.New(e.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Ohne Stufen wird die Priorität als Zahl übertragen
+func toAppointment(a store.Appointment) Appointment {
+	return fromAppointment(a, nil)
+}
+
+func toTask(t store.Task) Task {
+	return fromTask(t, nil)
+}
+
+// AddAppointment legt einen Termin an und setzt dessen ID
+func (c *Client) AddAppointment(a *store.Appointment) error {
+	var out Appointment
+	if err := c.do(context.Background(), http.MethodPost, "/appointments", toAppointment(*a), &out); err != nil {
+		return err
+	}
+	a.ID = out.ID
+	return nil
+}
+
+// UpdateAppointment überschreibt einen Termin
+func (c *Client) UpdateAppointment(a store.Appointment) error {
+	return c.do(context.Background(), http.MethodPut, fmt.Sprintf("/appointments/%d", a.ID), toAppointment(a), nil)
+}
+
+// DeleteAppointment löscht einen Termin und liefert den Eintrag im Änderungsjournal
+func (c *Client) DeleteAppointment(id int64) (store.Operation, error) {
+	return c.operation(http.MethodDelete, fmt.Sprintf("/appointments/%d", id), false)
+}
+
+// DeleteAllAppointments löscht alle eigenen Termine
+func (c *Client) DeleteAllAppointments() (store.Operation, error) {
+	return c.operation(http.MethodDelete, "/appointments", false)
+}
+
+// PostponeAppointment verschiebt einen Termin um d (mindestens eine Minute)
+func (c *Client) PostponeAppointment(id int64, d time.Duration) (store.Appointment, error) {
+	in := map[string]int{"minutes": int(d.Minutes())}
+	var out Appointment
+	if err := c.do(context.Background(), http.MethodPost, fmt.Sprintf("/appointments/%d/snooze", id), in, &out); err != nil {
+		return store.Appointment{}, err
+	}
+	a := store.Appointment{ID: out.ID, UID: out.UID, SubscriptionID: out.SubscriptionID}
+	return a, out.apply(&a, c.levels())
+}
+
+// Acknowledge bestätigt die Erinnerung an einen Termin zum Zeitpunkt due
+func (c *Client) Acknowledge(id int64, due time.Time) error {
+	in := map[string]string{"due": due.Format(dueLayout)}
+	return c.do(context.Background(), http.MethodPost, fmt.Sprintf("/appointments/%d/ack", id), in, nil)
+}
+
+// AddTask legt eine Aufgabe an und setzt deren ID
+func (c *Client) AddTask(t *store.Task) error {
+	var out Task
+	if err := c.do(context.Background(), http.MethodPost, "/tasks", toTask(*t), &out); err != nil {
+		return err
+	}
+	t.ID = out.ID
+	return nil
+}
+
+// UpdateTask überschreibt eine Aufgabe
+func (c *Client) UpdateTask(t store.Task) error {
+	return c.do(context.Background(), http.MethodPut, fmt.Sprintf("/tasks/%d", t.ID), toTask(t), nil)
+}
+
+// DeleteTask löscht eine Aufgabe und liefert den Eintrag im Änderungsjournal
+func (c *Client) DeleteTask(id int64) (store.Operation, error) {
+	return c.operation(http.MethodDelete, fmt.Sprintf("/tasks/%d", id), false)
+}
+
+// Import speichert Termine und Aufgaben als eine Änderung, wie store.Import
+func (c *Client) Import(appointments []store.Appointment, tasks []store.Task) (store.Imported, error) {
+	in := Import{Appointments: make([]Appointment, len(appointments)), Tasks: make([]Task, len(tasks))}
+	for i, a := range appointments {
+		in.Appointments[i] = toAppointment(a)
+	}
+	for i, t := range tasks {
+		in.Tasks[i] = toTask(t)
+	}
+	var out Imported
+	if err := c.do(context.Background(), http.MethodPost, "/import", in, &out); err != nil {
+		return store.Imported{}, err
+	}
+	return store.Imported{
+		Appointments: out.Appointments, Tasks: out.Tasks, Duplicates: out.Duplicates,
+		Operation: out.Operation.operation(false),
+	}, nil
+}
+
+// AddSubscription legt ein Abo an und setzt dessen ID
+func (c *Client) AddSubscription(sub *store.Subscription) error {
+	var out Subscription
+	if err := c.do(context.Background(), http.MethodPost, "/subscriptions", fromSubscription(*sub), &out); err != nil {
+		return err
+	}
+	sub.ID = out.ID
+	return nil
+}
+
+// UpdateSubscription ändert ein Abo
+func (c *Client) UpdateSubscription(sub store.Subscription) error {
+	return c.do(context.Background(), http.MethodPut, fmt.Sprintf("/subscriptions/%d", sub.ID), fromSubscription(sub), nil)
+}
+
+// DeleteSubscription entfernt ein Abo samt seinen Terminen
+func (c *Client) DeleteSubscription(id int64) error {
+	return c.do(context.Background(), http.MethodDelete, fmt.Sprintf("/subscriptions/%d", id), nil, nil)
+}
+
+// RefreshSubscription lässt reminderd ein Abo sofort abrufen
+func (c *Client) RefreshSubscription(id int64) error {
+	return c.do(context.Background(), http.MethodPost, fmt.Sprintf("/subscriptions/%d/refresh", id), nil, nil)
+}
+
+// SetSetting schreibt eine Einstellung in der Datenbank
+func (c *Client) SetSetting(key, value string) error {
+	return c.do(context.Background(), http.MethodPut, "/settings/"+url.PathEscape(key), Setting{Value: value}, nil)
+}
+
+// Undo macht die letzte Änderung rückgängig und liefert sie
+func (c *Client) Undo() (store.Operation, error) {
+	return c.operation(http.MethodPost, "/undo", true)
+}
+
+// Redo stellt die zuletzt rückgängig gemachte Änderung wieder her
+func (c *Client) Redo() (store.Operation, error) {
+	return c.operation(http.MethodPost, "/redo", false)
+}
+
+// UndoOp macht genau die Änderung mit der ID id rückgängig
+func (c *Client) UndoOp(id int64) (store.Operation, error) {
+	return c.operation(http.MethodPost, fmt.Sprintf("/undo/%d", id), true)
+}
+
+// RedoOp stellt genau die Änderung mit der ID id wieder her
+func (c *Client) RedoOp(id int64) (store.Operation, error) {
+	return c.operation(http.MethodPost, fmt.Sprintf("/redo/%d", id), false)
+}
+
+// operation sendet eine Anfrage, die einen Eintrag im Änderungsjournal liefert
+func (c *Client) operation(method, path string, undone bool) (store.Operation, error) {
+	var out Operation
+	err := c.do(context.Background(), method, path, nil, &out)
+	return out.operation(undone), err
+}
+
+// Events abonniert die Ereignisse von reminderd. Mit display=true zeigt
+// der Aufrufer Erinnerungen an und reminderd selbst nicht mehr. Der Kanal
+// wird geschlossen, wenn die Verbindung endet.
+func (c *Client) Events(ctx context.Context, display bool) (<-chan Event, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.base+"/events?display="+strconv.FormatBool(display), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reminderd nicht erreichbar: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Ereignisse von reminderd nicht verfügbar: %s", resp.Status)
+	}
+
+	events := make(chan Event, 16)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		var e Event
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if e.Name != "" {
+					events <- e
+				}
+				e = Event{}
+			case strings.HasPrefix(line, "id: "):
+				e.ID, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				e.Name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				e.Data = json.RawMessage(strings.TrimPrefix(line, "data: "))
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,471 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Ereignisse auf /events
+const (
+	EventChanged     = "data.changed"        // Changed: Termine, Aufgaben oder Einstellungen geändert
+	EventReminder    = "reminder.due"        // Reminder: Erinnerung ausgelöst
+	EventSnoozed     = "reminder.snoozed"    // Snoozed: Termin über die Schnittstelle verschoben
+	EventAppointment = "appointment.changed" // AppointmentChanged: Termin angelegt, geändert oder gelöscht
+	EventTaskDone    = "task.completed"      // TaskCompleted: Aufgabe erledigt
+)
+
+// Changed ist die Nutzlast von data.changed. Bei Änderungen durch andere
+// Prozesse ist Table leer.
+type Changed struct {
+	Table string `json:"table,omitempty"`
+	ID    int64  `json:"id,omitempty"`
+}
+
+// Reminder ist die Nutzlast von reminder.due
+type Reminder struct {
+	Kind        string      `json:"kind"` // due, early, reminder, nag oder overlay
+	Appointment Appointment `json:"appointment"`
+	Due         string      `json:"due"`               // YYYY-MM-DD HH:MM
+	Minutes     int         `json:"minutes,omitempty"` // bei early und reminder: Minuten bis zum Termin
+}
+
+// Snoozed ist die Nutzlast von reminder.snoozed
+type Snoozed struct {
+	Appointment Appointment `json:"appointment"` // mit dem neuen Zeitpunkt
+	Minutes     int         `json:"minutes"`
+}
+
+// AppointmentChanged ist die Nutzlast von appointment.changed. Ohne ID
+// wurden mehrere Termine geändert, z.B. beim Aktualisieren eines Abonnements.
+type AppointmentChanged struct {
+	ID          int64        `json:"id,omitempty"`
+	Appointment *Appointment `json:"appointment,omitempty"`
+	Deleted     bool         `json:"deleted,omitempty"`
+}
+
+// TaskCompleted ist die Nutzlast von task.completed
+type TaskCompleted struct {
+	Task Task `json:"task"`
+}
+
+// Event ist ein Ereignis im Format von Server-Sent Events
+type Event struct {
+	ID   int64
+	Name string
+	Data json.RawMessage
+}
+
+// Wartezeit zwischen zwei Lebenszeichen auf /events
+const keepAlive = 30 * time.Second
+
+// Anzahl der zuletzt gesendeten Ereignisse, die nach einer Unterbrechung
+// per Last-Event-ID erneut gesendet werden können
+const replaySize = 500
+
+type subscriber struct {
+	events  chan Event
+	queue   *queue          // statt events für Abonnenten im selben Prozess
+	display bool            // zeigt Erinnerungen selbst an (GUI)
+	names   map[string]bool // abonnierte Ereignisse, leer für alle
+}
+
+func (sub *subscriber) wants(name string) bool {
+	return len(sub.names) == 0 || sub.names[name]
+}
+
+// end beendet das Abonnement; discard verwirft noch nicht abgeholte
+// Ereignisse eines Abonnenten im selben Prozess
+func (sub *subscriber) end(discard bool) {
+	if sub.queue != nil {
+		sub.queue.end(discard)
+		return
+	}
+	close(sub.events)
+}
+
+// queue nimmt Ereignisse für einen Abonnenten im selben Prozess ohne
+// Obergrenze an und reicht sie der Reihe nach weiter, damit z.B. Webhooks
+// auch bei vielen Ereignissen auf einmal keins verlieren
+type queue struct {
+	mu      sync.Mutex
+	pending []Event
+	closed  bool
+	wake    chan struct{}
+	stop    chan struct{} // geschlossen, wenn ausstehende Ereignisse verworfen werden
+}
+
+func newQueue() (*queue, <-chan Event) {
+	q := &queue{wake: make(chan struct{}, 1), stop: make(chan struct{})}
+	out := make(chan Event)
+	go q.run(out)
+	return q, out
+}
+
+func (q *queue) push(e Event) {
+	q.mu.Lock()
+	q.pending = append(q.pending, e)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *queue) end(discard bool) {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	if discard {
+		close(q.stop)
+	}
+	q.signal()
+}
+
+func (q *queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run reicht die Ereignisse an out weiter und schließt out am Ende
+func (q *queue) run(out chan<- Event) {
+	defer close(out)
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		}
+		q.mu.Lock()
+		pending, closed := q.pending, q.closed
+		q.pending = nil
+		q.mu.Unlock()
+		for _, e := range pending {
+			select {
+			case out <- e:
+			case <-q.stop:
+				return
+			}
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// Hub verteilt Ereignisse an die Abonnenten von /events
+type Hub struct {
+	store *store.Store
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+	lastID      int64
+	recent      []Event        // die letzten replaySize Ereignisse
+	completed   map[int64]bool // erledigte Aufgaben, für task.completed
+}
+
+// NewHub erstellt einen Hub für die Ereignisse eines Stores. Die IDs der
+// Ereignisse beginnen bei der Startzeit in Mikrosekunden, damit sie auch
+// über einen Neustart von reminderd hinweg aufsteigen.
+func NewHub(s *store.Store) *Hub {
+	return &Hub{
+		store:       s,
+		subscribers: make(map[*subscriber]struct{}),
+		lastID:      time.Now().UnixMicro(),
+	}
+}
+
+// Run meldet Änderungen am Store als data.changed, appointment.changed und
+// task.completed, bis ctx beendet wird. Danach werden alle Abonnements
+// beendet.
+func (h *Hub) Run(ctx context.Context) {
+	changes, cancel := h.store.Subscribe()
+	defer cancel()
+	defer h.close()
+
+	h.completed = h.completedTasks()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c, ok := <-changes:
+			if !ok {
+				return
+			}
+			h.Publish(EventChanged, Changed{Table: c.Table, ID: c.ID})
+			if c.Table == store.TableAppointments {
+				h.appointmentChanged(c.ID)
+			}
+			if c.Affects(store.TableTasks) {
+				h.tasksChanged(c.ID)
+			}
+		}
+	}
+}
+
+func (h *Hub) levels() priority.Set {
+	levels, err := h.store.PriorityLevels()
+	if err != nil {
+		log.Printf("%v", err)
+		return priority.Defaults()
+	}
+	return levels
+}
+
+// appointmentChanged meldet einen angelegten, geänderten oder gelöschten Termin
+func (h *Hub) appointmentChanged(id int64) {
+	if id == 0 {
+		h.Publish(EventAppointment, AppointmentChanged{})
+		return
+	}
+	a, err := h.store.GetAppointment(id)
+	if err == sql.ErrNoRows {
+		h.Publish(EventAppointment, AppointmentChanged{ID: id, Deleted: true})
+		return
+	}
+	if err != nil {
+		log.Printf("Fehler beim Laden des Termins ID=%d: %v", id, err)
+		return
+	}
+	dto := fromAppointment(a, h.levels())
+	h.Publish(EventAppointment, AppointmentChanged{ID: id, Appointment: &dto})
+}
+
+// tasksChanged meldet Aufgaben, die seit der letzten Änderung erledigt
+// wurden. Ohne ID werden alle Aufgaben verglichen, da andere Prozesse nicht
+// melden, was sie geändert haben.
+func (h *Hub) tasksChanged(id int64) {
+	var tasks []store.Task
+	if id == 0 {
+		all, err := h.store.QueryTasks(store.Filter{})
+		if err != nil {
+			log.Printf("Fehler beim Laden der Aufgaben: %v", err)
+			return
+		}
+		tasks = all
+		completed := make(map[int64]bool, len(all))
+		for _, t := range all {
+			completed[t.ID] = h.completed[t.ID]
+		}
+		h.completed = completed
+	} else {
+		t, err := h.store.GetTask(id)
+		if err == sql.ErrNoRows {
+			delete(h.completed, id)
+			return
+		}
+		if err != nil {
+			log.Printf("Fehler beim Laden der Aufgabe ID=%d: %v", id, err)
+			return
+		}
+		tasks = []store.Task{t}
+	}
+
+	levels := h.levels()
+	for _, t := range tasks {
+		if t.Completed && !h.completed[t.ID] {
+			h.Publish(EventTaskDone, TaskCompleted{Task: fromTask(t, levels)})
+		}
+		h.completed[t.ID] = t.Completed
+	}
+}
+
+func (h *Hub) completedTasks() map[int64]bool {
+	completed := make(map[int64]bool)
+	tasks, err := h.store.QueryTasks(store.Filter{})
+	if err != nil {
+		log.Printf("Fehler beim Laden der Aufgaben: %v", err)
+		return completed
+	}
+	for _, t := range tasks {
+		completed[t.ID] = t.Completed
+	}
+	return completed
+}
+
+// PublishReminder meldet eine ausgelöste Erinnerung und liefert true, wenn
+// ein Abonnent sie anzeigt
+func (h *Hub) PublishReminder(kind string, a store.Appointment, due time.Time, minutes int) bool {
+	return h.Publish(EventReminder, Reminder{
+		Kind:        kind,
+		Appointment: fromAppointment(a, h.levels()),
+		Due:         due.Format(dueLayout),
+		Minutes:     minutes,
+	}) > 0
+}
+
+// Publish sendet ein Ereignis an alle Abonnenten und liefert die Anzahl
+// derer, die Erinnerungen selbst anzeigen. Ein Abonnent von /events, der
+// nicht mitkommt, verliert das Ereignis, kann es aber per Last-Event-ID
+// erneut abrufen; Abonnenten im selben Prozess erhalten alle.
+func (h *Hub) Publish(name string, data interface{}) int {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Fehler beim Erstellen des Ereignisses %s: %v", name, err)
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	e := Event{ID: h.lastID, Name: name, Data: raw}
+	h.recent = append(h.recent, e)
+	if len(h.recent) > replaySize {
+		h.recent = append(h.recent[:0], h.recent[len(h.recent)-replaySize:]...)
+	}
+
+	displayed := 0
+	for sub := range h.subscribers {
+		if !sub.wants(name) {
+			continue
+		}
+		if sub.queue != nil {
+			sub.queue.push(e)
+			continue
+		}
+		select {
+		case sub.events <- e:
+			if sub.display {
+				displayed++
+			}
+		default:
+			log.Printf("Ereignis %s verworfen: Abonnent zu langsam", name)
+		}
+	}
+	return displayed
+}
+
+// subscribe meldet einen Abonnenten an und liefert die gewünschten
+// Ereignisse nach lastID, die er verpasst hat. Ist lastID nicht mehr
+// vorgehalten, sind es alle vorgehaltenen.
+func (h *Hub) subscribe(sub *subscriber, lastID int64) ([]Event, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	h.subscribers[sub] = struct{}{}
+
+	var missed []Event
+	if lastID > 0 {
+		for _, e := range h.recent {
+			if e.ID > lastID && sub.wants(e.Name) {
+				missed = append(missed, e)
+			}
+		}
+	}
+	return missed, true
+}
+
+// Subscribe liefert alle weiteren Ereignisse an einen Abonnenten im selben
+// Prozess, z.B. die Hooks, ohne eins zu verwerfen. Die zurückgegebene
+// Funktion beendet das Abonnement; beim Beenden des Hubs wird der Kanal
+// nach den ausstehenden Ereignissen geschlossen.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	q, events := newQueue()
+	sub := &subscriber{queue: q}
+	if _, ok := h.subscribe(sub, 0); !ok {
+		q.end(false)
+		return events, func() {}
+	}
+	return events, func() { h.unsubscribe(sub) }
+}
+
+func (h *Hub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		sub.end(true)
+	}
+}
+
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		sub.end(false)
+	}
+}
+
+// events liefert Ereignisse als Server-Sent Events. Mit display=true
+// übernimmt der Abonnent das Anzeigen von Erinnerungen, mit types=a,b
+// erhält er nur diese Ereignisse. Nach einer Unterbrechung werden die
+// Ereignisse nach Last-Event-ID (Header oder Parameter last_event_id)
+// erneut gesendet.
+func (srv *Server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("Ereignisse werden nicht unterstützt"))
+		return
+	}
+	q := r.URL.Query()
+	names := make(map[string]bool)
+	if types := q.Get("types"); types != "" {
+		for _, name := range strings.Split(types, ",") {
+			names[strings.TrimSpace(name)] = true
+		}
+	}
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		last = q.Get("last_event_id")
+	}
+	var lastID int64
+	if last != "" {
+		id, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Ungültige Last-Event-ID: %s", last))
+			return
+		}
+		lastID = id
+	}
+
+	sub := &subscriber{events: make(chan Event, 64), display: q.Get("display") == "true", names: names}
+	missed, ok := srv.hub.subscribe(sub, lastID)
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("reminderd wird beendet"))
+		return
+	}
+	defer srv.hub.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, e := range missed {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Name, e.Data)
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// next liest Ereignisse, bis eins mit dem Namen name kommt
+func next(t *testing.T, events <-chan Event, name string) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("Verbindung beendet, %s fehlt", name)
+			}
+			if e.Name == name {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("%s nicht erhalten", name)
+		}
+	}
+}
+
+// running wartet, bis der Hub Änderungen am Store meldet
+func running(t *testing.T, s *store.Store, events <-chan Event) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if err := s.SetSetting("test", strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-events:
+			if e.Name == EventChanged {
+				return
+			}
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	t.Fatal("Hub meldet keine Änderungen")
+}
+
+func TestEvents(t *testing.T) {
+	s, hub, srv := newTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	c, err := dial(t, srv, s, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := c.Events(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	running(t, s, events)
+
+	a := store.Appointment{Title: "Arzt", Date: "2030-03-14", Time: "09:30"}
+	if err := s.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	var changed AppointmentChanged
+	e := next(t, events, EventAppointment)
+	if err := json.Unmarshal(e.Data, &changed); err != nil || changed.ID != a.ID || changed.Appointment == nil ||
+		changed.Appointment.Title != "Arzt" {
+		t.Errorf("%s: %s", e.Name, e.Data)
+	}
+	first := e.ID
+	if _, err := s.DeleteAppointment(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	e = next(t, events, EventAppointment)
+	if err := json.Unmarshal(e.Data, &changed); err != nil || !changed.Deleted || e.ID <= first {
+		t.Errorf("%s nach dem Löschen: %d %s", e.Name, e.ID, e.Data)
+	}
+
+	task := store.Task{Title: "Einkaufen"}
+	if err := s.AddTask(&task); err != nil {
+		t.Fatal(err)
+	}
+	task.Completed = true
+	if err := s.UpdateTask(task); err != nil {
+		t.Fatal(err)
+	}
+	var completed TaskCompleted
+	e = next(t, events, EventTaskDone)
+	if err := json.Unmarshal(e.Data, &completed); err != nil || completed.Task.ID != task.ID || !completed.Task.Completed {
+		t.Errorf("%s: %s", e.Name, e.Data)
+	}
+
+	// Ein Abonnent mit display=true zeigt die Erinnerung an
+	due := time.Date(2030, 3, 14, 9, 30, 0, 0, time.Local)
+	if !hub.PublishReminder("early", a, due, 15) {
+		t.Error("Erinnerung nicht als angezeigt gemeldet")
+	}
+	var reminder Reminder
+	e = next(t, events, EventReminder)
+	if err := json.Unmarshal(e.Data, &reminder); err != nil || reminder.Kind != "early" || reminder.Minutes != 15 ||
+		reminder.Due != "2030-03-14 09:30" || reminder.Appointment.Title != "Arzt" {
+		t.Errorf("%s: %s", e.Name, e.Data)
+	}
+
+	// Nach Ende des Hubs wird die Verbindung geschlossen
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Verbindung nach Ende des Hubs nicht geschlossen")
+		}
+	}
+}
+
+// stream liest die Ereignisse von /events mit den Parametern query
+func stream(t *testing.T, ctx context.Context, url, query, lastID string) <-chan Event {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("/events?%s: %s", query, resp.Status)
+	}
+
+	events := make(chan Event, 16)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		var e Event
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			name, value, _ := strings.Cut(scanner.Text(), ": ")
+			switch name {
+			case "":
+				if e.Name != "" {
+					events <- e
+				}
+				e = Event{}
+			case "id":
+				e.ID, _ = strconv.ParseInt(value, 10, 64)
+			case "event":
+				e.Name = value
+			case "data":
+				e.Data = json.RawMessage(value)
+			}
+		}
+	}()
+	return events
+}
+
+func TestEventsReplay(t *testing.T) {
+	_, hub, srv := newTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := store.Appointment{Title: "Arzt", Date: "2030-03-14", Time: "09:30"}
+	due := time.Date(2030, 3, 14, 9, 30, 0, 0, time.Local)
+	hub.Publish(EventChanged, Changed{Table: store.TableTasks})
+	first := hub.lastID
+	if hub.PublishReminder("due", a, due, 0) {
+		t.Error("Ohne Abonnent als angezeigt gemeldet")
+	}
+	hub.Publish(EventChanged, Changed{Table: store.TableAppointments})
+	hub.Publish(EventSnoozed, Snoozed{Minutes: 10})
+
+	// Verpasste Ereignisse nach Last-Event-ID, nur die gewünschten Typen
+	events := stream(t, ctx, srv.URL, "types=reminder.due,reminder.snoozed", strconv.FormatInt(first, 10))
+	for _, want := range []string{EventReminder, EventSnoozed} {
+		if e := <-events; e.Name != want || e.ID <= first {
+			t.Errorf("%s mit ID %d, erwartet %s nach Last-Event-ID %d", e.Name, e.ID, want, first)
+		}
+	}
+
+	// Neue Ereignisse anderer Typen werden nicht gesendet
+	hub.Publish(EventChanged, Changed{})
+	hub.PublishReminder("nag", a, due, 0)
+	if e := <-events; e.Name != EventReminder || !strings.Contains(string(e.Data), `"kind":"nag"`) {
+		t.Errorf("%s: %s", e.Name, e.Data)
+	}
+	select {
+	case e := <-events:
+		t.Errorf("Nicht abonniertes Ereignis %s", e.Name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Last-Event-ID als Parameter
+	replayed := stream(t, ctx, srv.URL, "last_event_id="+strconv.FormatInt(first, 10), "")
+	if e := next(t, replayed, EventChanged); !strings.Contains(string(e.Data), store.TableAppointments) {
+		t.Errorf("Erstes erneut gesendetes Ereignis: %s", e.Data)
+	}
+
+	if resp := get(t, srv.URL+"/events?last_event_id=x", testToken); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Ungültige Last-Event-ID: %s", resp.Status)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	_, hub, _ := newTest(t)
+	events, cancel := hub.Subscribe()
+	defer cancel()
+
+	// Mehr Ereignisse auf einmal, als ein Abonnent von /events puffert
+	const n = 1000
+	for i := 0; i < n; i++ {
+		hub.Publish(EventSnoozed, Snoozed{Minutes: i})
+	}
+	hub.close()
+
+	var last int64
+	count := 0
+	for e := range events {
+		if e.ID <= last {
+			t.Fatalf("Ereignis %d nach %d", e.ID, last)
+		}
+		last = e.ID
+		count++
+	}
+	if count != n {
+		t.Errorf("%d von %d Ereignissen erhalten", count, n)
+	}
+
+	// Nach dem Abmelden wartet der Hub nicht auf den Abonnenten
+	_, hub, _ = newTest(t)
+	events, cancel = hub.Subscribe()
+	hub.Publish(EventSnoozed, Snoozed{})
+	cancel()
+	for range events {
+	}
+}
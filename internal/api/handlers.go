@@ -0,0 +1,605 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Höchstgröße einer Anfrage
+const maxBody = 1 << 20
+
+// Größte Anfrage an POST /import
+const maxImport = 32 << 20
+
+// Zeitpunkt einer Erinnerung in Anfragen und Ereignissen
+const dueLayout = "2006-01-02 15:04"
+
+// Appointment ist ein Termin in Anfragen und Antworten. Die Priorität wird
+// als Name der Stufe geliefert und als Name oder Zahl angenommen.
+type Appointment struct {
+	ID             int64            `json:"id"`
+	Title          string           `json:"title"`
+	Date           string           `json:"date"`
+	Time           string           `json:"time,omitempty"`
+	EndTime        string           `json:"end_time,omitempty"`
+	Priority       string           `json:"priority,omitempty"`
+	Tags           []string         `json:"tags,omitempty"`
+	Notes          string           `json:"notes,omitempty"`
+	Alarms         []priority.Alarm `json:"alarms,omitempty"` // Minuten vor dem Termin, oder "15:mail"
+	UID            string           `json:"uid,omitempty"`
+	SubscriptionID int64            `json:"subscription_id,omitempty"`
+	ReadOnly       bool             `json:"read_only,omitempty"`
+}
+
+// Task ist eine Aufgabe in Anfragen und Antworten
+type Task struct {
+	ID        int64    `json:"id"`
+	Title     string   `json:"title"`
+	Completed bool     `json:"completed"`
+	DueDate   string   `json:"due_date,omitempty"`
+	Priority  string   `json:"priority,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+	UID       string   `json:"uid,omitempty"`
+}
+
+// Operation ist ein Eintrag im Änderungsjournal: nach dem Löschen, oder
+// rückgängig gemacht bzw. wiederhergestellt. Die ID ist 0, wenn die
+// Änderung nicht im Journal steht.
+type Operation struct {
+	ID      int64     `json:"id"`
+	Kind    string    `json:"kind"`  // create, edit, complete, delete oder import
+	Table   string    `json:"table"` // appointments, tasks oder leer für beide
+	Title   string    `json:"title"`
+	Count   int       `json:"count"`
+	Text    string    `json:"text"` // Beschreibung, z.B. Termin "Zahnarzt" gelöscht
+	Created time.Time `json:"created"`
+}
+
+// Import sind die Einträge für POST /import
+type Import struct {
+	Appointments []Appointment `json:"appointments"`
+	Tasks        []Task        `json:"tasks"`
+}
+
+// Imported ist die Antwort auf POST /import
+type Imported struct {
+	Appointments int       `json:"appointments"`
+	Tasks        int       `json:"tasks"`
+	Duplicates   int       `json:"duplicates"`
+	Operation    Operation `json:"operation"`
+}
+
+func fromOperation(op store.Operation) Operation {
+	return Operation{ID: op.ID, Kind: op.Kind, Table: op.Table, Title: op.Title, Count: op.Count,
+		Text: op.String(), Created: op.Created}
+}
+
+func (op Operation) operation(undone bool) store.Operation {
+	return store.Operation{ID: op.ID, Kind: op.Kind, Table: op.Table, Title: op.Title, Count: op.Count,
+		Undone: undone, Created: op.Created}
+}
+
+func fromAppointment(a store.Appointment, levels priority.Set) Appointment {
+	return Appointment{
+		ID: a.ID, Title: a.Title, Date: a.Date, Time: a.Time, EndTime: a.EndTime,
+		Priority: priorityName(a.Priority, levels), Tags: a.Tags, Notes: a.Notes,
+		Alarms: a.Alarms, UID: a.UID, SubscriptionID: a.SubscriptionID, ReadOnly: a.ReadOnly(),
+	}
+}
+
+// apply übernimmt die Felder in einen Termin und prüft sie dabei
+func (in Appointment) apply(a *store.Appointment, levels priority.Set) error {
+	if in.Title == "" {
+		return fmt.Errorf("Titel fehlt")
+	}
+	date, err := parseDate(in.Date)
+	if err != nil {
+		return err
+	}
+	if date == "" {
+		return fmt.Errorf("Datum fehlt")
+	}
+	for _, t := range []string{in.Time, in.EndTime} {
+		if _, err := time.Parse("15:04", t); t != "" && err != nil {
+			return fmt.Errorf("Ungültige Uhrzeit: %q (erwartet HH:MM)", t)
+		}
+	}
+	p, err := parsePriority(in.Priority, levels)
+	if err != nil {
+		return err
+	}
+
+	a.Title, a.Date, a.Time, a.EndTime = in.Title, date, in.Time, in.EndTime
+	a.Priority, a.Tags, a.Notes, a.Alarms = p, in.Tags, in.Notes, in.Alarms
+	return nil
+}
+
+func fromTask(t store.Task, levels priority.Set) Task {
+	return Task{
+		ID: t.ID, Title: t.Title, Completed: t.Completed, DueDate: t.DueDate,
+		Priority: priorityName(t.Priority, levels), Tags: t.Tags, Notes: t.Notes, UID: t.UID,
+	}
+}
+
+func (in Task) apply(t *store.Task, levels priority.Set) error {
+	if in.Title == "" {
+		return fmt.Errorf("Titel fehlt")
+	}
+	due, err := parseDate(in.DueDate)
+	if err != nil {
+		return err
+	}
+	p, err := parsePriority(in.Priority, levels)
+	if err != nil {
+		return err
+	}
+	t.Title, t.Completed, t.DueDate = in.Title, in.Completed, due
+	t.Priority, t.Tags, t.Notes = p, in.Tags, in.Notes
+	return nil
+}
+
+func priorityName(p *int, levels priority.Set) string {
+	if p == nil {
+		return ""
+	}
+	return levels.Lookup(p).Name
+}
+
+func parsePriority(name string, levels priority.Set) (*int, error) {
+	if name == "" {
+		return nil, nil
+	}
+	v, err := levels.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseDate akzeptiert ISO- und deutsches Datumsformat
+func parseDate(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	date := dates.ConvertToISODate(s)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", fmt.Errorf("Ungültiges Datum: %q", s)
+	}
+	return date, nil
+}
+
+// filter liest Suche und Filter aus den Parametern der Anfrage
+func filter(r *http.Request, levels priority.Set) (store.Filter, error) {
+	q := r.URL.Query()
+	f := store.Filter{Text: q.Get("q"), Tag: q.Get("tag"), SortBy: q.Get("sort"), Desc: q.Get("desc") == "true"}
+	var err error
+	if f.From, err = parseDate(q.Get("from")); err != nil {
+		return f, err
+	}
+	if f.To, err = parseDate(q.Get("to")); err != nil {
+		return f, err
+	}
+	if f.Priority, err = parsePriority(q.Get("priority"), levels); err != nil {
+		return f, err
+	}
+	if c := q.Get("completed"); c != "" {
+		completed, err := strconv.ParseBool(c)
+		if err != nil {
+			return f, fmt.Errorf("Ungültiger Wert für completed: %q", c)
+		}
+		f.Completed = &completed
+	}
+	f.OwnOnly = q.Get("own") == "true"
+	return f, nil
+}
+
+// decode liest den JSON-Inhalt einer Anfrage; ein leerer Inhalt lässt v unverändert
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return decodeLimit(w, r, v, maxBody)
+}
+
+func decodeLimit(w http.ResponseWriter, r *http.Request, v interface{}, limit int64) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("Ungültiger Inhalt: %v", err)
+	}
+	return nil
+}
+
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Ungültige ID: %s", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// status ordnet Fehler des Stores einem HTTP-Status zu
+func status(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrReadOnly):
+		return http.StatusForbidden
+	case errors.Is(err, store.ErrNothingToUndo), errors.Is(err, store.ErrNothingToRedo),
+		errors.Is(err, store.ErrUndoConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (srv *Server) levels() priority.Set {
+	levels, err := srv.store.PriorityLevels()
+	if err != nil {
+		return priority.Defaults()
+	}
+	return levels
+}
+
+// appointment lädt den Termin aus dem Pfad und meldet Fehler selbst
+func (srv *Server) appointment(w http.ResponseWriter, r *http.Request) (store.Appointment, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return store.Appointment{}, false
+	}
+	a, err := srv.store.GetAppointment(id)
+	if err != nil {
+		code := status(err)
+		if code == http.StatusNotFound {
+			err = fmt.Errorf("Termin ID=%d nicht gefunden", id)
+		}
+		writeError(w, code, err)
+		return a, false
+	}
+	return a, true
+}
+
+func (srv *Server) task(w http.ResponseWriter, r *http.Request) (store.Task, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return store.Task{}, false
+	}
+	t, err := srv.store.GetTask(id)
+	if err != nil {
+		code := status(err)
+		if code == http.StatusNotFound {
+			err = fmt.Errorf("Aufgabe ID=%d nicht gefunden", id)
+		}
+		writeError(w, code, err)
+		return t, false
+	}
+	return t, true
+}
+
+func (srv *Server) listAppointments(w http.ResponseWriter, r *http.Request) {
+	levels := srv.levels()
+	f, err := filter(r, levels)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	appointments, err := srv.store.QueryAppointments(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Appointment, len(appointments))
+	for i, a := range appointments {
+		out[i] = fromAppointment(a, levels)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (srv *Server) getAppointment(w http.ResponseWriter, r *http.Request) {
+	if a, ok := srv.appointment(w, r); ok {
+		writeJSON(w, http.StatusOK, fromAppointment(a, srv.levels()))
+	}
+}
+
+func (srv *Server) createAppointment(w http.ResponseWriter, r *http.Request) {
+	var in Appointment
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	levels := srv.levels()
+	a := store.Appointment{UID: in.UID}
+	if err := in.apply(&a, levels); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.AddAppointment(&a); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/appointments/%d", a.ID))
+	writeJSON(w, http.StatusCreated, fromAppointment(a, levels))
+}
+
+// updateAppointment ersetzt den Termin (PUT) oder ändert nur die
+// angegebenen Felder (PATCH)
+func (srv *Server) updateAppointment(w http.ResponseWriter, r *http.Request) {
+	a, ok := srv.appointment(w, r)
+	if !ok {
+		return
+	}
+	levels := srv.levels()
+	var in Appointment
+	if r.Method == http.MethodPatch {
+		in = fromAppointment(a, levels)
+	}
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := in.apply(&a, levels); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.UpdateAppointment(a); err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromAppointment(a, levels))
+}
+
+func (srv *Server) deleteAppointment(w http.ResponseWriter, r *http.Request) {
+	a, ok := srv.appointment(w, r)
+	if !ok {
+		return
+	}
+	op, err := srv.store.DeleteAppointment(a.ID)
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromOperation(op))
+}
+
+// deleteAllAppointments löscht alle eigenen Termine
+func (srv *Server) deleteAllAppointments(w http.ResponseWriter, r *http.Request) {
+	op, err := srv.store.DeleteAllAppointments()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromOperation(op))
+}
+
+// snooze verschiebt den Termin um "minutes" Minuten (Standard 5)
+func (srv *Server) snooze(w http.ResponseWriter, r *http.Request) {
+	a, ok := srv.appointment(w, r)
+	if !ok {
+		return
+	}
+	in := struct {
+		Minutes int `json:"minutes"`
+	}{Minutes: 5}
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if in.Minutes <= 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Ungültige Dauer: %d Minuten", in.Minutes))
+		return
+	}
+	if a.Time == "" {
+		writeError(w, http.StatusConflict, fmt.Errorf("Termin ID=%d hat keine Uhrzeit", a.ID))
+		return
+	}
+	a, err := srv.store.PostponeAppointment(a.ID, time.Duration(in.Minutes)*time.Minute)
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	dto := fromAppointment(a, srv.levels())
+	srv.hub.Publish(EventSnoozed, Snoozed{Appointment: dto, Minutes: in.Minutes})
+	writeJSON(w, http.StatusOK, dto)
+}
+
+// acknowledge bestätigt die Erinnerung an den Zeitpunkt "due" oder, ohne
+// Angabe, an den aktuellen Zeitpunkt des Termins
+func (srv *Server) acknowledge(w http.ResponseWriter, r *http.Request) {
+	a, ok := srv.appointment(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Due string `json:"due"` // YYYY-MM-DD HH:MM
+	}
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var due time.Time
+	var err error
+	if in.Due != "" {
+		if due, err = time.ParseInLocation(dueLayout, in.Due, time.Local); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Ungültiger Zeitpunkt: %q (erwartet YYYY-MM-DD HH:MM)", in.Due))
+			return
+		}
+	} else if due, err = a.Due(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err := srv.store.Acknowledge(a.ID, due); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":  a.ID,
+		"due": due.Format(dueLayout),
+	})
+}
+
+func (srv *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	levels := srv.levels()
+	f, err := filter(r, levels)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tasks, err := srv.store.QueryTasks(f)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Task, len(tasks))
+	for i, t := range tasks {
+		out[i] = fromTask(t, levels)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (srv *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	if t, ok := srv.task(w, r); ok {
+		writeJSON(w, http.StatusOK, fromTask(t, srv.levels()))
+	}
+}
+
+func (srv *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var in Task
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	levels := srv.levels()
+	t := store.Task{UID: in.UID}
+	if err := in.apply(&t, levels); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.AddTask(&t); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", t.ID))
+	writeJSON(w, http.StatusCreated, fromTask(t, levels))
+}
+
+func (srv *Server) updateTask(w http.ResponseWriter, r *http.Request) {
+	t, ok := srv.task(w, r)
+	if !ok {
+		return
+	}
+	levels := srv.levels()
+	var in Task
+	if r.Method == http.MethodPatch {
+		in = fromTask(t, levels)
+	}
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := in.apply(&t, levels); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.UpdateTask(t); err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromTask(t, levels))
+}
+
+func (srv *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	t, ok := srv.task(w, r)
+	if !ok {
+		return
+	}
+	op, err := srv.store.DeleteTask(t.ID)
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromOperation(op))
+}
+
+// undo macht die letzte Änderung rückgängig, mit {id} genau diese
+func (srv *Server) undo(w http.ResponseWriter, r *http.Request) {
+	step := srv.store.Undo
+	if r.PathValue("id") != "" {
+		id, err := pathID(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		step = func() (store.Operation, error) { return srv.store.UndoOp(id) }
+	}
+	op, err := step()
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromOperation(op))
+}
+
+// redo stellt die zuletzt rückgängig gemachte Änderung wieder her, mit {id}
+// genau diese
+func (srv *Server) redo(w http.ResponseWriter, r *http.Request) {
+	step := srv.store.Redo
+	if r.PathValue("id") != "" {
+		id, err := pathID(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		step = func() (store.Operation, error) { return srv.store.RedoOp(id) }
+	}
+	op, err := step()
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromOperation(op))
+}
+
+// importEntries speichert Termine und Aufgaben, z.B. aus einer Datei, als
+// eine Änderung im Journal; Einträge mit bekannter UID werden übersprungen
+func (srv *Server) importEntries(w http.ResponseWriter, r *http.Request) {
+	var in Import
+	if err := decodeLimit(w, r, &in, maxImport); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	levels := srv.levels()
+	appointments := make([]store.Appointment, len(in.Appointments))
+	for i, x := range in.Appointments {
+		appointments[i].UID = x.UID
+		if err := x.apply(&appointments[i], levels); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Termin %d: %v", i+1, err))
+			return
+		}
+	}
+	tasks := make([]store.Task, len(in.Tasks))
+	for i, x := range in.Tasks {
+		tasks[i].UID = x.UID
+		if err := x.apply(&tasks[i], levels); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Aufgabe %d: %v", i+1, err))
+			return
+		}
+	}
+	imported, err := srv.store.Import(appointments, tasks)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Imported{
+		Appointments: imported.Appointments, Tasks: imported.Tasks, Duplicates: imported.Duplicates,
+		Operation: fromOperation(imported.Operation),
+	})
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/feeds"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Subscription ist ein Kalender-Abo in Anfragen und Antworten
+type Subscription struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	Source         string `json:"source"`
+	RefreshMinutes int    `json:"refresh_minutes"`
+	Color          string `json:"color,omitempty"`
+	Remind         bool   `json:"remind"`
+	LastRefresh    string `json:"last_refresh,omitempty"` // RFC 3339
+	LastError      string `json:"last_error,omitempty"`
+}
+
+func fromSubscription(sub store.Subscription) Subscription {
+	out := Subscription{
+		ID: sub.ID, Name: sub.Name, Source: sub.Source, RefreshMinutes: int(sub.Refresh / time.Minute),
+		Color: sub.Color, Remind: sub.Remind, LastError: sub.LastError,
+	}
+	if !sub.LastRefresh.IsZero() {
+		out.LastRefresh = sub.LastRefresh.Format(time.RFC3339)
+	}
+	return out
+}
+
+// apply übernimmt die Felder in ein Abo und prüft sie dabei
+func (in Subscription) apply(sub *store.Subscription) error {
+	source := strings.TrimSpace(in.Source)
+	if source == "" {
+		return fmt.Errorf("Quelle fehlt")
+	}
+	if in.RefreshMinutes < 1 {
+		return fmt.Errorf("Ungültiger Abstand: %d Minuten", in.RefreshMinutes)
+	}
+	if _, err := feeds.ParseColor(in.Color); err != nil {
+		return err
+	}
+	sub.Name, sub.Source, sub.Color, sub.Remind = strings.TrimSpace(in.Name), source, in.Color, in.Remind
+	if sub.Name == "" {
+		sub.Name = feeds.Name(source)
+	}
+	sub.Refresh = time.Duration(in.RefreshMinutes) * time.Minute
+	return nil
+}
+
+// Setting ist der Wert einer Einstellung in der Datenbank für PUT /settings/{key}
+type Setting struct {
+	Value string `json:"value"`
+}
+
+func (srv *Server) subscription(w http.ResponseWriter, r *http.Request) (store.Subscription, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return store.Subscription{}, false
+	}
+	sub, err := srv.store.GetSubscription(id)
+	if err != nil {
+		code := status(err)
+		if code == http.StatusNotFound {
+			err = fmt.Errorf("Abo ID=%d nicht gefunden", id)
+		}
+		writeError(w, code, err)
+		return sub, false
+	}
+	return sub, true
+}
+
+func (srv *Server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := srv.store.Subscriptions()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Subscription, len(subs))
+	for i, sub := range subs {
+		out[i] = fromSubscription(sub)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (srv *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var in Subscription
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var sub store.Subscription
+	if err := in.apply(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.AddSubscription(&sub); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/subscriptions/%d", sub.ID))
+	writeJSON(w, http.StatusCreated, fromSubscription(sub))
+}
+
+func (srv *Server) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := srv.subscription(w, r)
+	if !ok {
+		return
+	}
+	var in Subscription
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := in.apply(&sub); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.UpdateSubscription(sub); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, fromSubscription(sub))
+}
+
+func (srv *Server) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := srv.subscription(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.store.DeleteSubscription(sub.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refreshSubscription ruft das Abo sofort ab; schlägt das fehl, ist die
+// Antwort 502 und der Fehler steht auch beim Abo
+func (srv *Server) refreshSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := srv.subscription(w, r)
+	if !ok {
+		return
+	}
+	if err := feeds.Refresh(r.Context(), srv.store, sub); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if sub, err := srv.store.GetSubscription(sub.ID); err == nil {
+		writeJSON(w, http.StatusOK, fromSubscription(sub))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// putSetting schreibt eine Einstellung in der Datenbank, z.B. Nicht stören
+func (srv *Server) putSetting(w http.ResponseWriter, r *http.Request) {
+	var in Setting
+	if err := decode(w, r, &in); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.SetSetting(r.PathValue("key"), in.Value); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/davsync"
+)
+
+// Höchstdauer eines Abgleichs über POST /sync
+const syncTimeout = 5 * time.Minute
+
+// SyncResult ist die Antwort auf POST /sync
+type SyncResult struct {
+	Pulled    int `json:"pulled"`
+	Pushed    int `json:"pushed"`
+	Deleted   int `json:"deleted"`
+	Conflicts int `json:"conflicts"`
+}
+
+// sync gleicht sofort mit dem CalDAV-Server ab. Ohne eingerichteten Server
+// ist die Antwort 409, schlägt der Abgleich fehl, 502.
+func (srv *Server) sync(w http.ResponseWriter, r *http.Request) {
+	if srv.caldav == nil || !srv.caldav().Enabled() {
+		writeError(w, http.StatusConflict, errors.New("Kein CalDAV-Server eingerichtet"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), syncTimeout)
+	defer cancel()
+	result, err := davsync.SyncNow(ctx, srv.store, srv.caldav())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SyncResult(result))
+}
+
+// SyncNow lässt reminderd sofort mit dem CalDAV-Server abgleichen
+func (c *Client) SyncNow() (davsync.Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	var out SyncResult
+	if err := c.send(ctx, c.stream, http.MethodPost, "/sync", nil, &out); err != nil {
+		return davsync.Result{}, err
+	}
+	return davsync.Result(out), nil
+}
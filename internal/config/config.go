@@ -0,0 +1,450 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"Reminder_Erinnerungs_App/internal/quiet"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config sind die Einstellungen aus der Konfigurationsdatei
+type Config struct {
+	DBPath     string    `toml:"db_path"`     // leer = Standardpfad unter $XDG_DATA_HOME
+	Locale     string    `toml:"locale"`      // Sprache der Oberfläche, z.B. "de" oder "en_US", leer = aus LANG
+	QuietHours string    `toml:"quiet_hours"` // Ruhezeit, z.B. "22:00-07:00"
+	Notifiers  []string  `toml:"notifiers"`   // Benachrichtigungswege in der Reihenfolge, in der sie versucht werden
+	Reminders  Reminders `toml:"reminders"`
+	Window     Window    `toml:"window"`
+	CalDAV     CalDAV    `toml:"caldav"`
+	API        API       `toml:"api"`
+	Hooks      []Hook    `toml:"hooks"`
+	Mail       Mail      `toml:"mail"`
+	Push       Push      `toml:"push"`
+}
+
+// Reminders legt fest, wann und wie lange erinnert wird
+type Reminders struct {
+	Alarms     []int  `toml:"alarms"`      // Vorwarnungen für alle Termine in Minuten
+	AutoClose  int    `toml:"auto_close"`  // Nicht-dauerhafte Erinnerungen nach N Minuten schließen
+	Monitor    string `toml:"monitor"`     // Monitor für Erinnerungen: "", "primary", "mouse" oder Name
+	StealFocus bool   `toml:"steal_focus"` // Erinnerungsfenster bekommen den Tastaturfokus
+}
+
+// Window ist Größe, Position und Verhalten des Hauptfensters
+type Window struct {
+	Width  int `toml:"width"`
+	Height int `toml:"height"`
+	X      int `toml:"x"` // 0/0 = vom Fenstermanager platzieren lassen
+	Y      int `toml:"y"`
+
+	Monitor     string `toml:"monitor"`       // "" = zuletzt verwendeter, "primary", "mouse" oder Name
+	CloseToTray bool   `toml:"close_to_tray"` // Schließen legt das Fenster in den Tray
+	Theme       string `toml:"theme"`         // system, light, dark oder high-contrast
+}
+
+// Farbschemata der Oberfläche
+const (
+	ThemeSystem       = "system" // hell oder dunkel wie der Desktop
+	ThemeLight        = "light"
+	ThemeDark         = "dark"
+	ThemeHighContrast = "high-contrast" // Weiß und Gelb auf Schwarz
+)
+
+// Regeln für Konflikte beim CalDAV-Abgleich, wenn ein Eintrag hier und auf
+// dem Server geändert wurde
+const (
+	ConflictServer = "server" // Stand des Servers übernehmen
+	ConflictLocal  = "local"  // eigenen Stand auf den Server schreiben
+	ConflictNewest = "newest" // die jüngere Änderung gewinnt
+)
+
+// CalDAV ist der Server für den Abgleich; ohne URL wird nicht abgeglichen
+type CalDAV struct {
+	URL           string `toml:"url"`
+	Username      string `toml:"username"`
+	Password      string `toml:"password"`       // oder $REMINDER_CALDAV_PASSWORD
+	Calendar      string `toml:"calendar"`       // Pfad des Kalenders, leer = erster mit Terminen
+	TasksCalendar string `toml:"tasks_calendar"` // leer = erster mit Aufgaben, sonst wie calendar
+	Interval      int    `toml:"interval"`       // Abgleich alle N Minuten
+	Conflict      string `toml:"conflict"`       // server, local oder newest
+}
+
+// Enabled gibt an, ob ein CalDAV-Server eingetragen ist
+func (c CalDAV) Enabled() bool {
+	return c.URL != ""
+}
+
+// API ist die Schnittstelle von reminderd für Skripte und andere Programme
+type API struct {
+	Listen string `toml:"listen"` // "" = Unix-Socket, "unix:PFAD", "127.0.0.1:PORT" oder "off"
+	Token  string `toml:"token"`  // leer = zufällig erzeugt in api-token, oder $REMINDER_API_TOKEN
+}
+
+// APIOff schaltet die Schnittstelle ab
+const APIOff = "off"
+
+// Verschlüsselung der Verbindung zum SMTP-Server
+const (
+	MailSTARTTLS = "starttls" // unverschlüsselt verbinden, dann STARTTLS verlangen
+	MailTLS      = "tls"      // von Anfang an TLS, meist Port 465
+	MailNone     = "none"     // unverschlüsselt, nur für Server auf diesem Rechner
+)
+
+// Mail ist der SMTP-Server für Erinnerungen per E-Mail; ohne Host wird keine
+// E-Mail versendet
+type Mail struct {
+	Host     string   `toml:"host"`
+	Port     int      `toml:"port"`     // 0 = 587, bei tls 465
+	Security string   `toml:"security"` // starttls, tls oder none
+	Username string   `toml:"username"`
+	Password string   `toml:"password"` // oder $REMINDER_SMTP_PASSWORD
+	From     string   `toml:"from"`
+	To       []string `toml:"to"`
+	Language string   `toml:"language"` // de oder en, leer = locale
+}
+
+// Enabled gibt an, ob ein SMTP-Server eingetragen ist
+func (m Mail) Enabled() bool {
+	return m.Host != ""
+}
+
+// Address liefert Host und Port des Servers
+func (m Mail) Address() string {
+	port := m.Port
+	if port == 0 {
+		port = 587
+		if m.Security == MailTLS {
+			port = 465
+		}
+	}
+	return net.JoinHostPort(m.Host, strconv.Itoa(port))
+}
+
+// Push sind Dienste für Benachrichtigungen aufs Handy und die Regeln, welche
+// Erinnerungen wohin gehen
+type Push struct {
+	// Prioritätsstufe (Name oder Wert, "*" = alle übrigen) → zusätzliche
+	// Benachrichtigungswege, z.B. Kritisch = ["ntfy", "matrix"]
+	Routes map[string][]string `toml:"routes"`
+	Ntfy   Ntfy                `toml:"ntfy"`
+	Gotify Gotify              `toml:"gotify"`
+	Matrix Matrix              `toml:"matrix"`
+}
+
+// Ntfy ist ein Topic auf einem ntfy-Server; ohne Topic ist ntfy nicht eingerichtet
+type Ntfy struct {
+	URL   string `toml:"url"`
+	Topic string `toml:"topic"`
+	Token string `toml:"token"` // Zugriffstoken, oder $REMINDER_NTFY_TOKEN
+}
+
+// Enabled gibt an, ob ein Topic eingetragen ist
+func (n Ntfy) Enabled() bool {
+	return n.Topic != ""
+}
+
+// Gotify ist eine Anwendung auf einem Gotify-Server
+type Gotify struct {
+	URL   string `toml:"url"`
+	Token string `toml:"token"` // Token der Anwendung, oder $REMINDER_GOTIFY_TOKEN
+}
+
+// Enabled gibt an, ob ein Server eingetragen ist
+func (g Gotify) Enabled() bool {
+	return g.URL != ""
+}
+
+// Matrix ist ein Raum, in den ein Konto des Homeservers schreibt
+type Matrix struct {
+	Homeserver string `toml:"homeserver"`
+	Room       string `toml:"room"`  // Raum-ID, z.B. "!abc:example.org"
+	Token      string `toml:"token"` // Zugriffstoken des Kontos, oder $REMINDER_MATRIX_TOKEN
+}
+
+// Enabled gibt an, ob ein Raum eingetragen ist
+func (m Matrix) Enabled() bool {
+	return m.Room != ""
+}
+
+// Hook wird von reminderd bei Ereignissen ausgeführt: als Webhook, der das
+// Ereignis als JSON erhält, oder als Programm, das es auf stdin erhält
+type Hook struct {
+	Events   []string `toml:"events"`   // z.B. "reminder.due", leer = alle
+	URL      string   `toml:"url"`      // Webhook, erhält das Ereignis per POST
+	Secret   string   `toml:"secret"`   // signiert Webhooks mit HMAC-SHA256
+	Command  []string `toml:"command"`  // Programm und Argumente
+	Timeout  int      `toml:"timeout"`  // Sekunden je Versuch, 0 = 10
+	Attempts int      `toml:"attempts"` // Versuche bei Fehlern des Webhooks, 0 = 4
+}
+
+// Default liefert die Einstellungen, die ohne Konfigurationsdatei gelten
+func Default() Config {
+	return Config{
+		Notifiers: []string{"zenity", "notify-send", "log"},
+		Reminders: Reminders{Alarms: []int{5}, AutoClose: 2, StealFocus: true},
+		Window:    Window{Width: 900, Height: 550, CloseToTray: true, Theme: ThemeSystem},
+		CalDAV:    CalDAV{Interval: 15, Conflict: ConflictServer},
+		Mail:      Mail{Security: MailSTARTTLS},
+		Push:      Push{Ntfy: Ntfy{URL: "https://ntfy.sh"}},
+	}
+}
+
+// Path liefert den Pfad der Konfigurationsdatei: $REMINDER_CONFIG oder
+// $XDG_CONFIG_HOME/reminder-app/config.toml
+func Path() string {
+	if p := os.Getenv("REMINDER_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.toml"
+	}
+	return filepath.Join(dir, "reminder-app", "config.toml")
+}
+
+// Read liest nur die Konfigurationsdatei. Fehlt sie, gelten die
+// Standardwerte; fehlende Einträge behalten ihren Standardwert. Zum Ändern
+// und Zurückschreiben der Datei ist Read statt Load zu verwenden.
+func Read(path string) (Config, error) {
+	c := Default()
+	if _, err := toml.DecodeFile(path, &c); err != nil && !os.IsNotExist(err) {
+		return c, fmt.Errorf("Fehler beim Lesen der Konfiguration %s: %v", path, err)
+	}
+	return c, c.Validate()
+}
+
+// Load liefert die wirksame Konfiguration: Umgebungsvariablen
+// (REMINDER_DB, REMINDER_LOCALE, REMINDER_CALDAV_PASSWORD, REMINDER_API_TOKEN,
+// REMINDER_SMTP_PASSWORD, REMINDER_NTFY_TOKEN, REMINDER_GOTIFY_TOKEN,
+// REMINDER_MATRIX_TOKEN) haben Vorrang vor der Datei
+func Load(path string) (Config, error) {
+	c, err := Read(path)
+	if err != nil {
+		return c, err
+	}
+	if v := os.Getenv("REMINDER_DB"); v != "" {
+		c.DBPath = v
+	}
+	if v := os.Getenv("REMINDER_LOCALE"); v != "" {
+		c.Locale = v
+	}
+	if v := os.Getenv("REMINDER_CALDAV_PASSWORD"); v != "" {
+		c.CalDAV.Password = v
+	}
+	if v := os.Getenv("REMINDER_API_TOKEN"); v != "" {
+		c.API.Token = v
+	}
+	if v := os.Getenv("REMINDER_SMTP_PASSWORD"); v != "" {
+		c.Mail.Password = v
+	}
+	if v := os.Getenv("REMINDER_NTFY_TOKEN"); v != "" {
+		c.Push.Ntfy.Token = v
+	}
+	if v := os.Getenv("REMINDER_GOTIFY_TOKEN"); v != "" {
+		c.Push.Gotify.Token = v
+	}
+	if v := os.Getenv("REMINDER_MATRIX_TOKEN"); v != "" {
+		c.Push.Matrix.Token = v
+	}
+	return c, c.Validate()
+}
+
+// Save schreibt die Konfiguration und legt dazu das Verzeichnis an. Steht
+// ein Passwort, Token oder Schlüssel darin, ist die Datei nur für den
+// Benutzer lesbar.
+func Save(path string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Konfiguration der Reminder-Erinnerungs-App\n\n")
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return fmt.Errorf("Fehler beim Schreiben der Konfiguration: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("Fehler beim Anlegen von %s: %v", filepath.Dir(path), err)
+	}
+	perm := os.FileMode(0o644)
+	if c.CalDAV.Password != "" || c.API.Token != "" || c.Mail.Password != "" ||
+		c.Push.Ntfy.Token != "" || c.Push.Gotify.Token != "" || c.Push.Matrix.Token != "" {
+		perm = 0o600
+	}
+	for _, h := range c.Hooks {
+		if h.Secret != "" {
+			perm = 0o600
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), perm); err != nil {
+		return fmt.Errorf("Fehler beim Schreiben der Konfiguration: %v", err)
+	}
+	if perm != 0o644 {
+		// WriteFile setzt die Rechte nur beim Anlegen
+		return os.Chmod(path, perm)
+	}
+	return nil
+}
+
+// Validate prüft die Werte, die sich nicht schon beim Einlesen prüfen lassen
+func (c Config) Validate() error {
+	if _, err := quiet.Parse(c.QuietHours); err != nil {
+		return err
+	}
+	for _, m := range c.Reminders.Alarms {
+		if m <= 0 {
+			return fmt.Errorf("Ungültige Vorwarnung: %d Minuten", m)
+		}
+	}
+	if c.Reminders.AutoClose <= 0 {
+		return fmt.Errorf("auto_close muss mindestens 1 Minute sein")
+	}
+	if c.Window.Width <= 0 || c.Window.Height <= 0 {
+		return fmt.Errorf("Ungültige Fenstergröße: %dx%d", c.Window.Width, c.Window.Height)
+	}
+	switch c.Window.Theme {
+	case ThemeSystem, ThemeLight, ThemeDark, ThemeHighContrast:
+	default:
+		return fmt.Errorf("Ungültiges Farbschema: %s (erlaubt: system, light, dark, high-contrast)", c.Window.Theme)
+	}
+	if c.CalDAV.Interval <= 0 {
+		return fmt.Errorf("caldav.interval muss mindestens 1 Minute sein")
+	}
+	switch c.CalDAV.Conflict {
+	case ConflictServer, ConflictLocal, ConflictNewest:
+	default:
+		return fmt.Errorf("Ungültige Konfliktregel: %s (erlaubt: server, local, newest)", c.CalDAV.Conflict)
+	}
+	for i, h := range c.Hooks {
+		if err := h.validate(); err != nil {
+			return fmt.Errorf("hooks[%d]: %v", i, err)
+		}
+	}
+	if err := c.Mail.validate(); err != nil {
+		return err
+	}
+	if err := c.Push.validate(); err != nil {
+		return err
+	}
+	return c.API.validate()
+}
+
+func (p Push) validate() error {
+	servers := map[string]string{
+		"push.ntfy.url":          p.Ntfy.URL,
+		"push.gotify.url":        p.Gotify.URL,
+		"push.matrix.homeserver": p.Matrix.Homeserver,
+	}
+	for name, server := range servers {
+		if server == "" {
+			continue
+		}
+		if u, err := url.Parse(server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Ungültige Adresse %s: %s", name, server)
+		}
+	}
+	// Tokens können auch aus der Umgebung kommen und werden daher erst vom
+	// Dienst geprüft
+	switch {
+	case p.Ntfy.Enabled() && p.Ntfy.URL == "":
+		return fmt.Errorf("push.ntfy.url fehlt")
+	case p.Matrix.Enabled() && p.Matrix.Homeserver == "":
+		return fmt.Errorf("push.matrix.homeserver fehlt")
+	}
+	return nil
+}
+
+func (m Mail) validate() error {
+	switch m.Security {
+	case MailSTARTTLS, MailTLS, MailNone:
+	default:
+		return fmt.Errorf("Ungültige Verschlüsselung mail.security: %s (erlaubt: starttls, tls, none)", m.Security)
+	}
+	switch m.Language {
+	case "", "de", "en":
+	default:
+		return fmt.Errorf("Ungültige Sprache mail.language: %s (erlaubt: de, en)", m.Language)
+	}
+	if m.Port < 0 || m.Port > 65535 {
+		return fmt.Errorf("Ungültiger Port mail.port: %d", m.Port)
+	}
+	if !m.Enabled() {
+		return nil
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("mail.to: kein Empfänger eingetragen")
+	}
+	for _, address := range append([]string{m.From}, m.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("Ungültige E-Mail-Adresse %q: %v", address, err)
+		}
+	}
+	return nil
+}
+
+func (h Hook) validate() error {
+	if (h.URL == "") == (len(h.Command) == 0) {
+		return fmt.Errorf("Entweder url oder command angeben")
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("Ungültige Adresse des Webhooks: %s", h.URL)
+		}
+	}
+	if h.Timeout < 0 || h.Attempts < 0 {
+		return fmt.Errorf("timeout und attempts dürfen nicht negativ sein")
+	}
+	return nil
+}
+
+// Über TCP ist die Schnittstelle nur auf diesem Rechner erreichbar
+func (a API) validate() error {
+	if a.Listen == "" || a.Listen == APIOff || strings.HasPrefix(a.Listen, "unix:") {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(a.Listen)
+	if err != nil {
+		return fmt.Errorf("Ungültige Adresse api.listen: %s", a.Listen)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("api.listen muss eine lokale Adresse sein (127.0.0.1, ::1 oder localhost): %s", a.Listen)
+	}
+	return nil
+}
+
+// Flags sind die gemeinsamen Optionen -config und -db aller Programme
+type Flags struct {
+	path   *string
+	dbPath *string
+}
+
+// RegisterFlags registriert -config und -db in fs
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		path:   fs.String("config", Path(), "Pfad zur Konfigurationsdatei"),
+		dbPath: fs.String("db", "", "Pfad zur Datenbank (überschreibt db_path)"),
+	}
+}
+
+// Path liefert den Pfad der Konfigurationsdatei nach dem Parsen der Flags
+func (f *Flags) Path() string {
+	return *f.path
+}
+
+// Load lädt die Konfiguration; -db hat Vorrang vor Datei und Umgebung
+func (f *Flags) Load() (Config, error) {
+	c, err := Load(*f.path)
+	if *f.dbPath != "" {
+		c.DBPath = *f.dbPath
+	}
+	return c, err
+}
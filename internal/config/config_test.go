@@ -0,0 +1,204 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// write legt eine Konfigurationsdatei mit dem Inhalt content an
+func write(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	// Ohne Datei gelten die Standardwerte
+	c, err := Read(filepath.Join(t.TempDir(), "fehlt.toml"))
+	if err != nil || !reflect.DeepEqual(c, Default()) {
+		t.Errorf("Ohne Datei: %+v, %v", c, err)
+	}
+
+	// Fehlende Einträge behalten ihren Standardwert
+	c, err = Read(write(t, "quiet_hours = \"22:00-07:00\"\n[window]\nwidth = 1200\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.QuietHours != "22:00-07:00" || c.Window.Width != 1200 || c.Window.Height != Default().Window.Height ||
+		c.Reminders.AutoClose != Default().Reminders.AutoClose {
+		t.Errorf("Teilweise Datei: %+v", c)
+	}
+
+	if _, err := Read(write(t, "window = 3\n")); err == nil {
+		t.Error("Ungültige Datei ohne Fehler gelesen")
+	}
+	if _, err := Read(write(t, "quiet_hours = \"abends\"\n")); err == nil {
+		t.Error("Ungültige Ruhezeit ohne Fehler gelesen")
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	path := write(t, "db_path = \"/datei.db\"\nlocale = \"de\"\n[caldav]\npassword = \"aus-datei\"\n")
+	t.Setenv("REMINDER_DB", "/umgebung.db")
+	t.Setenv("REMINDER_LOCALE", "en_US")
+	t.Setenv("REMINDER_CALDAV_PASSWORD", "geheim")
+	t.Setenv("REMINDER_API_TOKEN", "token")
+	t.Setenv("REMINDER_SMTP_PASSWORD", "smtp")
+	t.Setenv("REMINDER_NTFY_TOKEN", "ntfy")
+	t.Setenv("REMINDER_GOTIFY_TOKEN", "gotify")
+	t.Setenv("REMINDER_MATRIX_TOKEN", "matrix")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{c.DBPath, c.Locale, c.CalDAV.Password, c.API.Token, c.Mail.Password,
+		c.Push.Ntfy.Token, c.Push.Gotify.Token, c.Push.Matrix.Token}
+	want := []string{"/umgebung.db", "en_US", "geheim", "token", "smtp", "ntfy", "gotify", "matrix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load: %v, erwartet %v", got, want)
+	}
+
+	// Read liest nur die Datei, z.B. zum Zurückschreiben
+	if c, err := Read(path); err != nil || c.DBPath != "/datei.db" || c.CalDAV.Password != "aus-datei" {
+		t.Errorf("Read: %+v, %v", c, err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	path := write(t, "db_path = \"/datei.db\"\n")
+	t.Setenv("REMINDER_CONFIG", path)
+	t.Setenv("REMINDER_DB", "/umgebung.db")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "/umgebung.db"},
+		{[]string{"-db", "/flag.db"}, "/flag.db"},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := RegisterFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if flags.Path() != path {
+			t.Errorf("-config ohne Angabe: %s, erwartet $REMINDER_CONFIG", flags.Path())
+		}
+		c, err := flags.Load()
+		if err != nil || c.DBPath != tt.want {
+			t.Errorf("%v: Datenbank %s, %v, erwartet %s", tt.args, c.DBPath, err, tt.want)
+		}
+	}
+
+	// -db gilt auch, wenn die Datei fehlerhaft ist
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"-config", write(t, "[window]\nwidth = 0\n"), "-db", "/flag.db"}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := flags.Load(); err == nil || c.DBPath != "/flag.db" {
+		t.Errorf("Fehlerhafte Datei: %s, %v", c.DBPath, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string
+	}{
+		{"Standard", func(c *Config) {}, ""},
+		{"Ruhezeit", func(c *Config) { c.QuietHours = "22:00" }, "Ruhezeit"},
+		{"Vorwarnung", func(c *Config) { c.Reminders.Alarms = []int{5, 0} }, "Vorwarnung"},
+		{"auto_close", func(c *Config) { c.Reminders.AutoClose = 0 }, "auto_close"},
+		{"Fenstergröße", func(c *Config) { c.Window.Height = -1 }, "Fenstergröße"},
+		{"Farbschema", func(c *Config) { c.Window.Theme = "bunt" }, "Farbschema"},
+		{"Abgleich", func(c *Config) { c.CalDAV.Interval = 0 }, "caldav.interval"},
+		{"Konfliktregel", func(c *Config) { c.CalDAV.Conflict = "egal" }, "Konfliktregel"},
+		{"Hook ohne Ziel", func(c *Config) { c.Hooks = []Hook{{}} }, "hooks[0]"},
+		{"Hook mit beidem", func(c *Config) {
+			c.Hooks = []Hook{{URL: "https://example.com", Command: []string{"true"}}}
+		}, "Entweder url oder command"},
+		{"Webhook", func(c *Config) { c.Hooks = []Hook{{URL: "ftp://example.com"}} }, "Webhooks"},
+		{"Hook", func(c *Config) { c.Hooks = []Hook{{Command: []string{"true"}, Timeout: 5}} }, ""},
+		{"mail.security", func(c *Config) { c.Mail.Security = "ssl" }, "mail.security"},
+		{"mail.to", func(c *Config) { c.Mail.Host = "smtp.example.com" }, "Empfänger"},
+		{"Empfänger", func(c *Config) {
+			c.Mail.Host, c.Mail.To = "smtp.example.com", []string{"kein Empfänger"}
+		}, "E-Mail-Adresse"},
+		{"ntfy", func(c *Config) { c.Push.Ntfy.URL = "ntfy.sh" }, "push.ntfy.url"},
+		{"api.listen", func(c *Config) { c.API.Listen = "0.0.0.0:8080" }, "lokale Adresse"},
+		{"api.listen lokal", func(c *Config) { c.API.Listen = "127.0.0.1:8080" }, ""},
+		{"api.listen Socket", func(c *Config) { c.API.Listen = "unix:/tmp/reminderd.sock" }, ""},
+	}
+	for _, tt := range tests {
+		c := Default()
+		tt.change(&c)
+		err := c.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Fehler %v, erwartet %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminder-app", "config.toml")
+	c := Default()
+	c.QuietHours = "22:00-07:00"
+	c.Window.Theme = ThemeDark
+	if err := Save(path, c); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Fatalf("Ohne Geheimnisse: %v, %v", info.Mode(), err)
+	}
+	if got, err := Read(path); err != nil || !reflect.DeepEqual(got, c) {
+		t.Errorf("Zurückgelesen: %+v, %v", got, err)
+	}
+
+	// Mit Passwort, Token oder Schlüssel nur für den Benutzer lesbar, auch
+	// wenn die Datei schon bestand
+	secrets := map[string]func(c *Config){
+		"caldav.password": func(c *Config) { c.CalDAV.Password = "geheim" },
+		"api.token":       func(c *Config) { c.API.Token = "geheim" },
+		"mail.password":   func(c *Config) { c.Mail.Password = "geheim" },
+		"push.ntfy.token": func(c *Config) { c.Push.Ntfy.Token = "geheim" },
+		"hooks.secret": func(c *Config) {
+			c.Hooks = []Hook{{URL: "https://example.com/hook", Secret: "geheim"}}
+		},
+	}
+	for name, set := range secrets {
+		if err := os.Chmod(path, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		withSecret := c
+		set(&withSecret)
+		if err := Save(path, withSecret); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("%s: Rechte %v, %v", name, info.Mode().Perm(), err)
+		}
+	}
+
+	// Ungültige Einstellungen werden nicht geschrieben
+	c.Window.Width = 0
+	if err := Save(path, c); err == nil {
+		t.Error("Ungültige Konfiguration gespeichert")
+	}
+}
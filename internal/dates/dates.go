@@ -0,0 +1,29 @@
+package dates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConvertToGermanDate konvertiert von YYYY-MM-DD zu DD.MM.YYYY
+func ConvertToGermanDate(isoDate string) string {
+	if len(isoDate) != 10 {
+		return isoDate
+	}
+	parts := strings.Split(isoDate, "-")
+	if len(parts) != 3 {
+		return isoDate
+	}
+	return fmt.Sprintf("%s.%s.%s", parts[2], parts[1], parts[0])
+}
+
+// ConvertToISODate konvertiert von DD.MM.YYYY (auch D.M.YYYY) zu YYYY-MM-DD.
+// Andere Eingaben, z.B. schon ISO-Daten, bleiben unverändert.
+func ConvertToISODate(germanDate string) string {
+	parts := strings.Split(strings.TrimSpace(germanDate), ".")
+	if len(parts) != 3 || len(parts[2]) != 4 ||
+		len(parts[0]) < 1 || len(parts[0]) > 2 || len(parts[1]) < 1 || len(parts[1]) > 2 {
+		return germanDate
+	}
+	return fmt.Sprintf("%s-%02s-%02s", parts[2], parts[1], parts[0])
+}
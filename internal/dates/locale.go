@@ -0,0 +1,61 @@
+package dates
+
+import (
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+)
+
+// Layouts für die Anzeige je Sprache: Datum, Eingabe eines Datums (auch
+// ohne führende Nullen), Tag ohne Jahr und Uhrzeit
+type layout struct {
+	date, input, day, clock string
+}
+
+var layouts = map[string]layout{
+	"de": {date: "02.01.2006", input: "2.1.2006", day: "02.01.", clock: "15:04"},
+	"en": {date: "01/02/2006", input: "1/2/2006", day: "Jan 2", clock: "3:04 PM"},
+}
+
+func current() layout {
+	if l, ok := layouts[i18n.Language()]; ok {
+		return l
+	}
+	return layouts["de"]
+}
+
+// Format zeigt ein Datum (YYYY-MM-DD) in der eingestellten Sprache an, z.B.
+// „14.03.2025“ oder „03/14/2025“. Andere Eingaben bleiben unverändert.
+func Format(isoDate string) string {
+	t, err := time.Parse("2006-01-02", isoDate)
+	if err != nil {
+		return isoDate
+	}
+	return t.Format(current().date)
+}
+
+// Parse ist die Umkehrung von Format und nimmt auch YYYY-MM-DD an. Andere
+// Eingaben bleiben unverändert.
+func Parse(s string) string {
+	for _, l := range []string{current().input, "2006-01-02"} {
+		if t, err := time.Parse(l, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return s
+}
+
+// FormatDay zeigt Tag und Monat ohne Jahr an, z.B. „14.03.“ oder „Mar 14“
+func FormatDay(t time.Time) string {
+	return t.Format(current().day)
+}
+
+// FormatTime zeigt eine Uhrzeit (HH:MM) in der eingestellten Sprache an, z.B.
+// „14:30“ oder „2:30 PM“. Andere Eingaben bleiben unverändert.
+func FormatTime(hhmm string) string {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return hhmm
+	}
+	return t.Format(current().clock)
+}
@@ -0,0 +1,566 @@
+package davsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/ics"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	"github.com/emersion/go-webdav/caldav"
+)
+
+// Höchstdauer einer einzelnen Anfrage an den Server
+const requestTimeout = 30 * time.Second
+
+// Objekte, die mit einer Anfrage geholt werden
+const multiGetBatch = 100
+
+// ErrPrecondition meldet, dass ein Objekt seit dem letzten Abgleich auf dem
+// Server geändert wurde (ETag passt nicht mehr)
+var ErrPrecondition = errors.New("Eintrag wurde inzwischen auf dem Server geändert")
+
+// Eigenschaften, die hier gepflegt werden. Alle anderen (Ort, Teilnehmer,
+// Zeitzonen, …) bleiben beim Zurückschreiben so, wie sie auf dem Server stehen.
+var managedProps = []string{
+	ical.PropUID, ical.PropDateTimeStamp, ical.PropLastModified, ical.PropSummary, ical.PropDescription,
+	ical.PropCategories, ical.PropPriority, ical.PropDateTimeStart, ical.PropDateTimeEnd,
+	ical.PropDuration, ical.PropDue, ical.PropStatus, ical.PropCompleted,
+}
+
+// Result fasst einen Abgleich zusammen
+type Result struct {
+	Pulled    int // vom Server übernommen
+	Pushed    int // auf den Server geschrieben
+	Deleted   int // hier oder dort gelöscht
+	Conflicts int // hier und dort geändert, nach der Konfliktregel aufgelöst
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%d vom Server übernommen, %d übertragen, %d gelöscht, %d Konflikte",
+		r.Pulled, r.Pushed, r.Deleted, r.Conflicts)
+}
+
+// Syncer gleicht Termine und Aufgaben mit einem CalDAV-Server ab
+type Syncer struct {
+	store  *store.Store
+	cfg    config.CalDAV
+	http   webdav.HTTPClient
+	client *caldav.Client
+	base   *url.URL
+	result Result
+}
+
+// item ist ein Termin oder eine Aufgabe mit Abgleichstand
+type item struct {
+	table  string
+	id     int64
+	uid    string
+	title  string
+	sync   store.SyncState
+	encode func(uid string) (*ical.Component, error)
+}
+
+// New bereitet den Abgleich vor; der Server wird erst mit Sync angefragt
+func New(s *store.Store, cfg config.CalDAV) (*Syncer, error) {
+	base, err := url.Parse(cfg.URL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("Ungültige CalDAV-URL: %s", cfg.URL)
+	}
+	var hc webdav.HTTPClient = &http.Client{Timeout: requestTimeout}
+	if cfg.Username != "" {
+		hc = webdav.HTTPClientWithBasicAuth(hc, cfg.Username, cfg.Password)
+	}
+	client, err := caldav.NewClient(hc, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &Syncer{store: s, cfg: cfg, http: hc, client: client, base: base}, nil
+}
+
+// Sync gleicht beide Richtungen ab: zuerst Änderungen und Löschungen je
+// Objekt anhand der ETags, dann werden neue Einträge übertragen. Bricht
+// die Verbindung ab, bleiben nicht übertragene Änderungen vorgemerkt.
+func (y *Syncer) Sync(ctx context.Context) (Result, error) {
+	y.result = Result{}
+	events, tasks, err := y.calendars(ctx)
+	if err != nil {
+		return y.result, err
+	}
+
+	objects, err := y.store.RemoteObjects()
+	if err != nil {
+		return y.result, err
+	}
+	local, fresh, err := y.items()
+	if err != nil {
+		return y.result, err
+	}
+
+	collections := []string{events}
+	if tasks != events {
+		collections = append(collections, tasks)
+	}
+	for _, c := range collections {
+		if err := y.syncCollection(ctx, c, objects, local); err != nil {
+			return y.result, err
+		}
+	}
+
+	for _, it := range fresh {
+		collection := events
+		if it.table == store.TableTasks {
+			collection = tasks
+		}
+		if err := y.pushNew(ctx, collection, it); err != nil {
+			return y.result, err
+		}
+	}
+	return y.result, nil
+}
+
+// items liefert die eigenen Einträge nach href und die noch nie übertragenen
+func (y *Syncer) items() (map[string][]item, []item, error) {
+	appointments, err := y.store.QueryAppointments(store.Filter{OwnOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := y.store.QueryTasks(store.Filter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var all []item
+	for _, a := range appointments {
+		a := a
+		all = append(all, item{
+			table: store.TableAppointments, id: a.ID, uid: a.UID, title: a.Title, sync: a.Sync,
+			encode: func(uid string) (*ical.Component, error) {
+				a.UID = uid
+				return ics.EncodeAppointment(a, time.Now())
+			},
+		})
+	}
+	for _, t := range tasks {
+		t := t
+		all = append(all, item{
+			table: store.TableTasks, id: t.ID, uid: t.UID, title: t.Title, sync: t.Sync,
+			encode: func(uid string) (*ical.Component, error) {
+				t.UID = uid
+				return ics.EncodeTask(t, time.Now()), nil
+			},
+		})
+	}
+
+	local := make(map[string][]item)
+	var fresh []item
+	for _, it := range all {
+		if it.sync.Href == "" {
+			fresh = append(fresh, it)
+		} else {
+			local[it.sync.Href] = append(local[it.sync.Href], it)
+		}
+	}
+	return local, fresh, nil
+}
+
+func dirty(items []item) bool {
+	return slices.ContainsFunc(items, func(it item) bool { return it.sync.Dirty })
+}
+
+// calendars ermittelt die Kalender für Termine und Aufgaben, falls sie
+// nicht eingetragen sind, über Principal und Calendar-Home-Set
+func (y *Syncer) calendars(ctx context.Context) (events, tasks string, err error) {
+	events, tasks = y.path(y.cfg.Calendar), y.path(y.cfg.TasksCalendar)
+	if events != "" && tasks != "" {
+		return events, tasks, nil
+	}
+
+	principal, err := y.client.FindCurrentUserPrincipal(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("Fehler bei der Anmeldung am CalDAV-Server: %v", err)
+	}
+	home, err := y.client.FindCalendarHomeSet(ctx, principal)
+	if err != nil {
+		return "", "", fmt.Errorf("Fehler beim Suchen der Kalender: %v", err)
+	}
+	cals, err := y.client.FindCalendars(ctx, home)
+	if err != nil {
+		return "", "", fmt.Errorf("Fehler beim Suchen der Kalender: %v", err)
+	}
+
+	supports := func(c caldav.Calendar, comp string) bool {
+		return len(c.SupportedComponentSet) == 0 || slices.Contains(c.SupportedComponentSet, comp)
+	}
+	for _, c := range cals {
+		if events == "" && supports(c, ical.CompEvent) {
+			events = y.path(c.Path)
+		}
+		if tasks == "" && supports(c, ical.CompToDo) {
+			tasks = y.path(c.Path)
+		}
+	}
+	if events == "" {
+		return "", "", fmt.Errorf("Kein Kalender für Termine auf dem Server gefunden")
+	}
+	if tasks == "" {
+		tasks = events
+	}
+	return events, tasks, nil
+}
+
+// path wandelt einen Kalender (Pfad oder URL) in einen Pfad mit "/" am Ende um
+func (y *Syncer) path(p string) string {
+	if p == "" {
+		return ""
+	}
+	u, err := url.Parse(p)
+	if err != nil {
+		return p
+	}
+	p = y.base.ResolveReference(u).Path
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
+
+func (y *Syncer) syncCollection(ctx context.Context, collection string, objects map[string]store.RemoteObject, local map[string][]item) error {
+	infos, err := y.client.ReadDir(ctx, collection, false)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen von %s: %v", collection, err)
+	}
+	remote := make(map[string]string)
+	for _, fi := range infos {
+		if !fi.IsDir {
+			remote[fi.Path] = fi.ETag
+		}
+	}
+
+	today := time.Now().Format("2006-01-02")
+	var fetch []string
+	for href, etag := range remote {
+		o, known := objects[href]
+		if !known || o.ETag != etag {
+			fetch = append(fetch, href)
+			continue
+		}
+		items := local[href]
+		var err error
+		switch {
+		case o.Series:
+			// Serien werden hier nicht geändert; neu auflösen, damit
+			// geänderte Wiederholungen verworfen werden und das Jahr mitwandert
+			if dirty(items) {
+				log.Printf("CalDAV: Änderungen an einzelnen Wiederholungen von %s werden nicht übertragen", href)
+			}
+			if dirty(items) || o.Applied != today {
+				err = y.apply(o, ics.DecodeCalendar(decode(o.Data)), false)
+			}
+		case len(items) == 0:
+			err = y.deleteRemote(ctx, o)
+		case dirty(items):
+			err = y.push(ctx, items[0], o)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	slices.Sort(fetch)
+	for len(fetch) > 0 {
+		n := min(len(fetch), multiGetBatch)
+		cos, err := y.client.MultiGetCalendar(ctx, collection, &caldav.CalendarMultiGet{
+			Paths:       fetch[:n],
+			CompRequest: caldav.CalendarCompRequest{Name: ical.CompCalendar, AllProps: true, AllComps: true},
+		})
+		if err != nil {
+			return fmt.Errorf("Fehler beim Abrufen der Einträge: %v", err)
+		}
+		fetch = fetch[n:]
+		for _, co := range cos {
+			prev, known := objects[co.Path]
+			if err := y.pulled(ctx, co, prev, known, local[co.Path]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Auf dem Server gelöscht
+	for href, o := range objects {
+		if _, ok := remote[href]; ok || !strings.HasPrefix(href, collection) {
+			continue
+		}
+		items := local[href]
+		if !o.Series && dirty(items) && y.cfg.Conflict != config.ConflictServer {
+			y.result.Conflicts++
+			o.ETag = "" // neu anlegen
+			if err := y.push(ctx, items[0], o); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := y.store.DeleteRemoteObject(href); err != nil {
+			return err
+		}
+		y.result.Deleted++
+	}
+	return nil
+}
+
+// pulled verarbeitet ein geändertes oder neues Objekt vom Server
+func (y *Syncer) pulled(ctx context.Context, co caldav.CalendarObject, prev store.RemoteObject, known bool, items []item) error {
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(co.Data); err != nil {
+		return err
+	}
+	o := store.RemoteObject{Href: co.Path, ETag: co.ETag, Data: buf.String()}
+	data := ics.DecodeCalendar(co.Data)
+	o.Series = recurring(co.Data) || len(data.Appointments)+len(data.Tasks) != 1
+
+	switch {
+	case known && !prev.Series && len(items) == 0:
+		// Hier gelöscht, auf dem Server geändert
+		y.result.Conflicts++
+		if y.cfg.Conflict == config.ConflictLocal {
+			return y.deleteRemote(ctx, o)
+		}
+	case known && !o.Series && dirty(items):
+		// Hier und auf dem Server geändert
+		y.result.Conflicts++
+		if y.localWins(items[0], co) {
+			return y.push(ctx, items[0], o)
+		}
+	}
+	return y.apply(o, data, true)
+}
+
+// apply übernimmt den Stand des Servers
+func (y *Syncer) apply(o store.RemoteObject, data *ics.Data, count bool) error {
+	for _, e := range data.Errors {
+		log.Printf("CalDAV: %s: %v", o.Href, e)
+	}
+	o.Applied = time.Now().Format("2006-01-02")
+
+	var err error
+	if len(data.Appointments) == 0 && len(data.Tasks) > 0 {
+		err = y.store.ApplyRemoteTask(o, data.Tasks[0])
+	} else {
+		err = y.store.ApplyRemoteAppointments(o, data.Appointments)
+	}
+	if err == nil && count {
+		y.result.Pulled++
+	}
+	return err
+}
+
+func (y *Syncer) localWins(it item, co caldav.CalendarObject) bool {
+	switch y.cfg.Conflict {
+	case config.ConflictLocal:
+		return true
+	case config.ConflictNewest:
+		return it.sync.Modified.After(remoteModified(co))
+	}
+	return false
+}
+
+// remoteModified ist der Zeitpunkt der letzten Änderung auf dem Server
+func remoteModified(co caldav.CalendarObject) time.Time {
+	for _, child := range co.Data.Children {
+		if child.Name != ical.CompEvent && child.Name != ical.CompToDo {
+			continue
+		}
+		for _, name := range []string{ical.PropLastModified, ical.PropDateTimeStamp} {
+			if t, err := child.Props.DateTime(name, time.UTC); err == nil && !t.IsZero() {
+				return t
+			}
+		}
+	}
+	return co.ModTime
+}
+
+func recurring(cal *ical.Calendar) bool {
+	for _, child := range cal.Children {
+		for _, name := range []string{ical.PropRecurrenceRule, ical.PropRecurrenceDates, ical.PropRecurrenceID} {
+			if child.Props.Get(name) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decode(data string) *ical.Calendar {
+	cal, err := ical.NewDecoder(strings.NewReader(data)).Decode()
+	if err != nil {
+		return ics.NewCalendar()
+	}
+	return cal
+}
+
+// pushNew legt einen hier erstellten Eintrag auf dem Server an
+func (y *Syncer) pushNew(ctx context.Context, collection string, it item) error {
+	if it.uid == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		it.uid = hex.EncodeToString(b) + "@reminder-erinnerungs-app"
+	}
+	href := collection + url.PathEscape(strings.ReplaceAll(it.uid, "/", "_")) + ".ics"
+	return y.push(ctx, it, store.RemoteObject{Href: href})
+}
+
+// push schreibt einen Eintrag auf den Server. Mit ETag nur, wenn das Objekt
+// dort unverändert ist, ohne ETag nur, wenn es noch nicht existiert.
+func (y *Syncer) push(ctx context.Context, it item, o store.RemoteObject) error {
+	comp, err := it.encode(it.uid)
+	if err != nil {
+		log.Printf("CalDAV: \"%s\" nicht übertragen: %v", it.title, err)
+		return nil
+	}
+	comp.Props.SetDateTime(ical.PropLastModified, time.Now().UTC())
+	cal := merge(o.Data, comp)
+
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		return err
+	}
+	etag, err := y.put(ctx, o.Href, buf.Bytes(), o.ETag)
+	if errors.Is(err, ErrPrecondition) {
+		// Der neue Stand wird beim nächsten Abgleich geholt und der Konflikt dann aufgelöst
+		log.Printf("CalDAV: \"%s\": %v", it.title, err)
+		y.result.Conflicts++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	o.ETag, o.Data, o.Series = etag, buf.String(), false
+	o.Applied = time.Now().Format("2006-01-02")
+	if err := y.store.MarkSynced(it.table, it.id, it.uid, it.sync.Modified, o); err != nil {
+		return err
+	}
+	y.result.Pushed++
+	return nil
+}
+
+// merge überträgt die hier gepflegten Eigenschaften in den Kalender vom
+// Server, damit dort gesetzte weitere Angaben erhalten bleiben
+func merge(data string, comp *ical.Component) *ical.Calendar {
+	if data != "" {
+		cal := decode(data)
+		for _, child := range cal.Children {
+			if child.Name != comp.Name || child.Props.Get(ical.PropRecurrenceID) != nil {
+				continue
+			}
+			for _, name := range managedProps {
+				delete(child.Props, name)
+			}
+			for name, props := range comp.Props {
+				child.Props[name] = props
+			}
+			if comp.Name == ical.CompEvent {
+				children := slices.DeleteFunc(child.Children, func(c *ical.Component) bool {
+					return c.Name == ical.CompAlarm
+				})
+				child.Children = append(children, comp.Children...)
+			}
+			return cal
+		}
+	}
+	cal := ics.NewCalendar()
+	cal.Children = append(cal.Children, comp)
+	return cal
+}
+
+func (y *Syncer) url(href string) string {
+	return y.base.ResolveReference(&url.URL{Path: href}).String()
+}
+
+// put schreibt ein Objekt und liefert dessen neues ETag
+func (y *Syncer) put(ctx context.Context, href string, body []byte, etag string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, y.url(href), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", ical.MIMEType)
+	if etag != "" {
+		req.Header.Set("If-Match", strconv.Quote(etag))
+	} else {
+		req.Header.Set("If-None-Match", "*")
+	}
+
+	resp, err := y.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Fehler beim Schreiben von %s: %v", href, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusPreconditionFailed:
+		return "", ErrPrecondition
+	default:
+		return "", fmt.Errorf("Fehler beim Schreiben von %s: %s", href, resp.Status)
+	}
+
+	if e := resp.Header.Get("ETag"); e != "" {
+		if unquoted, err := strconv.Unquote(e); err == nil {
+			return unquoted, nil
+		}
+		return e, nil
+	}
+	// Manche Server liefern das ETag nicht mit
+	fi, err := y.client.Stat(ctx, href)
+	if err != nil {
+		return "", fmt.Errorf("Fehler beim Abrufen von %s: %v", href, err)
+	}
+	return fi.ETag, nil
+}
+
+// deleteRemote löscht ein hier gelöschtes Objekt auf dem Server, sofern es
+// dort seit dem letzten Abgleich unverändert ist
+func (y *Syncer) deleteRemote(ctx context.Context, o store.RemoteObject) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, y.url(o.Href), nil)
+	if err != nil {
+		return err
+	}
+	if o.ETag != "" {
+		req.Header.Set("If-Match", strconv.Quote(o.ETag))
+	}
+	resp, err := y.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Löschen von %s: %v", o.Href, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+	case http.StatusPreconditionFailed:
+		// Auf dem Server geändert: beim nächsten Abgleich als Konflikt behandeln
+		log.Printf("CalDAV: %s: %v", o.Href, ErrPrecondition)
+		return nil
+	default:
+		return fmt.Errorf("Fehler beim Löschen von %s: %s", o.Href, resp.Status)
+	}
+
+	if err := y.store.DeleteRemoteObject(o.Href); err != nil {
+		return err
+	}
+	y.result.Deleted++
+	return nil
+}
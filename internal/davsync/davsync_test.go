@@ -0,0 +1,465 @@
+package davsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	"github.com/emersion/go-webdav/caldav"
+)
+
+const calendarPath = "/dav/calendars/default/"
+
+// server ist ein CalDAV-Server im Speicher mit einem Kalender für Termine
+// und Aufgaben. Er prüft If-Match und If-None-Match wie ein echter Server.
+type server struct {
+	mu      sync.Mutex
+	objects map[string]object
+	etag    int
+}
+
+type object struct {
+	etag     string
+	data     string
+	modified time.Time
+}
+
+func newServer(t *testing.T) (*server, *httptest.Server) {
+	b := &server{objects: make(map[string]object)}
+	srv := httptest.NewServer(b.handler())
+	t.Cleanup(srv.Close)
+	return b, srv
+}
+
+// handler prüft If-Match beim Löschen, was caldav.Handler dem Backend nicht weitergibt
+func (b *server) handler() http.Handler {
+	h := &caldav.Handler{Backend: b}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			if err := b.precondition(r.URL.Path, webdav.ConditionalMatch(r.Header.Get("If-Match")), ""); err != nil {
+				http.Error(w, err.Error(), http.StatusPreconditionFailed)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (b *server) precondition(path string, ifMatch, ifNoneMatch webdav.ConditionalMatch) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, exists := b.objects[path]
+	if ifNoneMatch.IsWildcard() && exists {
+		return errors.New("Objekt existiert bereits")
+	}
+	if ifMatch.IsSet() {
+		etag, err := ifMatch.ETag()
+		if err != nil || !exists || etag != o.etag {
+			return errors.New("ETag passt nicht")
+		}
+	}
+	return nil
+}
+
+func (b *server) save(path, data string) object {
+	b.etag++
+	o := object{etag: strconv.Itoa(b.etag), data: data, modified: time.Now()}
+	b.objects[path] = o
+	return o
+}
+
+func (b *server) calendarObject(path string, o object) (caldav.CalendarObject, error) {
+	cal, err := ical.NewDecoder(strings.NewReader(o.data)).Decode()
+	if err != nil {
+		return caldav.CalendarObject{}, err
+	}
+	return caldav.CalendarObject{Path: path, ModTime: o.modified, ContentLength: int64(len(o.data)), ETag: o.etag, Data: cal}, nil
+}
+
+func (b *server) CurrentUserPrincipal(ctx context.Context) (string, error) {
+	return "/dav/", nil
+}
+
+func (b *server) CalendarHomeSetPath(ctx context.Context) (string, error) {
+	return "/dav/calendars/", nil
+}
+
+func (b *server) CreateCalendar(ctx context.Context, calendar *caldav.Calendar) error {
+	return webdav.NewHTTPError(http.StatusForbidden, nil)
+}
+
+func (b *server) ListCalendars(ctx context.Context) ([]caldav.Calendar, error) {
+	return []caldav.Calendar{{Path: calendarPath, Name: "Test"}}, nil
+}
+
+func (b *server) GetCalendar(ctx context.Context, path string) (*caldav.Calendar, error) {
+	if path != calendarPath {
+		return nil, webdav.NewHTTPError(http.StatusNotFound, nil)
+	}
+	return &caldav.Calendar{Path: calendarPath, Name: "Test"}, nil
+}
+
+func (b *server) GetCalendarObject(ctx context.Context, path string, req *caldav.CalendarCompRequest) (*caldav.CalendarObject, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.objects[path]
+	if !ok {
+		return nil, webdav.NewHTTPError(http.StatusNotFound, nil)
+	}
+	co, err := b.calendarObject(path, o)
+	return &co, err
+}
+
+func (b *server) ListCalendarObjects(ctx context.Context, path string, req *caldav.CalendarCompRequest) ([]caldav.CalendarObject, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []caldav.CalendarObject
+	for p, o := range b.objects {
+		if !strings.HasPrefix(p, path) {
+			continue
+		}
+		co, err := b.calendarObject(p, o)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, co)
+	}
+	return out, nil
+}
+
+func (b *server) QueryCalendarObjects(ctx context.Context, path string, query *caldav.CalendarQuery) ([]caldav.CalendarObject, error) {
+	return b.ListCalendarObjects(ctx, path, nil)
+}
+
+func (b *server) PutCalendarObject(ctx context.Context, path string, cal *ical.Calendar, opts *caldav.PutCalendarObjectOptions) (*caldav.CalendarObject, error) {
+	if err := b.precondition(path, opts.IfMatch, opts.IfNoneMatch); err != nil {
+		return nil, webdav.NewHTTPError(http.StatusPreconditionFailed, err)
+	}
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o := b.save(path, buf.String())
+	return &caldav.CalendarObject{Path: path, ModTime: o.modified, ETag: o.etag}, nil
+}
+
+func (b *server) DeleteCalendarObject(ctx context.Context, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.objects[path]; !ok {
+		return webdav.NewHTTPError(http.StatusNotFound, nil)
+	}
+	delete(b.objects, path)
+	return nil
+}
+
+// edit ändert den Titel eines Objekts wie ein anderer Client
+func (b *server) edit(t *testing.T, path, summary string, modified time.Time) {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cal, err := ical.NewDecoder(strings.NewReader(b.objects[path].data)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range cal.Children {
+		child.Props.SetText(ical.PropSummary, summary)
+		child.Props.SetDateTime(ical.PropLastModified, modified.UTC())
+	}
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+	b.save(path, buf.String())
+}
+
+func (b *server) remove(path string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, path)
+}
+
+// summaries liefert die Titel aller Objekte auf dem Server
+func (b *server) summaries(t *testing.T) []string {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, o := range b.objects {
+		cal, err := ical.NewDecoder(strings.NewReader(o.data)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, child := range cal.Children {
+			if s, err := child.Props.Text(ical.PropSummary); err == nil {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func openStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func syncer(t *testing.T, s *store.Store, srv *httptest.Server, conflict string) *Syncer {
+	t.Helper()
+	y, err := New(s, config.CalDAV{URL: srv.URL + "/dav/", Calendar: calendarPath, TasksCalendar: calendarPath, Conflict: conflict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return y
+}
+
+func run(t *testing.T, y *Syncer, want Result) {
+	t.Helper()
+	got, err := y.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Ergebnis %v, erwartet %v", got, want)
+	}
+}
+
+// pushed legt einen Termin an, überträgt ihn und liefert ihn mit href
+func pushed(t *testing.T, s *store.Store, y *Syncer) store.Appointment {
+	t.Helper()
+	a := store.Appointment{Title: "Zahnarzt", Date: "2030-05-02", Time: "10:00"}
+	if err := s.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	run(t, y, Result{Pushed: 1})
+	a, err := s.GetAppointment(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Sync.Href == "" || a.Sync.Dirty {
+		t.Fatalf("Abgleichstand nach dem Übertragen: %+v", a.Sync)
+	}
+	return a
+}
+
+func rename(t *testing.T, s *store.Store, a store.Appointment, title string) {
+	t.Helper()
+	a.Title = title
+	if err := s.UpdateAppointment(a); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appointmentTitles(t *testing.T, s *store.Store) []string {
+	t.Helper()
+	appointments, err := s.QueryAppointments(store.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, a := range appointments {
+		out = append(out, a.Title)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	return strings.Join(a, "|") == strings.Join(b, "|")
+}
+
+func TestSync(t *testing.T) {
+	b, srv := newServer(t)
+	s := openStore(t)
+	y := syncer(t, s, srv, config.ConflictServer)
+
+	a := pushed(t, s, y)
+	task := store.Task{Title: "Einkaufen"}
+	if err := s.AddTask(&task); err != nil {
+		t.Fatal(err)
+	}
+	run(t, y, Result{Pushed: 1})
+	if n := len(b.summaries(t)); n != 2 {
+		t.Fatalf("%d Objekte auf dem Server, erwartet 2", n)
+	}
+	run(t, y, Result{})
+
+	// Änderungen dort werden übernommen, hier werden übertragen
+	b.edit(t, a.Sync.Href, "Zahnarzt Dr. Müller", time.Now())
+	run(t, y, Result{Pulled: 1})
+	if got := appointmentTitles(t, s); !equal(got, []string{"Zahnarzt Dr. Müller"}) {
+		t.Errorf("Termine nach Änderung auf dem Server: %v", got)
+	}
+	a, _ = s.GetAppointment(a.ID)
+	rename(t, s, a, "Kontrolle")
+	run(t, y, Result{Pushed: 1})
+	if got := b.summaries(t); !strings.Contains(strings.Join(got, "|"), "Kontrolle") {
+		t.Errorf("Objekte nach Änderung hier: %v", got)
+	}
+
+	// Hier gelöscht wird auch dort gelöscht
+	if _, err := s.DeleteAppointment(a.ID); err != nil {
+		t.Fatal(err)
+	}
+	run(t, y, Result{Deleted: 1})
+	if got := b.summaries(t); !equal(got, []string{"Einkaufen"}) {
+		t.Errorf("Objekte nach Löschen hier: %v", got)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		conflict string
+		remote   time.Time // letzte Änderung auf dem Server
+		want     string
+	}{
+		{"Server", config.ConflictServer, time.Now().Add(-time.Hour), "dort"},
+		{"lokal", config.ConflictLocal, time.Now().Add(time.Hour), "hier"},
+		{"neuer dort", config.ConflictNewest, time.Now().Add(time.Hour), "dort"},
+		{"neuer hier", config.ConflictNewest, time.Now().Add(-time.Hour), "hier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, srv := newServer(t)
+			s := openStore(t)
+			y := syncer(t, s, srv, tt.conflict)
+			a := pushed(t, s, y)
+
+			rename(t, s, a, "hier")
+			b.edit(t, a.Sync.Href, "dort", tt.remote)
+			want := Result{Conflicts: 1, Pulled: 1}
+			if tt.want == "hier" {
+				want = Result{Conflicts: 1, Pushed: 1}
+			}
+			run(t, y, want)
+
+			if got := appointmentTitles(t, s); !equal(got, []string{tt.want}) {
+				t.Errorf("Termine: %v, erwartet %s", got, tt.want)
+			}
+			if got := b.summaries(t); !equal(got, []string{tt.want}) {
+				t.Errorf("Server: %v, erwartet %s", got, tt.want)
+			}
+			run(t, y, Result{})
+		})
+	}
+}
+
+func TestSyncDeletedHere(t *testing.T) {
+	// Hier gelöscht, auf dem Server geändert
+	for _, conflict := range []string{config.ConflictServer, config.ConflictLocal} {
+		t.Run(conflict, func(t *testing.T) {
+			b, srv := newServer(t)
+			s := openStore(t)
+			y := syncer(t, s, srv, conflict)
+			a := pushed(t, s, y)
+
+			if _, err := s.DeleteAppointment(a.ID); err != nil {
+				t.Fatal(err)
+			}
+			b.edit(t, a.Sync.Href, "dort", time.Now())
+			if conflict == config.ConflictLocal {
+				run(t, y, Result{Conflicts: 1, Deleted: 1})
+				if got := b.summaries(t); len(got) != 0 {
+					t.Errorf("Auf dem Server geblieben: %v", got)
+				}
+				return
+			}
+			run(t, y, Result{Conflicts: 1, Pulled: 1})
+			if got := appointmentTitles(t, s); !equal(got, []string{"dort"}) {
+				t.Errorf("Nicht wiederhergestellt: %v", got)
+			}
+		})
+	}
+}
+
+func TestSyncDeletedOnServer(t *testing.T) {
+	tests := []struct {
+		name     string
+		conflict string
+		dirty    bool
+		want     Result
+		kept     bool
+	}{
+		{"unverändert", config.ConflictLocal, false, Result{Deleted: 1}, false},
+		{"geändert, Server gewinnt", config.ConflictServer, true, Result{Deleted: 1}, false},
+		{"geändert, lokal gewinnt", config.ConflictLocal, true, Result{Conflicts: 1, Pushed: 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, srv := newServer(t)
+			s := openStore(t)
+			y := syncer(t, s, srv, tt.conflict)
+			a := pushed(t, s, y)
+
+			if tt.dirty {
+				rename(t, s, a, "hier")
+			}
+			b.remove(a.Sync.Href)
+			run(t, y, tt.want)
+
+			local, server := appointmentTitles(t, s), b.summaries(t)
+			if tt.kept && (!equal(local, []string{"hier"}) || !equal(server, []string{"hier"})) {
+				t.Errorf("Nicht neu angelegt: hier %v, Server %v", local, server)
+			}
+			if !tt.kept && (len(local) != 0 || len(server) != 0) {
+				t.Errorf("Nicht gelöscht: hier %v, Server %v", local, server)
+			}
+		})
+	}
+}
+
+func TestPrecondition(t *testing.T) {
+	b, srv := newServer(t)
+	s := openStore(t)
+	y := syncer(t, s, srv, config.ConflictServer)
+	a := pushed(t, s, y)
+	objects, err := s.RemoteObjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := objects[a.Sync.Href]
+	b.edit(t, o.Href, "dort", time.Now())
+
+	ctx := context.Background()
+	if _, err := y.put(ctx, o.Href, []byte(o.Data), o.ETag); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("Schreiben mit altem ETag: %v", err)
+	}
+	if _, err := y.put(ctx, o.Href, []byte(o.Data), ""); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("Neu anlegen über bestehendes Objekt: %v", err)
+	}
+
+	// Löschen mit altem ETag lässt das Objekt stehen und den Abgleichstand unverändert
+	if err := y.deleteRemote(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.summaries(t); !equal(got, []string{"dort"}) {
+		t.Errorf("Objekt trotz geändertem ETag gelöscht: %v", got)
+	}
+	if objects, _ := s.RemoteObjects(); objects[o.Href].ETag != o.ETag {
+		t.Errorf("Abgleichstand verändert: %+v", objects[o.Href])
+	}
+
+	// Der Konflikt wird beim nächsten Abgleich nach der Konfliktregel aufgelöst
+	rename(t, s, a, "hier")
+	run(t, y, Result{Conflicts: 1, Pulled: 1})
+}
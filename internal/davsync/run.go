@@ -0,0 +1,155 @@
+package davsync
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Einstellungen mit dem Stand des Abgleichs, für GUI und reminderctl
+const (
+	SettingLastSync = "caldav.last_sync"
+	SettingError    = "caldav.error"
+	SettingAccount  = "caldav.account"
+)
+
+// Wartezeit nach einer Änderung hier, damit mehrere Änderungen gemeinsam
+// übertragen werden
+const changeDelay = 10 * time.Second
+
+// Status ist der Stand des Abgleichs
+type Status struct {
+	LastSync time.Time // letzter erfolgreicher Abgleich
+	Error    string    // Fehler beim letzten Versuch
+	Pending  int       // noch nicht übertragene Änderungen
+}
+
+// ReadStatus liest den Stand des Abgleichs aus der Datenbank
+func ReadStatus(s *store.Store) (Status, error) {
+	var st Status
+	last, err := s.Setting(SettingLastSync, "")
+	if err != nil {
+		return st, err
+	}
+	st.LastSync, _ = time.Parse(time.RFC3339, last)
+	if st.Error, err = s.Setting(SettingError, ""); err != nil {
+		return st, err
+	}
+	st.Pending, err = s.PendingSync()
+	return st, err
+}
+
+func (st Status) String() string {
+	switch {
+	case st.Error != "":
+		return "Fehler: " + st.Error
+	case st.LastSync.IsZero():
+		return "noch nicht abgeglichen"
+	case st.Pending > 0:
+		return fmt.Sprintf("%d Änderungen ausstehend", st.Pending)
+	}
+	return "abgeglichen " + st.LastSync.Local().Format("02.01. 15:04")
+}
+
+// Hält einen zweiten Abgleich im selben Prozess an, bis der erste fertig ist
+var syncMu sync.Mutex
+
+// SyncNow gleicht einmal ab und vermerkt das Ergebnis. Wurde ein anderer
+// Server oder Kalender eingetragen, wird zuvor der alte Abgleichstand
+// verworfen, damit alle Einträge neu übertragen werden.
+func SyncNow(ctx context.Context, s *store.Store, cfg config.CalDAV) (Result, error) {
+	if !cfg.Enabled() {
+		return Result{}, fmt.Errorf("Kein CalDAV-Server eingerichtet")
+	}
+	syncMu.Lock()
+	defer syncMu.Unlock()
+
+	account := fmt.Sprintf("%s|%s|%s|%s", cfg.URL, cfg.Username, cfg.Calendar, cfg.TasksCalendar)
+	previous, err := s.Setting(SettingAccount, "")
+	if err != nil {
+		return Result{}, err
+	}
+	if previous != account {
+		if previous != "" {
+			log.Printf("CalDAV: anderer Server oder Kalender, alle Einträge werden neu abgeglichen")
+		}
+		if err := s.ResetSync(); err != nil {
+			return Result{}, err
+		}
+		if err := s.SetSetting(SettingAccount, account); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var result Result
+	y, err := New(s, cfg)
+	if err == nil {
+		result, err = y.Sync(ctx)
+	}
+	message := ""
+	if err != nil {
+		message = err.Error()
+	} else if err := s.SetSetting(SettingLastSync, time.Now().Format(time.RFC3339)); err != nil {
+		log.Print(err)
+	}
+	if err := s.SetSetting(SettingError, message); err != nil {
+		log.Print(err)
+	}
+	return result, err
+}
+
+// Run gleicht im Abstand aus der Konfiguration ab und zusätzlich kurz nach
+// Änderungen hier. Ist der Server nicht erreichbar, bleiben die Änderungen
+// vorgemerkt und werden beim nächsten erfolgreichen Abgleich übertragen.
+// cfg wird vor jedem Abgleich neu gelesen, damit geänderte Einstellungen
+// ohne Neustart gelten.
+func Run(ctx context.Context, s *store.Store, cfg func() config.CalDAV) {
+	changes, cancel := s.Subscribe()
+	defer cancel()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	next := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c, ok := <-changes:
+			if !ok {
+				return
+			}
+			if !c.Affects(store.TableAppointments) && !c.Affects(store.TableTasks) {
+				continue
+			}
+			if !cfg().Enabled() || time.Until(next) <= changeDelay {
+				continue
+			}
+			if n, err := s.PendingSync(); err == nil && n > 0 {
+				next = time.Now().Add(changeDelay)
+				timer.Reset(changeDelay)
+			}
+		case <-timer.C:
+			c := cfg()
+			interval := time.Duration(c.Interval) * time.Minute
+			if c.Enabled() {
+				result, err := SyncNow(ctx, s, c)
+				if err != nil {
+					log.Printf("CalDAV-Abgleich fehlgeschlagen: %v", err)
+				} else if result != (Result{}) {
+					log.Printf("CalDAV-Abgleich: %s", result)
+				}
+			} else {
+				// Auf eine spätere Einrichtung warten
+				interval = time.Minute
+			}
+			next = time.Now().Add(interval)
+			timer.Reset(interval)
+		}
+	}
+}
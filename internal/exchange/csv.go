@@ -0,0 +1,292 @@
+package exchange
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Felder eines Termins, denen beim CSV-Import eine Spalte zugeordnet wird
+const (
+	FieldTitle    = "title"
+	FieldDate     = "date"
+	FieldTime     = "time"
+	FieldPriority = "priority"
+	FieldTags     = "tags"
+)
+
+// Fields sind alle zuordenbaren Felder in der Reihenfolge der Anzeige
+var Fields = []string{FieldTitle, FieldDate, FieldTime, FieldPriority, FieldTags}
+
+// FieldLabels sind die Anzeigenamen der Felder
+var FieldLabels = map[string]string{
+	FieldTitle:    "Titel",
+	FieldDate:     "Datum",
+	FieldTime:     "Uhrzeit",
+	FieldPriority: "Priorität",
+	FieldTags:     "Tags",
+}
+
+// Übliche Spaltenüberschriften je Feld, für die automatische Zuordnung
+var headerNames = map[string][]string{
+	FieldTitle:    {"titel", "title", "betreff", "subject", "name"},
+	FieldDate:     {"datum", "date", "tag", "day", "startdatum", "start date"},
+	FieldTime:     {"uhrzeit", "zeit", "time", "beginn", "startzeit", "start time"},
+	FieldPriority: {"priorität", "prioritaet", "priority", "prio"},
+	FieldTags:     {"tags", "kategorien", "categories", "kategorie", "category"},
+}
+
+// Mapping ordnet jedem Feld eine Spalte zu (ab 0); fehlende Felder bleiben leer
+type Mapping map[string]int
+
+// Table ist eine eingelesene CSV-Datei
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// ReadCSV liest eine CSV-Datei mit Kopfzeile. Das Trennzeichen (Komma,
+// Semikolon oder Tabulator) wird an der Kopfzeile erkannt.
+func ReadCSV(r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM aus Excel
+
+	firstLine, _, _ := bufio.NewReader(bytes.NewReader(data)).ReadLine()
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = detectDelimiter(string(firstLine))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen der CSV-Datei: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("Die CSV-Datei ist leer")
+	}
+	return &Table{Header: records[0], Rows: records[1:]}, nil
+}
+
+func detectDelimiter(line string) rune {
+	best, count := ',', strings.Count(line, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+// GuessMapping ordnet Spalten anhand ihrer Überschrift den Feldern zu
+func (t *Table) GuessMapping() Mapping {
+	m := make(Mapping)
+	for col, header := range t.Header {
+		header = strings.ToLower(strings.TrimSpace(header))
+		for field, names := range headerNames {
+			if _, ok := m[field]; ok {
+				continue
+			}
+			for _, name := range names {
+				if header == name {
+					m[field] = col
+				}
+			}
+		}
+	}
+	return m
+}
+
+// Row ist eine umgewandelte Zeile; Err ist gesetzt, wenn sie fehlerhaft ist
+type Row struct {
+	Line        int // Zeilennummer in der Datei, die Kopfzeile ist Zeile 1
+	Appointment store.Appointment
+	Err         error
+}
+
+// Appointments wandelt die Zeilen mit der Zuordnung in Termine um. Datum
+// wird als ISO (2025-01-31) oder deutsch (31.01.2025) erkannt.
+func (t *Table) Appointments(m Mapping, levels priority.Set) ([]Row, error) {
+	if _, ok := m[FieldTitle]; !ok {
+		return nil, fmt.Errorf("Keine Spalte für den Titel zugeordnet")
+	}
+	if _, ok := m[FieldDate]; !ok {
+		return nil, fmt.Errorf("Keine Spalte für das Datum zugeordnet")
+	}
+
+	rows := make([]Row, 0, len(t.Rows))
+	for i, record := range t.Rows {
+		if isEmpty(record) {
+			continue
+		}
+		row := Row{Line: i + 2}
+		row.Appointment, row.Err = appointment(record, m, levels)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isEmpty(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func appointment(record []string, m Mapping, levels priority.Set) (store.Appointment, error) {
+	value := func(field string) string {
+		col, ok := m[field]
+		if !ok || col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+
+	a := store.Appointment{Title: value(FieldTitle), Tags: store.SplitTags(value(FieldTags))}
+	if a.Title == "" {
+		return a, fmt.Errorf("Titel fehlt")
+	}
+
+	date := dates.ConvertToISODate(value(FieldDate))
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return a, fmt.Errorf("Ungültiges Datum: %q", value(FieldDate))
+	}
+	a.Date = date
+
+	if t := value(FieldTime); t != "" {
+		parsed, err := parseTime(t)
+		if err != nil {
+			return a, err
+		}
+		a.Time = parsed
+	}
+
+	if p := value(FieldPriority); p != "" {
+		v, err := levels.Parse(p)
+		if err != nil {
+			return a, err
+		}
+		a.Priority = &v
+	}
+	return a, nil
+}
+
+// parseTime akzeptiert 9:30, 09:30, 09:30:00 und 9.30 Uhr
+func parseTime(s string) (string, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "Uhr"))
+	s = strings.ReplaceAll(s, ".", ":")
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("15:04"), nil
+		}
+	}
+	return "", fmt.Errorf("Ungültige Uhrzeit: %q", s)
+}
+
+// Import speichert alle fehlerfreien Zeilen als eine Änderung
+func Import(s store.Importer, rows []Row) (store.Imported, error) {
+	var appointments []store.Appointment
+	for _, row := range rows {
+		if row.Err == nil {
+			appointments = append(appointments, row.Appointment)
+		}
+	}
+	return s.Import(appointments, nil)
+}
+
+// WriteAppointmentsCSV schreibt Termine mit Kopfzeile. Datum und Uhrzeit
+// im ISO-Format lassen sich unverändert wieder importieren.
+func WriteAppointmentsCSV(w io.Writer, appointments []store.Appointment, levels priority.Set) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Titel", "Datum", "Uhrzeit", "Ende", "Priorität", "Tags", "Notizen"})
+	for _, a := range appointments {
+		cw.Write([]string{a.Title, a.Date, a.Time, a.EndTime, priorityName(a.Priority, levels),
+			strings.Join(a.Tags, ","), a.Notes})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTasksCSV schreibt Aufgaben mit Kopfzeile
+func WriteTasksCSV(w io.Writer, tasks []store.Task, levels priority.Set) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Titel", "Erledigt", "Fällig", "Priorität", "Tags", "Notizen"})
+	for _, t := range tasks {
+		cw.Write([]string{t.Title, strconv.FormatBool(t.Completed), t.DueDate,
+			priorityName(t.Priority, levels), strings.Join(t.Tags, ","), t.Notes})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func priorityName(p *int, levels priority.Set) string {
+	if p == nil {
+		return ""
+	}
+	return levels.Lookup(p).Name
+}
+
+// ParseMapping ergänzt die Zuordnung um Angaben der Form
+// "titel=Betreff,datum=3": Feld (deutsch oder englisch) gleich
+// Spaltenüberschrift oder Spaltennummer ab 1. "feld=" entfernt ein Feld.
+func (t *Table) ParseMapping(m Mapping, spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, column, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("Ungültige Zuordnung: %q (erwartet FELD=SPALTE)", part)
+		}
+		field := fieldByName(name)
+		if field == "" {
+			return fmt.Errorf("Unbekanntes Feld: %q", name)
+		}
+		column = strings.TrimSpace(column)
+		if column == "" {
+			delete(m, field)
+			continue
+		}
+		col, err := t.column(column)
+		if err != nil {
+			return err
+		}
+		m[field] = col
+	}
+	return nil
+}
+
+func fieldByName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, field := range Fields {
+		if name == field || name == strings.ToLower(FieldLabels[field]) {
+			return field
+		}
+	}
+	return ""
+}
+
+func (t *Table) column(name string) (int, error) {
+	for i, header := range t.Header {
+		if strings.EqualFold(strings.TrimSpace(header), name) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(t.Header) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("Unbekannte Spalte: %q", name)
+}
@@ -0,0 +1,212 @@
+package exchange
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func read(t *testing.T, content string) *Table {
+	t.Helper()
+	table, err := ReadCSV(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		header  []string
+		rows    [][]string
+	}{
+		{"Komma", "Titel,Datum\nArzt,2030-03-14\n",
+			[]string{"Titel", "Datum"}, [][]string{{"Arzt", "2030-03-14"}}},
+		{"Semikolon aus Excel", "\xef\xbb\xbfTitel;Datum;Notiz\r\nArzt; 14.03.2030;Karte, Überweisung\r\n",
+			[]string{"Titel", "Datum", "Notiz"}, [][]string{{"Arzt", "14.03.2030", "Karte, Überweisung"}}},
+		{"Tabulator", "Titel\tDatum\nArzt\t2030-03-14\n",
+			[]string{"Titel", "Datum"}, [][]string{{"Arzt", "2030-03-14"}}},
+		{"Komma im Titel", "Titel,Datum\n\"Arzt; Zahn\",2030-03-14\n",
+			[]string{"Titel", "Datum"}, [][]string{{"Arzt; Zahn", "2030-03-14"}}},
+		{"unterschiedlich lang", "Titel;Datum;Uhrzeit\nArzt;14.03.2030\n",
+			[]string{"Titel", "Datum", "Uhrzeit"}, [][]string{{"Arzt", "14.03.2030"}}},
+	}
+	for _, tt := range tests {
+		table := read(t, tt.content)
+		if !reflect.DeepEqual(table.Header, tt.header) || !reflect.DeepEqual(table.Rows, tt.rows) {
+			t.Errorf("%s: %q %q", tt.name, table.Header, table.Rows)
+		}
+	}
+
+	for _, content := range []string{"", "Titel,Datum\n\"offen,2030-03-14\n"} {
+		if _, err := ReadCSV(strings.NewReader(content)); err == nil {
+			t.Errorf("%q ohne Fehler gelesen", content)
+		}
+	}
+}
+
+func TestMapping(t *testing.T) {
+	table := read(t, "Betreff;Start Date;Zeit;Notizen;Titel;Prio\n")
+	m := table.GuessMapping()
+	// Nicht erkannte Spalten bleiben frei, bei doppelten gilt die erste
+	want := Mapping{FieldTitle: 0, FieldDate: 1, FieldTime: 2, FieldPriority: 5}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("GuessMapping: %v, erwartet %v", m, want)
+	}
+
+	tests := []struct {
+		spec    string
+		want    Mapping
+		wantErr string
+	}{
+		{"", want, ""},
+		{"titel=Titel", Mapping{FieldTitle: 4, FieldDate: 1, FieldTime: 2, FieldPriority: 5}, ""},
+		{"title=5, tags=notizen", Mapping{FieldTitle: 4, FieldDate: 1, FieldTime: 2, FieldPriority: 5, FieldTags: 3}, ""},
+		{"Uhrzeit=,priorität=", Mapping{FieldTitle: 0, FieldDate: 1}, ""},
+		{"datum", nil, "FELD=SPALTE"},
+		{"ort=Betreff", nil, "Unbekanntes Feld"},
+		{"datum=Ende", nil, "Unbekannte Spalte"},
+		{"datum=7", nil, "Unbekannte Spalte"},
+		{"datum=0", nil, "Unbekannte Spalte"},
+	}
+	for _, tt := range tests {
+		m := table.GuessMapping()
+		err := table.ParseMapping(m, tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q: Fehler %v, erwartet %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(m, tt.want) {
+			t.Errorf("%q: %v, %v, erwartet %v", tt.spec, m, err, tt.want)
+		}
+	}
+}
+
+func TestAppointments(t *testing.T) {
+	table := read(t, "Titel;Datum;Uhrzeit;Priorität;Tags\n"+
+		"Arzt;14.03.2030;9:30;Hoch;Gesundheit, Termin\n"+
+		"Urlaub;1.7.2030;;;\n"+
+		";;;;\n"+
+		"Ohne Datum;;;;\n"+
+		"Falsches Datum;31.02.2030;;;\n"+
+		"Falsche Zeit;2030-03-15;25:00;;\n"+
+		"Falsche Priorität;2030-03-15;;Dringend;\n"+
+		";2030-03-15;;;\n"+
+		"Mittag;2030-03-16;12.00 Uhr;3;\n")
+	levels := priority.Defaults()
+	m := table.GuessMapping()
+
+	// Ohne Titel oder Datum wird nichts umgewandelt
+	for _, field := range []string{FieldTitle, FieldDate} {
+		without := Mapping{}
+		for k, v := range m {
+			if k != field {
+				without[k] = v
+			}
+		}
+		if _, err := table.Appointments(without, levels); err == nil {
+			t.Errorf("Ohne Spalte für %s kein Fehler", field)
+		}
+	}
+
+	rows, err := table.Appointments(m, levels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high := priority.High
+	tests := []struct {
+		line    int
+		want    store.Appointment
+		wantErr string
+	}{
+		{2, store.Appointment{Title: "Arzt", Date: "2030-03-14", Time: "09:30", Priority: &high,
+			Tags: []string{"gesundheit", "termin"}}, ""},
+		{3, store.Appointment{Title: "Urlaub", Date: "2030-07-01"}, ""},
+		// Zeile 4 ist leer und wird übersprungen
+		{5, store.Appointment{}, "Ungültiges Datum"},
+		{6, store.Appointment{}, "Ungültiges Datum"},
+		{7, store.Appointment{}, "Ungültige Uhrzeit"},
+		{8, store.Appointment{}, "Unbekannte Priorität"},
+		{9, store.Appointment{}, "Titel fehlt"},
+		{10, store.Appointment{Title: "Mittag", Date: "2030-03-16", Time: "12:00", Priority: &high}, ""},
+	}
+	if len(rows) != len(tests) {
+		t.Fatalf("%d Zeilen, erwartet %d: %+v", len(rows), len(tests), rows)
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		if row.Line != tt.line {
+			t.Errorf("Zeile %d, erwartet %d", row.Line, tt.line)
+		}
+		if tt.wantErr != "" {
+			if row.Err == nil || !strings.Contains(row.Err.Error(), tt.wantErr) {
+				t.Errorf("Zeile %d: Fehler %v, erwartet %q", row.Line, row.Err, tt.wantErr)
+			}
+			continue
+		}
+		if row.Err != nil || !reflect.DeepEqual(row.Appointment, tt.want) {
+			t.Errorf("Zeile %d: %+v, %v", row.Line, row.Appointment, row.Err)
+		}
+	}
+
+	// Fehlerhafte Zeilen brechen den Import nicht ab
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	imported, err := Import(s, rows)
+	if err != nil || imported.Appointments != 3 {
+		t.Fatalf("Import: %+v, %v", imported, err)
+	}
+	if all, _ := s.QueryAppointments(store.Filter{}); len(all) != 3 {
+		t.Errorf("Im Store: %+v", all)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	levels := priority.Defaults()
+	high := priority.High
+	appointments := []store.Appointment{
+		{Title: "Arzt, Zahn", Date: "2030-03-14", Time: "09:30", EndTime: "10:00", Priority: &high,
+			Tags: []string{"gesundheit", "termin"}, Notes: "Karte\nmitbringen"},
+		{Title: "Urlaub", Date: "2030-07-01"},
+	}
+	var buf bytes.Buffer
+	if err := WriteAppointmentsCSV(&buf, appointments, levels); err != nil {
+		t.Fatal(err)
+	}
+
+	// Die eigene Ausgabe lässt sich ohne Anpassung wieder einlesen
+	table := read(t, buf.String())
+	rows, err := table.Appointments(table.GuessMapping(), levels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range rows {
+		want := appointments[i]
+		want.EndTime, want.Notes = "", ""
+		if row.Err != nil || !reflect.DeepEqual(row.Appointment, want) {
+			t.Errorf("Zeile %d: %+v, %v", row.Line, row.Appointment, row.Err)
+		}
+	}
+
+	buf.Reset()
+	tasks := []store.Task{{Title: "Einkaufen", Completed: true, DueDate: "2030-03-15", Priority: &high}}
+	if err := WriteTasksCSV(&buf, tasks, levels); err != nil {
+		t.Fatal(err)
+	}
+	want := "Titel,Erledigt,Fällig,Priorität,Tags,Notizen\nEinkaufen,true,2030-03-15,Hoch,,\n"
+	if buf.String() != want {
+		t.Errorf("Aufgaben: %q", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"encoding/json"
+	"io"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Aufbau der JSON-Datei beim Export
+type jsonExport struct {
+	Appointments []jsonAppointment `json:"appointments"`
+	Tasks        []jsonTask        `json:"tasks"`
+}
+
+type jsonAppointment struct {
+	ID       int64            `json:"id"`
+	Title    string           `json:"title"`
+	Date     string           `json:"date"`
+	Time     string           `json:"time,omitempty"`
+	EndTime  string           `json:"end_time,omitempty"`
+	Priority string           `json:"priority,omitempty"`
+	Tags     []string         `json:"tags,omitempty"`
+	Notes    string           `json:"notes,omitempty"`
+	Alarms   []priority.Alarm `json:"alarms,omitempty"` // Minuten vor dem Termin, oder "15:mail"
+	UID      string           `json:"uid,omitempty"`
+}
+
+type jsonTask struct {
+	ID        int64    `json:"id"`
+	Title     string   `json:"title"`
+	Completed bool     `json:"completed"`
+	DueDate   string   `json:"due_date,omitempty"`
+	Priority  string   `json:"priority,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Notes     string   `json:"notes,omitempty"`
+	UID       string   `json:"uid,omitempty"`
+}
+
+// WriteJSON schreibt Termine und Aufgaben als ein JSON-Dokument
+func WriteJSON(w io.Writer, appointments []store.Appointment, tasks []store.Task, levels priority.Set) error {
+	out := jsonExport{
+		Appointments: make([]jsonAppointment, len(appointments)),
+		Tasks:        make([]jsonTask, len(tasks)),
+	}
+	for i, a := range appointments {
+		out.Appointments[i] = jsonAppointment{
+			ID: a.ID, Title: a.Title, Date: a.Date, Time: a.Time, EndTime: a.EndTime,
+			Priority: priorityName(a.Priority, levels), Tags: a.Tags, Notes: a.Notes,
+			Alarms: a.Alarms, UID: a.UID,
+		}
+	}
+	for i, t := range tasks {
+		out.Tasks[i] = jsonTask{
+			ID: t.ID, Title: t.Title, Completed: t.Completed, DueDate: t.DueDate,
+			Priority: priorityName(t.Priority, levels), Tags: t.Tags, Notes: t.Notes, UID: t.UID,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func TestWriteJSON(t *testing.T) {
+	critical, unknown := priority.Critical, 7
+	appointments := []store.Appointment{
+		{ID: 1, Title: "Arzt", Date: "2030-03-14", Time: "09:30", Priority: &critical, UID: "a@example.com",
+			Alarms: []priority.Alarm{{Before: time.Hour, Via: "mail"}, {Before: 15 * time.Minute}}},
+		{ID: 2, Title: "Urlaub", Date: "2030-07-01"},
+	}
+	tasks := []store.Task{{ID: 3, Title: "Einkaufen", Priority: &unknown, Tags: []string{"haushalt"}}}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, appointments, tasks, priority.Defaults()); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "appointments": [
+    {
+      "id": 1,
+      "title": "Arzt",
+      "date": "2030-03-14",
+      "time": "09:30",
+      "priority": "Kritisch",
+      "alarms": [
+        "60:mail",
+        15
+      ],
+      "uid": "a@example.com"
+    },
+    {
+      "id": 2,
+      "title": "Urlaub",
+      "date": "2030-07-01"
+    }
+  ],
+  "tasks": [
+    {
+      "id": 3,
+      "title": "Einkaufen",
+      "completed": false,
+      "priority": "7",
+      "tags": [
+        "haushalt"
+      ]
+    }
+  ]
+}
+`
+	if buf.String() != want {
+		t.Errorf("Ausgabe:\n%s", buf.String())
+	}
+
+	// Vorwarnungen lassen sich wieder einlesen
+	var back jsonExport
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if alarms := back.Appointments[0].Alarms; len(alarms) != 2 || alarms[0] != appointments[0].Alarms[0] ||
+		alarms[1] != appointments[0].Alarms[1] {
+		t.Errorf("Vorwarnungen: %+v", alarms)
+	}
+
+	// Ohne Einträge leere Listen statt null
+	buf.Reset()
+	if err := WriteJSON(&buf, nil, nil, priority.Defaults()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\n  \"appointments\": [],\n  \"tasks\": []\n}\n" {
+		t.Errorf("Leere Ausgabe: %s", buf.String())
+	}
+}
@@ -0,0 +1,176 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/ics"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Abstand, in dem geprüft wird, ob ein Kalender abgerufen werden muss
+const checkInterval = time.Minute
+
+// Höchstdauer eines einzelnen Abrufs
+const fetchTimeout = 30 * time.Second
+
+// Größte Antwort beim Abruf über http(s); größere Kalender werden abgelehnt
+const maxFeedSize = 16 << 20
+
+var client = &http.Client{Timeout: fetchTimeout}
+
+// Open öffnet die Quelle eines Abos: eine http(s)- oder webcal-URL, eine
+// file-URL oder einen Dateipfad
+func Open(ctx context.Context, source string) (io.ReadCloser, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 { // C:\… unter Windows
+		return os.Open(source)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return os.Open(u.Path)
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("Nicht unterstützte Quelle: %s", source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen von %s: %v", u.Redacted(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Fehler beim Abrufen von %s: %s", u.Redacted(), resp.Status)
+	}
+	return &limitedBody{body: resp.Body, left: maxFeedSize, source: u.Redacted()}, nil
+}
+
+// limitedBody liefert einen Fehler, sobald die Antwort länger als
+// maxFeedSize ist, statt sie stillschweigend abzuschneiden
+type limitedBody struct {
+	body   io.ReadCloser
+	left   int64
+	source string
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// Nur ein weiteres Byte entscheidet, ob die Antwort zu groß ist
+		var probe [1]byte
+		if n, err := l.body.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%s ist größer als %d MB", l.source, maxFeedSize>>20)
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.body.Read(p)
+	l.left -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
+
+// Refresh ruft einen abonnierten Kalender ab und ersetzt seine Termine.
+// Aufgaben (VTODO) aus Abos werden nicht übernommen. Das Ergebnis wird
+// beim Abo vermerkt, damit GUI und reminderctl es anzeigen können.
+func Refresh(ctx context.Context, s *store.Store, sub store.Subscription) error {
+	err := refresh(ctx, s, sub)
+	if statusErr := s.SetSubscriptionStatus(sub.ID, time.Now(), err); statusErr != nil {
+		log.Print(statusErr)
+	}
+	return err
+}
+
+func refresh(ctx context.Context, s *store.Store, sub store.Subscription) error {
+	r, err := Open(ctx, sub.Source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := ics.Decode(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range data.Errors {
+		log.Printf("Kalender-Abo \"%s\": Eintrag übersprungen: %v", sub.Name, e)
+	}
+	return s.ReplaceSubscriptionAppointments(sub.ID, data.Appointments)
+}
+
+// RefreshDue ruft alle Abos ab, deren Abstand seit dem letzten Abruf
+// verstrichen ist
+func RefreshDue(ctx context.Context, s *store.Store) {
+	subs, err := s.Subscriptions()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		if !sub.Due(now) {
+			continue
+		}
+		if err := Refresh(ctx, s, sub); err != nil {
+			log.Printf("Kalender-Abo \"%s\": %v", sub.Name, err)
+		}
+	}
+}
+
+// Run hält die Abos aktuell, bis ctx beendet wird. GUI und Daemon können
+// beide laufen: der Zeitpunkt des letzten Abrufs steht in der Datenbank,
+// daher ruft nur der erste den Kalender ab.
+func Run(ctx context.Context, s *store.Store) {
+	RefreshDue(ctx, s)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			RefreshDue(ctx, s)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Name schlägt einen Namen für ein Abo vor, wenn keiner angegeben wurde
+func Name(source string) string {
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		return u.Host
+	}
+	name := source[strings.LastIndexAny(source, `/\`)+1:]
+	return strings.TrimSuffix(name, ".ics")
+}
+
+// ParseColor prüft eine Farbe im Format #rrggbb; leer ist erlaubt
+func ParseColor(s string) (color.NRGBA, error) {
+	c := color.NRGBA{A: 0xff}
+	if s == "" {
+		return c, nil
+	}
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil || len(s) != 7 {
+		return c, fmt.Errorf("Ungültige Farbe: %s (erwartet #rrggbb)", s)
+	}
+	return c, nil
+}
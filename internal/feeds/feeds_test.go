@@ -0,0 +1,170 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func event(uid, summary, start string) string {
+	return "BEGIN:VEVENT\r\nUID:" + uid + "\r\nDTSTAMP:20250101T000000Z\r\nSUMMARY:" + summary +
+		"\r\nDTSTART:" + start + "\r\nEND:VEVENT\r\n"
+}
+
+func calendar(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//DE\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+// feed ist ein Kalender auf einem httptest.Server, dessen Inhalt sich ändern lässt
+type feed struct {
+	mu     sync.Mutex
+	body   string
+	status int
+}
+
+func (f *feed) set(status int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status, f.body = status, body
+}
+
+func (f *feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "text/calendar")
+	w.WriteHeader(f.status)
+	fmt.Fprint(w, f.body)
+}
+
+func openStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// byUID liefert die Termine des Abos nach UID
+func byUID(t *testing.T, s *store.Store) map[string]store.Appointment {
+	t.Helper()
+	appointments, err := s.QueryAppointments(store.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string]store.Appointment)
+	for _, a := range appointments {
+		out[a.UID] = a
+	}
+	return out
+}
+
+func TestRefresh(t *testing.T) {
+	f := &feed{}
+	f.set(http.StatusOK, calendar(
+		event("a@test", "Sommerfest", "20250614T180000"),
+		event("b@test", "Feiertag", "20250501T000000")))
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	s := openStore(t)
+	sub := store.Subscription{Name: "Test", Source: srv.URL, Refresh: time.Hour}
+	if err := s.AddSubscription(&sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := Refresh(context.Background(), s, sub); err != nil {
+		t.Fatal(err)
+	}
+	first := byUID(t, s)
+	if len(first) != 2 || first["a@test"].Title != "Sommerfest" || first["a@test"].SubscriptionID != sub.ID {
+		t.Fatalf("nach dem ersten Abruf: %+v", first)
+	}
+	due, err := first["a@test"].Due()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Acknowledge(first["a@test"].ID, due); err != nil {
+		t.Fatal(err)
+	}
+
+	// Geänderte Termine behalten ID und Bestätigung, entfernte verschwinden
+	f.set(http.StatusOK, calendar(
+		event("a@test", "Sommerfest im Park", "20250614T180000"),
+		event("c@test", "Neu", "20250701T090000")))
+	if err := Refresh(context.Background(), s, sub); err != nil {
+		t.Fatal(err)
+	}
+	second := byUID(t, s)
+	if len(second) != 2 {
+		t.Fatalf("nach dem zweiten Abruf: %+v", second)
+	}
+	a := second["a@test"]
+	if a.ID != first["a@test"].ID || a.Title != "Sommerfest im Park" {
+		t.Errorf("Termin nicht an Ort und Stelle aktualisiert: %+v", a)
+	}
+	if acked, err := s.Acknowledged(a.ID, due); err != nil || !acked {
+		t.Errorf("Bestätigung verloren: %v, %v", acked, err)
+	}
+	if _, ok := second["b@test"]; ok {
+		t.Error("Entfernter Termin noch vorhanden")
+	}
+
+	// Fehler beim Abruf lassen die Termine stehen und stehen beim Abo
+	f.set(http.StatusNotFound, "")
+	if err := Refresh(context.Background(), s, sub); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Fehler bei 404: %v", err)
+	}
+	if got, err := s.GetSubscription(sub.ID); err != nil || got.LastError == "" || got.LastRefresh.IsZero() {
+		t.Errorf("Status nach Fehler: %+v, %v", got, err)
+	}
+	if got := byUID(t, s); len(got) != 2 {
+		t.Errorf("Termine nach Fehler: %+v", got)
+	}
+}
+
+func TestRefreshTooLarge(t *testing.T) {
+	f := &feed{}
+	f.set(http.StatusOK, calendar(event("a@test", strings.Repeat("x", maxFeedSize), "20250614T180000")))
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	r, err := Open(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	buf := make([]byte, 64<<10)
+	var read int64
+	for {
+		n, err := r.Read(buf)
+		read += int64(n)
+		if err != nil {
+			if !strings.Contains(err.Error(), "größer als") {
+				t.Errorf("Fehler %v, erwartet zu groß", err)
+			}
+			break
+		}
+	}
+	if read != maxFeedSize {
+		t.Errorf("%d Bytes gelesen, erwartet höchstens %d", read, maxFeedSize)
+	}
+}
+
+func TestInvalidRefresh(t *testing.T) {
+	s := openStore(t)
+	for _, d := range []time.Duration{0, -time.Minute, time.Second} {
+		sub := store.Subscription{Name: "Test", Source: "feiertage.ics", Refresh: d}
+		if err := s.AddSubscription(&sub); err == nil {
+			t.Errorf("Abstand %v angenommen", d)
+		}
+	}
+}
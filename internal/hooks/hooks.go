@@ -0,0 +1,214 @@
+// Package hooks führt bei Ereignissen von reminderd die in der
+// Konfiguration eingetragenen Webhooks und Programme aus, z.B. um
+// Erinnerungen an einen Chat-Bot oder an Lampen weiterzugeben.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"Reminder_Erinnerungs_App/internal/api"
+	"Reminder_Erinnerungs_App/internal/config"
+)
+
+// Standardwerte für Hooks ohne timeout und attempts
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultAttempts = 4
+)
+
+// Wartezeit vor dem zweiten Versuch eines Webhooks, danach jeweils doppelt so lang
+const firstRetry = 2 * time.Second
+
+// Höchstens so viel Ausgabe eines Programms wird bei einem Fehler protokolliert
+const maxOutput = 500
+
+// Payload ist der JSON-Inhalt, den Webhooks und Programme erhalten
+type Payload struct {
+	ID    int64           `json:"id"`
+	Event string          `json:"event"`
+	Time  string          `json:"time"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// runner führt die Hooks aus
+type runner struct {
+	firstRetry time.Duration // siehe firstRetry, in Tests kürzer
+}
+
+// Run führt für jedes Ereignis des Hubs die passenden Hooks aus, bis ctx
+// beendet wird. hooks wird bei jedem Ereignis neu gelesen, damit geänderte
+// Einstellungen ohne Neustart gelten.
+func Run(ctx context.Context, hub *api.Hub, hooks func() []config.Hook) {
+	r := runner{firstRetry: firstRetry}
+	events, cancel := hub.Subscribe()
+	defer cancel()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			var body []byte
+			for _, h := range hooks() {
+				if len(h.Events) > 0 && !slices.Contains(h.Events, e.Name) {
+					continue
+				}
+				if body == nil {
+					var err error
+					body, err = json.Marshal(Payload{
+						ID: e.ID, Event: e.Name, Time: time.Now().Format(time.RFC3339), Data: e.Data,
+					})
+					if err != nil {
+						log.Printf("Fehler beim Erstellen des Ereignisses %s: %v", e.Name, err)
+						break
+					}
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := r.deliver(ctx, h, e, body); err != nil {
+						log.Printf("Hook für %s fehlgeschlagen: %v", e.Name, err)
+					}
+				}()
+			}
+		}
+	}
+}
+
+// deliver führt einen Hook für ein Ereignis aus. body ist der Inhalt als
+// JSON (Payload).
+func (r runner) deliver(ctx context.Context, h config.Hook, e api.Event, body []byte) error {
+	if h.URL != "" {
+		return r.post(ctx, h, e, body)
+	}
+	return run(ctx, h, e, body)
+}
+
+func timeout(h config.Hook) time.Duration {
+	if h.Timeout > 0 {
+		return time.Duration(h.Timeout) * time.Second
+	}
+	return defaultTimeout
+}
+
+// signature liefert den Wert des Headers X-Reminder-Signature: die
+// HMAC-SHA256-Prüfsumme des Inhalts mit dem Schlüssel des Hooks
+func signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post sendet das Ereignis an einen Webhook. Bei Netzwerkfehlern und den
+// Antworten 429 und 5xx wird es mit wachsendem Abstand erneut versucht.
+func (r runner) post(ctx context.Context, h config.Hook, e api.Event, body []byte) error {
+	attempts := h.Attempts
+	if attempts == 0 {
+		attempts = defaultAttempts
+	}
+	wait := r.firstRetry
+	for i := 1; ; i++ {
+		retry, err := postOnce(ctx, h, e, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || i >= attempts {
+			return fmt.Errorf("%s: %v", h.URL, err)
+		}
+		log.Printf("Webhook %s: %v, neuer Versuch in %s", h.URL, err, wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// postOnce sendet das Ereignis einmal und meldet, ob sich ein weiterer
+// Versuch lohnt
+func postOnce(ctx context.Context, h config.Hook, e api.Event, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout(h))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "reminderd")
+	req.Header.Set("X-Reminder-Event", e.Name)
+	req.Header.Set("X-Reminder-Delivery", strconv.FormatInt(e.ID, 10))
+	if h.Secret != "" {
+		req.Header.Set("X-Reminder-Signature", signature(h.Secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil || ctx.Err() == context.DeadlineExceeded, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("Antwort %s", resp.Status)
+	}
+	return false, fmt.Errorf("Antwort %s", resp.Status)
+}
+
+// run startet das Programm des Hooks mit dem Ereignis auf stdin und in den
+// Umgebungsvariablen REMINDER_EVENT und REMINDER_EVENT_ID
+func run(ctx context.Context, h config.Hook, e api.Event, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout(h))
+	defer cancel()
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"REMINDER_EVENT="+e.Name,
+		"REMINDER_EVENT_ID="+strconv.FormatInt(e.ID, 10),
+	)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("nach %s abgebrochen", timeout(h))
+	}
+	out := strings.TrimSpace(string(output))
+	if len(out) > maxOutput {
+		// nicht mitten in einem Zeichen abschneiden
+		cut := maxOutput
+		for cut > 0 && !utf8.RuneStart(out[cut]) {
+			cut--
+		}
+		out = out[:cut] + "…"
+	}
+	if out != "" {
+		return fmt.Errorf("%s: %v: %s", h.Command[0], err, out)
+	}
+	return fmt.Errorf("%s: %v", h.Command[0], err)
+}
@@ -0,0 +1,207 @@
+package hooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"Reminder_Erinnerungs_App/internal/api"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func TestRun(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	hub := api.NewHub(s)
+	dir := t.TempDir()
+
+	// Das Programm legt für jedes Ereignis eine Datei mit dem Inhalt von stdin an
+	hooks := []config.Hook{{
+		Events:  []string{api.EventReminder},
+		Command: []string{"sh", "-c", `cat > "$0/$REMINDER_EVENT-$REMINDER_EVENT_ID.json"`, dir},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, hub, func() []config.Hook { return hooks })
+		close(done)
+	}()
+
+	// Run abonniert den Hub erst nach dem Start
+	a := store.Appointment{Title: "Arzt", Date: "2030-03-14", Time: "09:30"}
+	var files []os.DirEntry
+	for i := 0; i < 100 && len(files) == 0; i++ {
+		hub.Publish(api.EventChanged, api.Changed{})
+		hub.PublishReminder("due", a, time.Now(), 0)
+		time.Sleep(20 * time.Millisecond)
+		if files, err = os.ReadDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	<-done // wartet auch auf laufende Programme
+
+	files, _ = os.ReadDir(dir)
+	if len(files) == 0 {
+		t.Fatal("Programm nicht ausgeführt")
+	}
+	for _, f := range files {
+		id, ok := strings.CutPrefix(strings.TrimSuffix(f.Name(), ".json"), api.EventReminder+"-")
+		if !ok {
+			t.Errorf("Programm für anderes Ereignis ausgeführt: %s", f.Name())
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p Payload
+		if err := json.Unmarshal(data, &p); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		var r api.Reminder
+		if p.Event != api.EventReminder || strconv.FormatInt(p.ID, 10) != id ||
+			json.Unmarshal(p.Data, &r) != nil || r.Appointment.Title != "Arzt" {
+			t.Errorf("Inhalt auf stdin: %s", data)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	e := api.Event{ID: 7, Name: api.EventReminder, Data: json.RawMessage(`{}`)}
+	tests := []struct {
+		name    string
+		hook    config.Hook
+		wantErr string
+	}{
+		{"erfolgreich", config.Hook{Command: []string{"true"}}, ""},
+		{"Umgebung", config.Hook{Command: []string{"sh", "-c", `test "$REMINDER_EVENT_ID" = 7`}}, ""},
+		{"Fehler", config.Hook{Command: []string{"false"}}, "false: exit status 1"},
+		{"Ausgabe", config.Hook{Command: []string{"sh", "-c", "echo kaputt >&2; exit 3"}}, "exit status 3: kaputt"},
+		{"Zeitlimit", config.Hook{Command: []string{"sleep", "5"}, Timeout: 1}, "nach 1s abgebrochen"},
+		{"fehlt", config.Hook{Command: []string{filepath.Join(t.TempDir(), "fehlt")}}, "fehlt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runner{}.deliver(context.Background(), tt.hook, e, []byte(`{"id":7}`))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fehler %v, erwartet %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Lange Ausgabe wird an einer Zeichengrenze gekürzt
+	long := config.Hook{Command: []string{"sh", "-c", "printf 'x%.0sä' $(seq 400) >&2; exit 1"}}
+	err := runner{}.deliver(context.Background(), long, e, nil)
+	if err == nil || !utf8.ValidString(err.Error()) || !strings.HasSuffix(err.Error(), "x…") {
+		t.Errorf("Gekürzte Ausgabe: %v", err)
+	}
+}
+
+// webhook antwortet der Reihe nach mit statuses und danach mit 200
+type webhook struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (h *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r)
+	h.bodies = append(h.bodies, body)
+	status := http.StatusOK
+	if len(h.statuses) > 0 {
+		status, h.statuses = h.statuses[0], h.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhook(t *testing.T) {
+	r := runner{firstRetry: time.Millisecond}
+	e := api.Event{ID: 42, Name: api.EventTaskDone}
+	body := []byte(`{"id":42,"event":"task.completed"}`)
+
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		requests int
+		wantErr  string
+	}{
+		{"erfolgreich", nil, 0, 1, ""},
+		{"erneut versucht", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, 0, 3, ""},
+		{"nicht wiederholt", []int{http.StatusBadRequest}, 0, 1, "400 Bad Request"},
+		{"aufgegeben", []int{500, 500, 500}, 2, 2, "500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &webhook{statuses: tt.statuses}
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			hook := config.Hook{URL: srv.URL, Secret: "schlüssel", Attempts: tt.attempts}
+			err := r.deliver(context.Background(), hook, e, body)
+			if tt.wantErr == "" && err != nil {
+				t.Error(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Fehler %v, erwartet %q", err, tt.wantErr)
+			}
+
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if len(h.requests) != tt.requests {
+				t.Fatalf("%d Anfragen, erwartet %d", len(h.requests), tt.requests)
+			}
+			mac := hmac.New(sha256.New, []byte("schlüssel"))
+			mac.Write(body)
+			want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+			for i, r := range h.requests {
+				if r.Method != http.MethodPost || r.Header.Get("X-Reminder-Event") != e.Name ||
+					r.Header.Get("X-Reminder-Delivery") != "42" || r.Header.Get("X-Reminder-Signature") != want {
+					t.Errorf("Anfrage %d: %s %v", i, r.Method, r.Header)
+				}
+				if string(h.bodies[i]) != string(body) {
+					t.Errorf("Inhalt %s", h.bodies[i])
+				}
+			}
+		})
+	}
+
+	// Ohne Schlüssel keine Signatur
+	h := &webhook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	if err := r.deliver(context.Background(), config.Hook{URL: srv.URL}, e, body); err != nil {
+		t.Fatal(err)
+	}
+	if sig := h.requests[0].Header.Get("X-Reminder-Signature"); sig != "" {
+		t.Errorf("Signatur ohne Schlüssel: %s", sig)
+	}
+}
@@ -0,0 +1,126 @@
+// Package i18n übersetzt die Texte der Oberfläche und der Erinnerungen mit
+// go-i18n. Die Kataloge liegen als TOML in locales/, Deutsch ist die
+// Ausgangssprache. Protokoll- und Fehlermeldungen bleiben deutsch.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.toml
+var catalogs embed.FS
+
+// Languages sind die Sprachen mit Katalog; die erste gilt, wenn keine passt
+var Languages = []string{"de", "en"}
+
+var (
+	bundle  = newBundle()
+	matcher = newMatcher()
+
+	mu        sync.RWMutex
+	current   = Languages[0]
+	localizer = goi18n.NewLocalizer(bundle, current)
+)
+
+func newBundle() *goi18n.Bundle {
+	b := goi18n.NewBundle(language.Make(Languages[0]))
+	b.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+	for _, lang := range Languages {
+		if _, err := b.LoadMessageFileFS(catalogs, "locales/"+lang+".toml"); err != nil {
+			panic(fmt.Sprintf("Fehler beim Laden des Katalogs %s: %v", lang, err))
+		}
+	}
+	return b
+}
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, len(Languages))
+	for i, lang := range Languages {
+		tags[i] = language.Make(lang)
+	}
+	return language.NewMatcher(tags)
+}
+
+// Match liefert die Sprache mit Katalog zu einer Angabe wie "en", "de-AT"
+// oder "en_US.UTF-8"; leer = aus der Umgebung (Detect)
+func Match(locale string) string {
+	if locale == "" {
+		locale = Detect()
+	}
+	// POSIX-Schreibweise: Sprache_Land.Zeichensatz@Variante
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	tag, err := language.Parse(strings.ReplaceAll(locale, "_", "-"))
+	if err != nil {
+		return Languages[0]
+	}
+	_, index, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		return Languages[0]
+	}
+	return Languages[index]
+}
+
+// Detect liefert die Spracheinstellung der Umgebung aus LC_ALL, LC_MESSAGES
+// oder LANG, z.B. "de_DE.UTF-8"
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	return ""
+}
+
+// SetLanguage stellt die Sprache für T ein (siehe Match) und liefert sie
+func SetLanguage(locale string) string {
+	lang := Match(locale)
+	mu.Lock()
+	defer mu.Unlock()
+	current = lang
+	localizer = goi18n.NewLocalizer(bundle, lang)
+	return lang
+}
+
+// Language liefert die eingestellte Sprache, z.B. "de"
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T übersetzt die Nachricht id in die eingestellte Sprache. args sind Paare
+// aus Name und Wert für die Platzhalter, z.B. T("reminder.in", "Count", 5);
+// ein Wert "Count" wählt zusätzlich die Pluralform. Unbekannte Nachrichten
+// kommen als id zurück.
+func T(id string, args ...any) string {
+	var data map[string]any
+	var count any
+	if len(args) > 0 {
+		data = make(map[string]any, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			name := fmt.Sprint(args[i])
+			data[name] = args[i+1]
+			if name == "Count" {
+				count = args[i+1]
+			}
+		}
+	}
+
+	mu.RLock()
+	l := localizer
+	mu.RUnlock()
+	text, err := l.Localize(&goi18n.LocalizeConfig{MessageID: id, TemplateData: data, PluralCount: count})
+	if err != nil && text == "" {
+		return id
+	}
+	return text
+}
@@ -0,0 +1,372 @@
+package ics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+	"github.com/teambition/rrule-go"
+)
+
+// Wiederkehrende Termine werden nur in diesem Zeitraum ab heute aufgelöst
+const (
+	recurrenceHorizon = 366 * 24 * time.Hour
+	maxOccurrences    = 500
+)
+
+// Data sind die Einträge einer .ics-Datei, umgewandelt in Termine und Aufgaben
+type Data struct {
+	Appointments []store.Appointment
+	Tasks        []store.Task
+	Errors       []error // Einträge, die nicht umgewandelt werden konnten
+}
+
+// Decode liest einen iCalendar-Datenstrom (RFC 5545). VEVENTs werden zu
+// Terminen, VTODOs zu Aufgaben. Wiederholungen (RRULE, RDATE, EXDATE,
+// RECURRENCE-ID) werden ab heute für ein Jahr als einzelne Termine aufgelöst,
+// deren UID das Datum der Wiederholung enthält.
+func Decode(r io.Reader) (*Data, error) {
+	cal, err := ical.NewDecoder(r).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen der iCalendar-Datei: %v", err)
+	}
+	return DecodeCalendar(cal), nil
+}
+
+// DecodeCalendar wandelt einen bereits gelesenen Kalender um, siehe Decode
+func DecodeCalendar(cal *ical.Calendar) *Data {
+	data := &Data{}
+	from := startOfDay(time.Now())
+	until := from.Add(recurrenceHorizon)
+
+	// Geänderte Einzeltermine einer Serie ersetzen die jeweilige Wiederholung
+	overrides := make(map[string]map[int64]bool)
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent || child.Props.Get(ical.PropRecurrenceID) == nil {
+			continue
+		}
+		uid, _ := child.Props.Text(ical.PropUID)
+		recurrence, _, err := dateTime(child.Props.Get(ical.PropRecurrenceID))
+		if err != nil {
+			continue
+		}
+		if overrides[uid] == nil {
+			overrides[uid] = make(map[int64]bool)
+		}
+		overrides[uid][recurrence.Unix()] = true
+	}
+
+	for _, child := range cal.Children {
+		switch child.Name {
+		case ical.CompEvent:
+			appointments, err := decodeEvent(child, from, until, overrides)
+			if err != nil {
+				data.Errors = append(data.Errors, err)
+				continue
+			}
+			data.Appointments = append(data.Appointments, appointments...)
+		case ical.CompToDo:
+			task, err := decodeToDo(child)
+			if err != nil {
+				data.Errors = append(data.Errors, err)
+				continue
+			}
+			data.Tasks = append(data.Tasks, task)
+		}
+	}
+	return data
+}
+
+func decodeEvent(ev *ical.Component, from, until time.Time, overrides map[string]map[int64]bool) ([]store.Appointment, error) {
+	uid, _ := ev.Props.Text(ical.PropUID)
+	title, _ := ev.Props.Text(ical.PropSummary)
+	start, allDay, err := dateTime(ev.Props.Get(ical.PropDateTimeStart))
+	if err != nil {
+		return nil, fmt.Errorf("Termin \"%s\": %v", title, err)
+	}
+
+	a := store.Appointment{
+		Title:    title,
+		Priority: decodePriority(ev.Props),
+		Tags:     categories(ev.Props),
+		Alarms:   alarms(ev, start),
+	}
+	if a.Title == "" {
+		a.Title = "(ohne Titel)"
+	}
+	a.Notes, _ = ev.Props.Text(ical.PropDescription)
+
+	var duration time.Duration
+	if end, _, err := dateTime(ev.Props.Get(ical.PropDateTimeEnd)); err == nil && end.After(start) {
+		duration = end.Sub(start)
+	} else if p := ev.Props.Get(ical.PropDuration); p != nil {
+		duration, _ = p.Duration()
+	}
+
+	occurrence := func(t time.Time, uid string) store.Appointment {
+		o := a
+		o.UID = uid
+		o.Date = t.Format("2006-01-02")
+		if !allDay {
+			o.Time = t.Format("15:04")
+			if duration > 0 && duration < 24*time.Hour {
+				o.EndTime = t.Add(duration).Format("15:04")
+			}
+		}
+		return o
+	}
+
+	// Geänderter Einzeltermin einer Serie
+	if p := ev.Props.Get(ical.PropRecurrenceID); p != nil {
+		recurrence, _, err := dateTime(p)
+		if err != nil {
+			return nil, fmt.Errorf("Termin \"%s\": %v", title, err)
+		}
+		if start.Before(from) {
+			return nil, nil
+		}
+		return []store.Appointment{occurrence(start, OccurrenceUID(uid, recurrence))}, nil
+	}
+
+	times, err := occurrences(ev, start, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("Termin \"%s\": %v", title, err)
+	}
+	if times == nil {
+		return []store.Appointment{occurrence(start, uid)}, nil
+	}
+
+	var result []store.Appointment
+	for _, t := range times {
+		if overrides[uid][t.Unix()] {
+			continue
+		}
+		result = append(result, occurrence(t, OccurrenceUID(uid, t)))
+	}
+	return result, nil
+}
+
+// OccurrenceUID ist die UID einer einzelnen Wiederholung
+func OccurrenceUID(uid string, t time.Time) string {
+	return uid + "/" + t.UTC().Format("20060102T150405Z")
+}
+
+// occurrences liefert die Wiederholungen eines Termins im Zeitraum, oder
+// nil, wenn er sich nicht wiederholt
+func occurrences(ev *ical.Component, start, from, until time.Time) ([]time.Time, error) {
+	option, err := ev.Props.RecurrenceRule()
+	if err != nil {
+		return nil, err
+	}
+	rdates, err := dateList(ev.Props, ical.PropRecurrenceDates)
+	if err != nil {
+		return nil, err
+	}
+	if option == nil && len(rdates) == 0 {
+		return nil, nil
+	}
+
+	set := rrule.Set{}
+	if option != nil {
+		option.Dtstart = start
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, fmt.Errorf("Ungültige Wiederholungsregel: %v", err)
+		}
+		set.RRule(rule)
+	}
+	set.DTStart(start)
+	set.RDate(start)
+	for _, t := range rdates {
+		set.RDate(t)
+	}
+	exdates, err := dateList(ev.Props, ical.PropExceptionDates)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range exdates {
+		set.ExDate(t)
+	}
+
+	var times []time.Time
+	next := set.Iterator()
+	for t, ok := next(); ok && t.Before(until) && len(times) < maxOccurrences; t, ok = next() {
+		if !t.Before(from) {
+			times = append(times, t.In(time.Local))
+		}
+	}
+	if times == nil {
+		times = []time.Time{}
+	}
+	return times, nil
+}
+
+// dateList liest RDATE/EXDATE, die mehrere kommagetrennte Werte haben können
+func dateList(props ical.Props, name string) ([]time.Time, error) {
+	var list []time.Time
+	for _, p := range props.Values(name) {
+		for _, value := range strings.Split(p.Value, ",") {
+			single := p
+			single.Value = value
+			t, _, err := dateTime(&single)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+// dateTime liest ein Datum oder einen Zeitpunkt in Ortszeit. Unbekannte
+// Zeitzonen (etwa Windows-Namen aus Outlook) werden als Ortszeit gelesen.
+func dateTime(p *ical.Prop) (t time.Time, allDay bool, err error) {
+	if p == nil {
+		return t, false, fmt.Errorf("Datum fehlt")
+	}
+	allDay = p.ValueType() == ical.ValueDate || len(p.Value) == len("20060102")
+	t, err = p.DateTime(time.Local)
+	if err != nil && p.Params.Get(ical.PropTimezoneID) != "" {
+		local := *p
+		local.Params = make(ical.Params)
+		for k, v := range p.Params {
+			if k != ical.PropTimezoneID {
+				local.Params[k] = v
+			}
+		}
+		t, err = local.DateTime(time.Local)
+	}
+	if err != nil {
+		return t, allDay, fmt.Errorf("Ungültiges Datum %s: %v", p.Value, err)
+	}
+	return t.In(time.Local), allDay, nil
+}
+
+// alarms liest die Vorwarnungen aus den VALARMs eines Termins. Erinnerungen
+// per E-Mail (ACTION:EMAIL) gehen auch hier zusätzlich per E-Mail.
+func alarms(ev *ical.Component, start time.Time) []priority.Alarm {
+	var result []priority.Alarm
+	for _, child := range ev.Children {
+		if child.Name != ical.CompAlarm {
+			continue
+		}
+		trigger := child.Props.Get(ical.PropTrigger)
+		if trigger == nil {
+			continue
+		}
+		var before time.Duration
+		if trigger.ValueType() == ical.ValueDateTime {
+			at, _, err := dateTime(trigger)
+			if err != nil {
+				continue
+			}
+			before = start.Sub(at)
+		} else {
+			d, err := trigger.Duration()
+			if err != nil || strings.EqualFold(trigger.Params.Get("RELATED"), "END") {
+				continue
+			}
+			before = -d
+		}
+		before = before.Round(time.Minute)
+		if before <= 0 || slices.ContainsFunc(result, func(a priority.Alarm) bool { return a.Before == before }) {
+			continue
+		}
+		alarm := priority.Alarm{Before: before}
+		if p := child.Props.Get(propVia); p != nil {
+			alarm.Via = p.Value
+		} else if p := child.Props.Get(ical.PropAction); p != nil && strings.EqualFold(p.Value, "EMAIL") {
+			alarm.Via = "mail"
+		}
+		result = append(result, alarm)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before > result[j].Before })
+	return result
+}
+
+func decodeToDo(todo *ical.Component) (store.Task, error) {
+	t := store.Task{
+		Priority: decodePriority(todo.Props),
+		Tags:     categories(todo.Props),
+	}
+	t.UID, _ = todo.Props.Text(ical.PropUID)
+	t.Title, _ = todo.Props.Text(ical.PropSummary)
+	if t.Title == "" {
+		t.Title = "(ohne Titel)"
+	}
+	t.Notes, _ = todo.Props.Text(ical.PropDescription)
+	if p := todo.Props.Get(ical.PropDue); p != nil {
+		due, _, err := dateTime(p)
+		if err != nil {
+			return t, fmt.Errorf("Aufgabe \"%s\": %v", t.Title, err)
+		}
+		t.DueDate = due.Format("2006-01-02")
+	}
+	status, _ := todo.Props.Text(ical.PropStatus)
+	t.Completed = strings.EqualFold(status, "COMPLETED") || todo.Props.Get(ical.PropCompleted) != nil
+	return t, nil
+}
+
+func categories(props ical.Props) []string {
+	var tags []string
+	for _, p := range props.Values(ical.PropCategories) {
+		list, err := p.TextList()
+		if err != nil {
+			continue
+		}
+		tags = append(tags, list...)
+	}
+	return store.SplitTags(store.JoinTags(tags))
+}
+
+// decodePriority bildet PRIORITY (1 = höchste, 9 = niedrigste, 0 = keine)
+// auf die Stufen der Anwendung ab
+func decodePriority(props ical.Props) *int {
+	p := props.Get(ical.PropPriority)
+	if p == nil {
+		return nil
+	}
+	v, err := p.Int()
+	if err != nil || v <= 0 || v > 9 {
+		return nil
+	}
+	var level int
+	switch {
+	case v == 1:
+		level = priority.Critical
+	case v < 5:
+		level = priority.High
+	case v == 5:
+		level = priority.Normal
+	default:
+		level = priority.Low
+	}
+	return &level
+}
+
+// encodePriority ist die Umkehrung von decodePriority
+func encodePriority(p *int) int {
+	if p == nil {
+		return 0
+	}
+	switch {
+	case *p >= priority.Critical:
+		return 1
+	case *p == priority.High:
+		return 3
+	case *p == priority.Normal:
+		return 5
+	}
+	return 9
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
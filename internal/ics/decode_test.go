@@ -0,0 +1,236 @@
+package ics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+)
+
+// calendar liest einen Kalender mit den Zeilen lines
+func calendar(t *testing.T, lines ...string) *Data {
+	t.Helper()
+	body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//DE\r\n" +
+		strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	data, err := Decode(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// local formatiert t als Zeitpunkt ohne Zeitzone, also in Ortszeit
+func local(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// when liefert Datum und Uhrzeit der Termine
+func when(data *Data) []string {
+	var list []string
+	for _, a := range data.Appointments {
+		list = append(list, strings.TrimSpace(a.Date+" "+a.Time))
+	}
+	return list
+}
+
+func TestDecodeRecurrence(t *testing.T) {
+	// Alle Termine liegen im aufgelösten Jahr ab heute
+	start := startOfDay(time.Now()).AddDate(0, 0, 7).Add(9 * time.Hour)
+	day := func(days int) string { return start.AddDate(0, 0, days).Format("2006-01-02") + " 09:00" }
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{"einzeln", []string{"DTSTART:" + local(start)}, []string{day(0)}},
+		{"wöchentlich", []string{"DTSTART:" + local(start), "RRULE:FREQ=WEEKLY;COUNT=3"},
+			[]string{day(0), day(7), day(14)}},
+		{"EXDATE", []string{"DTSTART:" + local(start), "RRULE:FREQ=DAILY;COUNT=4",
+			"EXDATE:" + local(start.AddDate(0, 0, 1)) + "," + local(start.AddDate(0, 0, 3))},
+			[]string{day(0), day(2)}},
+		{"RDATE", []string{"DTSTART:" + local(start), "RDATE:" + local(start.AddDate(0, 0, 30))},
+			[]string{day(0), day(30)}},
+		{"RRULE und RDATE", []string{"DTSTART:" + local(start), "RRULE:FREQ=DAILY;COUNT=2",
+			"RDATE:" + local(start.AddDate(0, 0, 5))}, []string{day(0), day(1), day(5)}},
+		{"Vergangenes ausgelassen, heute nicht", []string{"DTSTART:" + local(start.AddDate(0, 0, -14)), "RRULE:FREQ=WEEKLY;COUNT=4"},
+			[]string{day(-7), day(0), day(7)}},
+		{"ohne Ende begrenzt", []string{"DTSTART:" + local(start), "RRULE:FREQ=YEARLY"},
+			[]string{day(0)}},
+		{"ganztägig", []string{"DTSTART;VALUE=DATE:" + start.Format("20060102"), "RRULE:FREQ=DAILY;COUNT=2"},
+			[]string{day(0)[:10], day(1)[:10]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append([]string{"BEGIN:VEVENT", "UID:serie", "SUMMARY:Training"}, tt.lines...)
+			data := calendar(t, append(lines, "END:VEVENT")...)
+			if len(data.Errors) > 0 {
+				t.Fatal(data.Errors)
+			}
+			if got := when(data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%v, erwartet %v", got, tt.want)
+			}
+		})
+	}
+
+	// Wiederholungen haben je eine eigene UID, ein einzelner Termin behält seine
+	data := calendar(t, "BEGIN:VEVENT", "UID:serie", "DTSTART:"+local(start), "RRULE:FREQ=DAILY;COUNT=2", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:einzeln", "DTSTART:"+local(start), "END:VEVENT")
+	uids := []string{OccurrenceUID("serie", start), OccurrenceUID("serie", start.AddDate(0, 0, 1)), "einzeln"}
+	for i, a := range data.Appointments {
+		if a.UID != uids[i] {
+			t.Errorf("UID %s, erwartet %s", a.UID, uids[i])
+		}
+	}
+}
+
+func TestDecodeOverride(t *testing.T) {
+	start := startOfDay(time.Now()).AddDate(0, 0, 7).Add(9 * time.Hour)
+	second := start.AddDate(0, 0, 7)
+	moved := second.Add(2 * time.Hour)
+
+	// Die geänderte Wiederholung steht vor der Serie in der Datei
+	data := calendar(t,
+		"BEGIN:VEVENT", "UID:serie", "SUMMARY:Training verschoben", "RECURRENCE-ID:"+local(second),
+		"DTSTART:"+local(moved), "DTEND:"+local(moved.Add(time.Hour)), "END:VEVENT",
+		"BEGIN:VEVENT", "UID:serie", "SUMMARY:Training", "DTSTART:"+local(start),
+		"DURATION:PT90M", "RRULE:FREQ=WEEKLY;COUNT=3", "END:VEVENT")
+	if len(data.Errors) > 0 {
+		t.Fatal(data.Errors)
+	}
+	if len(data.Appointments) != 3 {
+		t.Fatalf("%d Termine: %+v", len(data.Appointments), data.Appointments)
+	}
+	byUID := make(map[string]string)
+	for _, a := range data.Appointments {
+		byUID[a.UID] = a.Title + " " + a.Date + " " + a.Time + "-" + a.EndTime
+	}
+	want := map[string]string{
+		OccurrenceUID("serie", start):                   "Training " + start.Format("2006-01-02") + " 09:00-10:30",
+		OccurrenceUID("serie", second):                  "Training verschoben " + second.Format("2006-01-02") + " 11:00-12:00",
+		OccurrenceUID("serie", start.AddDate(0, 0, 14)): "Training " + start.AddDate(0, 0, 14).Format("2006-01-02") + " 09:00-10:30",
+	}
+	if !reflect.DeepEqual(byUID, want) {
+		t.Errorf("%v, erwartet %v", byUID, want)
+	}
+
+	// Vergangene Änderungen werden nicht übernommen
+	past := startOfDay(time.Now()).AddDate(0, 0, -7).Add(9 * time.Hour)
+	data = calendar(t, "BEGIN:VEVENT", "UID:serie", "RECURRENCE-ID:"+local(past), "DTSTART:"+local(past), "END:VEVENT")
+	if len(data.Appointments)+len(data.Errors) != 0 {
+		t.Errorf("Vergangene Änderung: %+v %v", data.Appointments, data.Errors)
+	}
+}
+
+func TestDecodeTimezone(t *testing.T) {
+	tests := []struct {
+		name string
+		prop string
+		want time.Time
+	}{
+		{"UTC", "DTSTART:20300314T083000Z", time.Date(2030, 3, 14, 8, 30, 0, 0, time.UTC)},
+		{"ohne Zeitzone", "DTSTART:20300314T093000", time.Date(2030, 3, 14, 9, 30, 0, 0, time.Local)},
+		{"bekannte Zeitzone", "DTSTART;TZID=America/New_York:20300314T093000",
+			time.Date(2030, 3, 14, 9, 30, 0, 0, mustLoad(t, "America/New_York"))},
+		{"Windows-Name", "DTSTART;TZID=W. Europe Standard Time:20300314T093000",
+			time.Date(2030, 3, 14, 9, 30, 0, 0, time.Local)},
+		{"erfundene Zeitzone", "DTSTART;TZID=Mars/Olympus:20300314T093000",
+			time.Date(2030, 3, 14, 9, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		data := calendar(t, "BEGIN:VEVENT", "UID:x", tt.prop, "END:VEVENT")
+		if len(data.Errors) > 0 || len(data.Appointments) != 1 {
+			t.Errorf("%s: %+v %v", tt.name, data.Appointments, data.Errors)
+			continue
+		}
+		a := data.Appointments[0]
+		want := tt.want.In(time.Local)
+		if a.Date != want.Format("2006-01-02") || a.Time != want.Format("15:04") {
+			t.Errorf("%s: %s %s, erwartet %s", tt.name, a.Date, a.Time, want.Format("2006-01-02 15:04"))
+		}
+	}
+
+	// Ein unlesbares Datum wird als fehlerhafter Eintrag gemeldet
+	data := calendar(t, "BEGIN:VEVENT", "UID:x", "SUMMARY:Kaputt", "DTSTART:morgen", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:y", "SUMMARY:Ohne Beginn", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:z", "DTSTART:20300314T093000", "END:VEVENT")
+	if len(data.Errors) != 2 || !strings.Contains(data.Errors[0].Error(), "Kaputt") ||
+		!strings.Contains(data.Errors[1].Error(), "Datum fehlt") {
+		t.Errorf("Fehler: %v", data.Errors)
+	}
+	if len(data.Appointments) != 1 || data.Appointments[0].Title != "(ohne Titel)" {
+		t.Errorf("Termine: %+v", data.Appointments)
+	}
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip(err)
+	}
+	return loc
+}
+
+func TestDecodeAlarms(t *testing.T) {
+	valarm := func(lines ...string) []string {
+		return append(append([]string{"BEGIN:VALARM", "ACTION:DISPLAY"}, lines...), "END:VALARM")
+	}
+	tests := []struct {
+		name   string
+		alarms [][]string
+		want   []priority.Alarm
+	}{
+		{"Dauer", [][]string{valarm("TRIGGER:-PT15M")}, []priority.Alarm{{Before: 15 * time.Minute}}},
+		{"Zeitpunkt", [][]string{valarm("TRIGGER;VALUE=DATE-TIME:20300314T080000Z")},
+			[]priority.Alarm{{Before: 90 * time.Minute}}},
+		{"sortiert", [][]string{valarm("TRIGGER:-PT15M"), valarm("TRIGGER:-P1D")},
+			[]priority.Alarm{{Before: 24 * time.Hour}, {Before: 15 * time.Minute}}},
+		{"doppelt", [][]string{valarm("TRIGGER:-PT15M"), valarm("TRIGGER:-PT900S")},
+			[]priority.Alarm{{Before: 15 * time.Minute}}},
+		{"nach Beginn", [][]string{valarm("TRIGGER:PT5M")}, nil},
+		{"zum Ende", [][]string{valarm("TRIGGER;RELATED=END:-PT15M")}, nil},
+		{"ohne Auslöser", [][]string{valarm()}, nil},
+		{"E-Mail", [][]string{{"BEGIN:VALARM", "ACTION:EMAIL", "TRIGGER:-PT1H", "END:VALARM"}},
+			[]priority.Alarm{{Before: time.Hour, Via: "mail"}}},
+		{"Weg", [][]string{valarm("TRIGGER:-PT30M", "X-REMINDER-VIA:push")},
+			[]priority.Alarm{{Before: 30 * time.Minute, Via: "push"}}},
+	}
+	for _, tt := range tests {
+		lines := []string{"BEGIN:VEVENT", "UID:x", "DTSTART:20300314T093000Z"}
+		for _, a := range tt.alarms {
+			lines = append(lines, a...)
+		}
+		data := calendar(t, append(lines, "END:VEVENT")...)
+		if len(data.Appointments) != 1 {
+			t.Fatalf("%s: %v", tt.name, data.Errors)
+		}
+		if got := data.Appointments[0].Alarms; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %+v, erwartet %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeToDo(t *testing.T) {
+	data := calendar(t,
+		"BEGIN:VTODO", "UID:a", "SUMMARY:Einkaufen", "DUE;VALUE=DATE:20300314", "STATUS:NEEDS-ACTION",
+		"PRIORITY:1", "CATEGORIES:Haushalt,Wichtig", "END:VTODO",
+		"BEGIN:VTODO", "UID:b", "SUMMARY:Erledigt", "COMPLETED:20300101T100000Z", "PRIORITY:7", "END:VTODO",
+		"BEGIN:VTODO", "UID:c", "SUMMARY:Kaputt", "DUE:irgendwann", "END:VTODO")
+	if len(data.Errors) != 1 || !strings.Contains(data.Errors[0].Error(), "Kaputt") {
+		t.Errorf("Fehler: %v", data.Errors)
+	}
+	if len(data.Tasks) != 2 {
+		t.Fatalf("Aufgaben: %+v", data.Tasks)
+	}
+	a, b := data.Tasks[0], data.Tasks[1]
+	if a.UID != "a" || a.DueDate != "2030-03-14" || a.Completed || a.Priority == nil ||
+		*a.Priority != priority.Critical || !reflect.DeepEqual(a.Tags, []string{"haushalt", "wichtig"}) {
+		t.Errorf("Erste Aufgabe: %+v", a)
+	}
+	if !b.Completed || b.DueDate != "" || b.Priority == nil || *b.Priority != priority.Low {
+		t.Errorf("Zweite Aufgabe: %+v", b)
+	}
+}
@@ -0,0 +1,178 @@
+package ics
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+)
+
+const productID = "-//Reminder-Erinnerungs-App//DE"
+
+// Zusätzlicher Benachrichtigungsweg einer Vorwarnung (priority.Alarm.Via)
+const propVia = "X-REMINDER-VIA"
+
+// Result fasst einen Import zusammen
+type Result struct {
+	Appointments int     // neu angelegte Termine
+	Tasks        int     // neu angelegte Aufgaben
+	Duplicates   int     // anhand der UID als bereits vorhanden übersprungen
+	Errors       []error // nicht übernommene Einträge
+
+	Operation store.Operation // Eintrag im Änderungsjournal für Rückgängig
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%d Termine und %d Aufgaben importiert, %d bereits vorhanden, %d fehlerhaft",
+		r.Appointments, r.Tasks, r.Duplicates, len(r.Errors))
+}
+
+// Import liest eine .ics-Datei ein und speichert sie als eine Änderung.
+// Einträge, deren UID schon vorhanden ist, werden übersprungen.
+func Import(s store.Importer, r io.Reader) (Result, error) {
+	data, err := Decode(r)
+	if err != nil {
+		return Result{}, err
+	}
+	imported, err := s.Import(data.Appointments, data.Tasks)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Appointments: imported.Appointments, Tasks: imported.Tasks, Duplicates: imported.Duplicates,
+		Errors: data.Errors, Operation: imported.Operation,
+	}, nil
+}
+
+// Export schreibt alle Termine und Aufgaben als eine .ics-Datei
+func Export(s *store.Store, w io.Writer) error {
+	appointments, err := s.QueryAppointments(store.Filter{OwnOnly: true})
+	if err != nil {
+		return err
+	}
+	tasks, err := s.QueryTasks(store.Filter{})
+	if err != nil {
+		return err
+	}
+	if len(appointments)+len(tasks) == 0 {
+		return fmt.Errorf("Keine Termine oder Aufgaben zum Exportieren")
+	}
+
+	cal := NewCalendar()
+	now := time.Now()
+
+	for _, a := range appointments {
+		ev, err := EncodeAppointment(a, now)
+		if err != nil {
+			return err
+		}
+		cal.Children = append(cal.Children, ev)
+	}
+	for _, t := range tasks {
+		cal.Children = append(cal.Children, EncodeTask(t, now))
+	}
+
+	if err := ical.NewEncoder(w).Encode(cal); err != nil {
+		return fmt.Errorf("Fehler beim Schreiben der iCalendar-Datei: %v", err)
+	}
+	return nil
+}
+
+// NewCalendar liefert einen leeren Kalender mit VERSION und PRODID
+func NewCalendar() *ical.Calendar {
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, productID)
+	return cal
+}
+
+// UID liefert die UID eines Eintrags; hier angelegte Einträge bekommen
+// eine aus ihrer ID abgeleitete, die Import wiedererkennt
+func UID(uid, table string, id int64) string {
+	if uid != "" {
+		return uid
+	}
+	return store.DerivedUID(table, id)
+}
+
+// EncodeAppointment wandelt einen Termin in ein VEVENT um
+func EncodeAppointment(a store.Appointment, stamp time.Time) (*ical.Component, error) {
+	ev := ical.NewEvent()
+	ev.Props.SetText(ical.PropUID, UID(a.UID, store.TableAppointments, a.ID))
+	ev.Props.SetDateTime(ical.PropDateTimeStamp, stamp.UTC())
+	ev.Props.SetText(ical.PropSummary, a.Title)
+	setCommon(ev.Component, a.Notes, a.Tags, a.Priority)
+
+	if a.Time == "" {
+		day, err := time.ParseInLocation("2006-01-02", a.Date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("Termin \"%s\": Ungültiges Datum %s", a.Title, a.Date)
+		}
+		ev.Props.SetDate(ical.PropDateTimeStart, day)
+		ev.Props.SetDate(ical.PropDateTimeEnd, day.AddDate(0, 0, 1))
+		return ev.Component, nil
+	}
+
+	start, err := a.Due()
+	if err != nil {
+		return nil, fmt.Errorf("Termin \"%s\": %v", a.Title, err)
+	}
+	ev.Props.SetDateTime(ical.PropDateTimeStart, start.UTC())
+	if a.EndTime != "" {
+		if end, err := time.ParseInLocation("2006-01-02 15:04", a.Date+" "+a.EndTime, time.Local); err == nil && end.After(start) {
+			ev.Props.SetDateTime(ical.PropDateTimeEnd, end.UTC())
+		}
+	}
+	for _, alarm := range a.Alarms {
+		valarm := ical.NewComponent(ical.CompAlarm)
+		valarm.Props.SetText(ical.PropAction, "DISPLAY")
+		valarm.Props.SetText(ical.PropDescription, a.Title)
+		trigger := ical.NewProp(ical.PropTrigger)
+		trigger.SetDuration(-alarm.Before)
+		valarm.Props.Set(trigger)
+		if alarm.Via != "" {
+			via := ical.NewProp(propVia)
+			via.Value = alarm.Via
+			valarm.Props.Set(via)
+		}
+		ev.Children = append(ev.Children, valarm)
+	}
+	return ev.Component, nil
+}
+
+// EncodeTask wandelt eine Aufgabe in ein VTODO um
+func EncodeTask(t store.Task, stamp time.Time) *ical.Component {
+	todo := ical.NewComponent(ical.CompToDo)
+	todo.Props.SetText(ical.PropUID, UID(t.UID, store.TableTasks, t.ID))
+	todo.Props.SetDateTime(ical.PropDateTimeStamp, stamp.UTC())
+	todo.Props.SetText(ical.PropSummary, t.Title)
+	setCommon(todo, t.Notes, t.Tags, t.Priority)
+	if due, err := time.ParseInLocation("2006-01-02", t.DueDate, time.Local); err == nil {
+		todo.Props.SetDate(ical.PropDue, due)
+	}
+	if t.Completed {
+		todo.Props.SetText(ical.PropStatus, "COMPLETED")
+	} else {
+		todo.Props.SetText(ical.PropStatus, "NEEDS-ACTION")
+	}
+	return todo
+}
+
+func setCommon(c *ical.Component, notes string, tags []string, p *int) {
+	if notes != "" {
+		c.Props.SetText(ical.PropDescription, notes)
+	}
+	if len(tags) > 0 {
+		prop := ical.NewProp(ical.PropCategories)
+		prop.SetTextList(tags)
+		c.Props.Set(prop)
+	}
+	if v := encodePriority(p); v > 0 {
+		prop := ical.NewProp(ical.PropPriority)
+		prop.Value = fmt.Sprint(v)
+		c.Props.Set(prop)
+	}
+}
@@ -0,0 +1,138 @@
+package ics
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+)
+
+func level(v int) *int { return &v }
+
+// roundTrip schreibt Termine und Aufgaben als .ics und liest sie wieder ein
+func roundTrip(t *testing.T, appointments []store.Appointment, tasks []store.Task) *Data {
+	t.Helper()
+	cal := NewCalendar()
+	stamp := time.Now()
+	for _, a := range appointments {
+		ev, err := EncodeAppointment(a, stamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cal.Children = append(cal.Children, ev)
+	}
+	for _, task := range tasks {
+		cal.Children = append(cal.Children, EncodeTask(task, stamp))
+	}
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+	data, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Errors) > 0 {
+		t.Fatal(data.Errors)
+	}
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	appointments := []store.Appointment{
+		{ID: 1, Title: "Zahnarzt", Date: "2030-03-14", Time: "09:30", EndTime: "10:15", Priority: level(priority.High),
+			Notes: "Karte mitbringen\nZweite Zeile; mit Komma, und Semikolon", Tags: []string{"arzt", "gesundheit"},
+			Alarms: []priority.Alarm{{Before: 24 * time.Hour, Via: "mail"}, {Before: 15 * time.Minute}}},
+		{ID: 2, Title: "Urlaub", Date: "2030-07-01", Priority: level(priority.Low), UID: "fremd@example.com"},
+		{ID: 3, Title: "Mitternacht", Date: "2030-12-31", Time: "23:30", EndTime: "00:30", Priority: level(priority.Critical)},
+	}
+	tasks := []store.Task{
+		{ID: 1, Title: "Steuererklärung", DueDate: "2030-05-31", Priority: level(priority.Normal), Tags: []string{"büro"}},
+		{ID: 2, Title: "Einkaufen", Completed: true, Notes: "Milch"},
+	}
+	data := roundTrip(t, appointments, tasks)
+
+	// IDs und Sync-Zustand gehören nicht zur Datei; ohne UID wird eine abgeleitet
+	want := make([]store.Appointment, len(appointments))
+	for i, a := range appointments {
+		a.UID = UID(a.UID, store.TableAppointments, a.ID)
+		a.ID = 0
+		want[i] = a
+	}
+	// Ein Ende vor dem Beginn (nach Mitternacht) geht verloren
+	want[2].EndTime = ""
+	if !reflect.DeepEqual(data.Appointments, want) {
+		t.Errorf("Termine:\n%+v\nerwartet\n%+v", data.Appointments, want)
+	}
+
+	wantTasks := make([]store.Task, len(tasks))
+	for i, task := range tasks {
+		task.UID = UID(task.UID, store.TableTasks, task.ID)
+		task.ID = 0
+		wantTasks[i] = task
+	}
+	if !reflect.DeepEqual(data.Tasks, wantTasks) {
+		t.Errorf("Aufgaben:\n%+v\nerwartet\n%+v", data.Tasks, wantTasks)
+	}
+
+	if _, err := EncodeAppointment(store.Appointment{Title: "Kaputt", Date: "14.03.2030"}, time.Now()); err == nil ||
+		!strings.Contains(err.Error(), "Kaputt") {
+		t.Errorf("Ungültiges Datum: %v", err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	open := func() *store.Store {
+		s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+	from, to := open(), open()
+
+	var buf bytes.Buffer
+	if err := Export(from, &buf); err == nil {
+		t.Error("Leerer Export ohne Fehler")
+	}
+	a := store.Appointment{Title: "Zahnarzt", Date: "2030-03-14", Time: "09:30"}
+	if err := from.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	task := store.Task{Title: "Einkaufen", DueDate: "2030-03-15"}
+	if err := from.AddTask(&task); err != nil {
+		t.Fatal(err)
+	}
+	if err := Export(from, &buf); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+
+	result, err := Import(to, strings.NewReader(exported))
+	if err != nil || result.Appointments != 1 || result.Tasks != 1 || result.Duplicates != 0 {
+		t.Fatalf("Import: %v, %v", result, err)
+	}
+	got, err := to.QueryAppointments(store.Filter{})
+	if err != nil || len(got) != 1 || got[0].Title != "Zahnarzt" || got[0].Time != "09:30" {
+		t.Errorf("Importierte Termine: %+v, %v", got, err)
+	}
+
+	// Ein zweiter Import erkennt die Einträge an ihrer UID
+	result, err = Import(to, strings.NewReader(exported))
+	if err != nil || result.Appointments+result.Tasks != 0 || result.Duplicates != 2 {
+		t.Errorf("Zweiter Import: %v, %v", result, err)
+	}
+	// Auch in die Ausgangsdatenbank zurück
+	result, err = Import(from, strings.NewReader(exported))
+	if err != nil || result.Duplicates != 2 {
+		t.Errorf("Import in die Ausgangsdatenbank: %v, %v", result, err)
+	}
+}
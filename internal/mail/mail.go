@@ -0,0 +1,195 @@
+// Package mail versendet E-Mails über den SMTP-Server aus der Konfiguration
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+)
+
+// Höchstdauer für Verbindung und Versand einer E-Mail
+const timeout = 30 * time.Second
+
+// Attachment ist ein Anhang einer E-Mail
+type Attachment struct {
+	Name        string
+	ContentType string // z.B. "text/calendar; method=PUBLISH"
+	Data        []byte
+}
+
+// Message ist eine E-Mail an die Empfänger aus der Konfiguration
+type Message struct {
+	Subject     string
+	Body        string // Text ohne Formatierung
+	Attachments []Attachment
+}
+
+// Send versendet m an alle Empfänger aus cfg. Bei security = starttls wird
+// ohne STARTTLS nicht gesendet.
+func Send(cfg config.Mail, m Message) error {
+	if !cfg.Enabled() {
+		return fmt.Errorf("Kein SMTP-Server eingerichtet")
+	}
+	data, err := m.build(cfg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("Ungültiger Absender: %v", err)
+	}
+
+	addr := cfg.Address()
+	dialer := &net.Dialer{Timeout: timeout}
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	var conn net.Conn
+	if cfg.Security == config.MailTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("SMTP-Server %s nicht erreichbar: %v", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("Fehler beim Verbinden mit %s: %v", addr, err)
+	}
+	defer c.Close()
+
+	if cfg.Security == config.MailSTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s unterstützt kein STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("Fehler bei STARTTLS: %v", err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("Anmeldung am SMTP-Server fehlgeschlagen: %v", err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return fmt.Errorf("Absender abgelehnt: %v", err)
+	}
+	for _, to := range cfg.To {
+		rcpt, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("Ungültiger Empfänger %q: %v", to, err)
+		}
+		if err := c.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("Empfänger %s abgelehnt: %v", rcpt.Address, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("Fehler beim Senden der E-Mail: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("Fehler beim Senden der E-Mail: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("E-Mail nicht angenommen: %v", err)
+	}
+	return c.Quit()
+}
+
+// build setzt die E-Mail mit Kopfzeilen als MIME-Nachricht zusammen
+func (m Message) build(cfg config.Mail, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", cfg.From)
+	header("To", strings.Join(cfg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(cfg.From))
+	header("MIME-Version", "1.0")
+	header("User-Agent", "Reminder-Erinnerungs-App")
+
+	if len(m.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeText(&buf, m.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mp := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mp.Boundary())
+	buf.WriteString("\r\n")
+
+	part, err := mp.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeText(part, m.Body); err != nil {
+		return nil, err
+	}
+	for _, a := range m.Attachments {
+		part, err := mp.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; charset=utf-8; name=%q", a.ContentType, a.Name)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", a.Name)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+	if err := mp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeText(w io.Writer, text string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID erzeugt eine eindeutige Message-ID mit der Domain des Absenders
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
@@ -0,0 +1,270 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+)
+
+// smtpServer ist ein SMTP-Server für Tests, der eine E-Mail je Verbindung annimmt
+type smtpServer struct {
+	starttls bool   // STARTTLS anbieten
+	login    string // erwartete Anmeldung "Benutzer:Passwort", leer = ohne
+	reject   string // abgelehnter Empfänger
+
+	mu       sync.Mutex
+	from     string
+	to       []string
+	data     string
+	commands []string
+}
+
+func (s *smtpServer) start(t *testing.T, useTLS bool) config.Mail {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if useTLS {
+		l = tls.NewListener(l, &tls.Config{Certificates: []tls.Certificate{selfSigned(t)}})
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(t, conn)
+		}
+	}()
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return config.Mail{Host: host, Port: p, Security: config.MailNone, From: "App <app@example.com>", To: []string{"a@example.com", "B <b@example.com>"}}
+}
+
+func (s *smtpServer) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 test ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		cmd = strings.ToUpper(cmd)
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		s.mu.Unlock()
+
+		switch cmd {
+		case "EHLO":
+			ext := []string{"test"}
+			if s.starttls {
+				ext = append(ext, "STARTTLS")
+			}
+			if s.login != "" {
+				ext = append(ext, "AUTH PLAIN")
+			}
+			for _, e := range ext {
+				tc.PrintfLine("250-%s", e)
+			}
+			tc.PrintfLine("250 8BITMIME")
+		case "STARTTLS":
+			tc.PrintfLine("220 Bereit")
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{selfSigned(t)}})
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tc = textproto.NewConn(conn)
+		case "AUTH":
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			if strings.ReplaceAll(strings.TrimPrefix(string(decoded), "\x00"), "\x00", ":") != s.login {
+				tc.PrintfLine("535 Zugangsdaten falsch")
+				continue
+			}
+			tc.PrintfLine("235 Angemeldet")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = address(arg)
+			s.mu.Unlock()
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			to := address(arg)
+			if to == s.reject {
+				tc.PrintfLine("550 Unbekannt")
+				continue
+			}
+			s.mu.Lock()
+			s.to = append(s.to, to)
+			s.mu.Unlock()
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 Los")
+			data, err := io.ReadAll(tc.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			tc.PrintfLine("250 Angenommen")
+		case "QUIT":
+			tc.PrintfLine("221 Tschüss")
+			return
+		default:
+			tc.PrintfLine("502 Unbekannt")
+		}
+	}
+}
+
+// address liefert die Adresse aus "FROM:<…> BODY=8BITMIME"
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, "<")
+	addr, _, _ = strings.Cut(addr, ">")
+	return addr
+}
+
+// received liefert Absender, Empfänger, Inhalt und alle Befehle
+func (s *smtpServer) received() (string, []string, string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.from, s.to, s.data, s.commands
+}
+
+// selfSigned erzeugt ein Zertifikat, dem der Client nicht vertraut
+func selfSigned(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Error(err)
+		return tls.Certificate{}
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Error(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSend(t *testing.T) {
+	srv := &smtpServer{}
+	cfg := srv.start(t, false)
+	m := Message{
+		Subject:     "Erinnerung: Müll rausbringen",
+		Body:        "Heute ist Abholung.",
+		Attachments: []Attachment{{Name: "termin.ics", ContentType: "text/calendar", Data: []byte("BEGIN:VCALENDAR")}},
+	}
+	if err := Send(cfg, m); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to, data, _ := srv.received()
+	if from != "app@example.com" {
+		t.Errorf("Absender %q", from)
+	}
+	if strings.Join(to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("Empfänger %v", to)
+	}
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Get("Subject"); got != "=?utf-8?q?Erinnerung:_M=C3=BCll_rausbringen?=" {
+		t.Errorf("Betreff %q", got)
+	}
+	for _, want := range []string{"Heute ist Abholung.", `filename="termin.ics"`, base64.StdEncoding.EncodeToString([]byte("BEGIN:VCALENDAR"))} {
+		if !strings.Contains(data, want) {
+			t.Errorf("%q fehlt in der E-Mail:\n%s", want, data)
+		}
+	}
+}
+
+func TestSendAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string
+	}{
+		{"richtig", "geheim", ""},
+		{"falsch", "falsch", "Anmeldung am SMTP-Server fehlgeschlagen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &smtpServer{login: "app:geheim"}
+			cfg := srv.start(t, false)
+			cfg.Username, cfg.Password = "app", tt.password
+			err := Send(cfg, Message{Subject: "Test"})
+			_, _, data, _ := srv.received()
+			if tt.wantErr == "" {
+				if err != nil || data == "" {
+					t.Errorf("Nicht gesendet: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fehler %v, erwartet %q", err, tt.wantErr)
+			}
+			if data != "" {
+				t.Error("Trotz fehlgeschlagener Anmeldung gesendet")
+			}
+		})
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   *smtpServer
+		useTLS   bool
+		security string
+		wantErr  string
+	}{
+		{"ohne STARTTLS", &smtpServer{}, false, config.MailSTARTTLS, "unterstützt kein STARTTLS"},
+		// Das Zertifikat des Servers ist nicht vertrauenswürdig
+		{"STARTTLS", &smtpServer{starttls: true}, false, config.MailSTARTTLS, "Fehler bei STARTTLS"},
+		{"TLS", &smtpServer{}, true, config.MailTLS, "nicht erreichbar"},
+		{"Empfänger", &smtpServer{reject: "b@example.com"}, false, config.MailNone, "Empfänger b@example.com abgelehnt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := tt.server
+			cfg := srv.start(t, tt.useTLS)
+			cfg.Security = tt.security
+			err := Send(cfg, Message{Subject: "Test"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fehler %v, erwartet %q", err, tt.wantErr)
+			}
+			if _, _, data, commands := srv.received(); data != "" {
+				t.Errorf("Trotz Fehler gesendet, Befehle %v", commands)
+			}
+		})
+	}
+
+	if err := Send(config.Mail{}, Message{}); err == nil {
+		t.Error("Ohne SMTP-Server kein Fehler")
+	}
+}
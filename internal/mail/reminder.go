@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/ics"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"github.com/emersion/go-ical"
+)
+
+// Texte der E-Mails je Sprache
+type mailText struct {
+	subject    *template.Template
+	body       *template.Template
+	dateLayout string
+	attachment string
+}
+
+var mailTexts = map[string]mailText{
+	"de": {
+		subject: template.Must(template.New("subject").Parse(
+			`Erinnerung: {{.Title}}{{if .Time}} um {{.Time}}{{end}}`)),
+		body: template.Must(template.New("body").Parse(
+			`{{if .Minutes}}In {{.Minutes}} Minuten beginnt:{{else}}Jetzt beginnt:{{end}}
+
+{{.Title}}
+Datum: {{.Date}}
+Zeit: {{.Time}}{{if .EndTime}} bis {{.EndTime}}{{end}}
+{{if .Priority}}Priorität: {{.Priority}}
+{{end}}{{if .Tags}}Tags: {{.Tags}}
+{{end}}{{if .Notes}}
+{{.Notes}}
+{{end}}
+--
+Reminder-Erinnerungs-App
+`)),
+		dateLayout: "02.01.2006",
+		attachment: "termin.ics",
+	},
+	"en": {
+		subject: template.Must(template.New("subject").Parse(
+			`Reminder: {{.Title}}{{if .Time}} at {{.Time}}{{end}}`)),
+		body: template.Must(template.New("body").Parse(
+			`{{if .Minutes}}Starting in {{.Minutes}} minutes:{{else}}Starting now:{{end}}
+
+{{.Title}}
+Date: {{.Date}}
+Time: {{.Time}}{{if .EndTime}} to {{.EndTime}}{{end}}
+{{if .Priority}}Priority: {{.Priority}}
+{{end}}{{if .Tags}}Tags: {{.Tags}}
+{{end}}{{if .Notes}}
+{{.Notes}}
+{{end}}
+--
+Reminder-Erinnerungs-App
+`)),
+		dateLayout: "Mon, Jan 2 2006",
+		attachment: "appointment.ics",
+	},
+}
+
+// Werte für die Vorlagen
+type reminderData struct {
+	Title, Date, Time, EndTime string
+	Minutes                    int
+	Priority, Tags, Notes      string
+}
+
+// Reminder baut die Erinnerung an einen Termin in der Sprache language (de
+// oder en, auch "en_US") mit dem Termin als .ics-Anhang. priority ist der Name der
+// Prioritätsstufe, minutes der Abstand zum Termin (0 = zum Termin).
+func Reminder(language string, a store.Appointment, priority string, minutes int) (Message, error) {
+	language, _, _ = strings.Cut(strings.ToLower(language), "_")
+	text, ok := mailTexts[language]
+	if !ok {
+		text = mailTexts["de"]
+	}
+	data := reminderData{
+		Title: a.Title, Date: a.Date, Time: a.Time, EndTime: a.EndTime,
+		Minutes: minutes, Priority: priority, Tags: strings.Join(a.Tags, ", "), Notes: a.Notes,
+	}
+	if date, err := time.Parse("2006-01-02", a.Date); err == nil {
+		data.Date = date.Format(text.dateLayout)
+	}
+	var subject, body bytes.Buffer
+	if err := text.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := text.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	m := Message{Subject: subject.String(), Body: body.String()}
+
+	// Ohne Uhrzeit lässt sich kein Termin für den Kalender erzeugen
+	event, err := ics.EncodeAppointment(a, time.Now())
+	if err != nil {
+		return m, nil
+	}
+	cal := ics.NewCalendar()
+	cal.Props.SetText(ical.PropMethod, "PUBLISH")
+	cal.Children = append(cal.Children, event)
+	var attachment bytes.Buffer
+	if err := ical.NewEncoder(&attachment).Encode(cal); err != nil {
+		return m, nil
+	}
+	m.Attachments = []Attachment{{
+		Name:        text.attachment,
+		ContentType: "text/calendar; method=PUBLISH",
+		Data:        attachment.Bytes(),
+	}}
+	return m, nil
+}
@@ -0,0 +1,171 @@
+package paths
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const appDir = "reminder-app"
+
+// Frühere Programmversionen legten die Datenbank im Arbeitsverzeichnis an
+const legacyDB = "reminder.db"
+
+// DataDir liefert $XDG_DATA_HOME/reminder-app bzw. ~/.local/share/reminder-app
+func DataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appDir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".local", "share", appDir)
+}
+
+// DefaultDB ist der Pfad der Datenbank, wenn keiner konfiguriert ist
+func DefaultDB() string {
+	return filepath.Join(DataDir(), "reminder.db")
+}
+
+// Database liefert den absoluten Datenbankpfad. Ohne Angabe wird DefaultDB
+// verwendet; liegt dort noch keine Datenbank, wird eine vorhandene
+// ./reminder.db einmalig dorthin verschoben.
+func Database(configured string) (string, error) {
+	if configured != "" {
+		return filepath.Abs(configured)
+	}
+	path := DefaultDB()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("Fehler beim Anlegen von %s: %v", filepath.Dir(path), err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := migrateLegacy(path); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// migrateLegacy verschiebt ./reminder.db samt Journaldateien nach target
+func migrateLegacy(target string) error {
+	legacy, err := filepath.Abs(legacyDB)
+	if err != nil || legacy == target {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		if _, err := os.Stat(legacy + suffix); err != nil {
+			continue
+		}
+		if err := move(legacy+suffix, target+suffix); err != nil {
+			return fmt.Errorf("Fehler beim Verschieben der Datenbank nach %s: %v", target, err)
+		}
+	}
+	log.Printf("Datenbank %s nach %s verschoben", legacy, target)
+	return nil
+}
+
+// move benennt um und kopiert, wenn das Ziel auf einem anderen Dateisystem liegt
+func move(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+// runtimeDir nimmt die Anmeldungen laufender Programme auf
+func runtimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, appDir)
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", appDir, os.Getuid()))
+}
+
+// Socket ist der Unix-Socket der Schnittstelle von reminderd
+func Socket() string {
+	return filepath.Join(runtimeDir(), "reminderd.sock")
+}
+
+// APIToken ist die Datei mit dem erzeugten Token für die Schnittstelle
+func APIToken() string {
+	return filepath.Join(DataDir(), "api-token")
+}
+
+// Register meldet ein laufendes Programm (z.B. "reminderd") mit seiner
+// Datenbank an, damit andere Programme abweichende Pfade bemerken. Die
+// zurückgegebene Funktion meldet es wieder ab.
+func Register(name, dbPath string) (func(), error) {
+	dir := runtimeDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return func() {}, err
+	}
+	file := filepath.Join(dir, name)
+	content := fmt.Sprintf("%d\n%s\n", os.Getpid(), dbPath)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		return func() {}, err
+	}
+	return func() { os.Remove(file) }, nil
+}
+
+// Registered liefert die Datenbank eines laufenden, angemeldeten Programms
+func Registered(name string) (dbPath string, running bool) {
+	data, err := os.ReadFile(filepath.Join(runtimeDir(), name))
+	if err != nil {
+		return "", false
+	}
+	pidText, path, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(pidText)
+	if err != nil || pid == os.Getpid() {
+		return "", false
+	}
+	// Signal 0 prüft nur, ob der Prozess noch existiert
+	if p, err := os.FindProcess(pid); err != nil || p.Signal(syscall.Signal(0)) != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// CheckShared vergleicht die eigene Datenbank mit der eines anderen
+// laufenden Programms und liefert eine Warnung, wenn sie sich unterscheiden
+func CheckShared(other, dbPath string) string {
+	otherPath, running := Registered(other)
+	if !running || sameFile(otherPath, dbPath) {
+		return ""
+	}
+	return fmt.Sprintf("%s verwendet eine andere Datenbank:\n%s\nDieses Programm verwendet:\n%s\n"+
+		"GUI und Daemon sehen so nicht dieselben Termine.", other, otherPath, dbPath)
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return os.SameFile(infoA, infoB)
+}
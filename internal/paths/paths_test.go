@@ -0,0 +1,165 @@
+package paths
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// chdir wechselt für die Dauer des Tests in dir
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestDatabase(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	work := t.TempDir()
+	chdir(t, work)
+
+	// Ein angegebener Pfad wird nur absolut gemacht
+	if got, err := Database("eigene.db"); err != nil || got != filepath.Join(work, "eigene.db") {
+		t.Errorf("Angegebener Pfad: %s, %v", got, err)
+	}
+
+	// Die alte Datenbank wird samt Journaldateien verschoben
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.WriteFile(legacyDB+suffix, []byte("alt"+suffix), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := filepath.Join(data, appDir, "reminder.db")
+	got, err := Database("")
+	if err != nil || got != want {
+		t.Fatalf("Standardpfad: %s, %v, erwartet %s", got, err, want)
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if content, err := os.ReadFile(want + suffix); err != nil || string(content) != "alt"+suffix {
+			t.Errorf("%s: %q, %v", want+suffix, content, err)
+		}
+		if _, err := os.Stat(legacyDB + suffix); !os.IsNotExist(err) {
+			t.Errorf("%s nicht verschoben", legacyDB+suffix)
+		}
+	}
+	if _, err := os.Stat(want + "-journal"); !os.IsNotExist(err) {
+		t.Error("Nicht vorhandenes Journal angelegt")
+	}
+
+	// Liegt schon eine Datenbank am Standardpfad, bleibt die alte liegen
+	if err := os.WriteFile(legacyDB, []byte("neuer"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Database(""); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(want); string(content) != "alt" {
+		t.Errorf("Datenbank überschrieben: %q", content)
+	}
+	if _, err := os.Stat(legacyDB); err != nil {
+		t.Errorf("Alte Datenbank verschoben: %v", err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.WriteFile(from, []byte("inhalt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := move(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(to); err != nil || string(content) != "inhalt" {
+		t.Errorf("Ziel: %q, %v", content, err)
+	}
+	if err := move(from, filepath.Join(dir, "c")); err == nil {
+		t.Error("Fehlende Datei ohne Fehler verschoben")
+	}
+}
+
+// register meldet name so an, als liefe es als Prozess pid
+func register(t *testing.T, name string, pid int, dbPath string) {
+	t.Helper()
+	content := strconv.Itoa(pid) + "\n" + dbPath + "\n"
+	if err := os.WriteFile(filepath.Join(runtimeDir(), name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	run := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", run)
+	dir := t.TempDir()
+	db := filepath.Join(dir, "reminder.db")
+	other := filepath.Join(dir, "andere.db")
+	for _, path := range []string{db, other} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Das eigene Programm gilt nicht als anderes laufendes Programm
+	unregister, err := Register("reminderd", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(run, appDir)); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("Verzeichnis: %v, %v", info, err)
+	}
+	if _, running := Registered("reminderd"); running {
+		t.Error("Eigener Prozess als laufend gemeldet")
+	}
+	unregister()
+	if _, err := os.Stat(filepath.Join(run, appDir, "reminderd")); !os.IsNotExist(err) {
+		t.Errorf("Nach dem Abmelden: %v", err)
+	}
+	if _, running := Registered("reminderd"); running {
+		t.Error("Abgemeldetes Programm als laufend gemeldet")
+	}
+
+	// Der Elternprozess läuft sicher noch
+	register(t, "reminderd", os.Getppid(), db)
+	if path, running := Registered("reminderd"); !running || path != db {
+		t.Errorf("Laufendes Programm: %s, %v", path, running)
+	}
+	if warning := CheckShared("reminderd", db); warning != "" {
+		t.Errorf("Warnung bei gleicher Datenbank: %s", warning)
+	}
+	if warning := CheckShared("reminderd", filepath.Join(dir, ".", "reminder.db")); warning != "" {
+		t.Errorf("Warnung bei gleicher Datenbank über anderen Pfad: %s", warning)
+	}
+	if warning := CheckShared("reminderd", other); !strings.Contains(warning, db) || !strings.Contains(warning, other) {
+		t.Errorf("Warnung bei anderer Datenbank: %q", warning)
+	}
+
+	// Ein beendeter Prozess gilt nicht als laufend
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	register(t, "reminderd", cmd.Process.Pid, db)
+	if _, running := Registered("reminderd"); running {
+		t.Error("Beendetes Programm als laufend gemeldet")
+	}
+	if warning := CheckShared("reminderd", other); warning != "" {
+		t.Errorf("Warnung ohne laufendes Programm: %s", warning)
+	}
+
+	if err := os.WriteFile(filepath.Join(runtimeDir(), "reminderd"), []byte("kaputt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, running := Registered("reminderd"); running {
+		t.Error("Unlesbare Anmeldung als laufend gemeldet")
+	}
+}
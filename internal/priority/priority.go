@@ -0,0 +1,216 @@
+package priority
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Werte der Spalte priority in appointments und tasks
+const (
+	Low      = 1
+	Normal   = 2
+	High     = 3
+	Critical = 4
+)
+
+// Dringlichkeitsstufen für Desktop-Benachrichtigungen (notify-send --urgency)
+const (
+	UrgencyLow      = "low"
+	UrgencyNormal   = "normal"
+	UrgencyCritical = "critical"
+)
+
+// Eskalationsstufen beim Wiederholen unbestätigter Erinnerungen
+const (
+	StepPopup   = "popup"   // Erinnerung erneut anzeigen
+	StepSound   = "sound"   // zusätzlich einen Alarmton abspielen
+	StepOverlay = "overlay" // bildschirmfüllender Hinweis
+)
+
+// Level beschreibt eine Prioritätsstufe und wie Erinnerungen dafür ablaufen
+type Level struct {
+	Value       int
+	Name        string
+	Urgency     string        // Dringlichkeit der Benachrichtigung
+	Sticky      bool          // Erinnerungsdialog bleibt offen, bis er bestätigt wird
+	NagInterval time.Duration // > 0: Erinnerung wiederholen, bis sie bestätigt wird
+	Escalation  []string      // Ablauf der Wiederholungen, die letzte Stufe bleibt bestehen
+	EarlyAlarms []Alarm       // Zusätzliche Vorwarnungen vor dem Termin
+	SortWeight  int           // Gewicht beim Sortieren nach Priorität
+	Sound       string        // Tondatei (WAV/OGG), leer = eingebauter Signalton
+}
+
+// None ist das Verhalten für Einträge ohne Priorität
+var None = Level{Name: "Keine", Urgency: UrgencyNormal}
+
+// Defaults liefert die Standardstufen, mit denen eine neue Datenbank angelegt wird
+func Defaults() Set {
+	return Set{
+		{Value: Low, Name: "Niedrig", Urgency: UrgencyLow, SortWeight: 10},
+		{Value: Normal, Name: "Normal", Urgency: UrgencyNormal, SortWeight: 20},
+		{Value: High, Name: "Hoch", Urgency: UrgencyCritical, Sticky: true,
+			EarlyAlarms: []Alarm{{Before: 30 * time.Minute}}, SortWeight: 30},
+		{Value: Critical, Name: "Kritisch", Urgency: UrgencyCritical, Sticky: true,
+			NagInterval: 5 * time.Minute,
+			Escalation:  []string{StepPopup, StepSound, StepOverlay},
+			EarlyAlarms: []Alarm{{Before: time.Hour}, {Before: 15 * time.Minute}}, SortWeight: 40},
+	}
+}
+
+// Set ist die Menge aller bekannten Stufen, aufsteigend nach Value
+type Set []Level
+
+// Lookup liefert die Stufe zu einem Prioritätswert; unbekannte Werte
+// bekommen das Verhalten von None, behalten aber ihre Zahl als Namen
+func (s Set) Lookup(p *int) Level {
+	if p == nil {
+		return None
+	}
+	for _, l := range s {
+		if l.Value == *p {
+			return l
+		}
+	}
+	l := None
+	l.Value = *p
+	l.Name = strconv.Itoa(*p)
+	return l
+}
+
+// Names liefert die Namen aller Stufen in aufsteigender Reihenfolge
+func (s Set) Names() []string {
+	names := make([]string, len(s))
+	for i, l := range s {
+		names[i] = l.Name
+	}
+	return names
+}
+
+// Parse akzeptiert den Namen einer Stufe (ohne Groß-/Kleinschreibung) oder ihren Zahlenwert
+func (s Set) Parse(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	for _, l := range s {
+		if strings.EqualFold(l.Name, name) {
+			return l.Value, nil
+		}
+	}
+	if v, err := strconv.Atoi(name); err == nil {
+		return v, nil
+	}
+	return 0, fmt.Errorf("Unbekannte Priorität: %s", name)
+}
+
+// Steps liefert den Ablauf der Wiederholungen; ohne Angabe wird nur die
+// Erinnerung erneut angezeigt
+func (l Level) Steps() []string {
+	if len(l.Escalation) == 0 {
+		return []string{StepPopup}
+	}
+	return l.Escalation
+}
+
+// Step liefert die Eskalationsstufe für die n-te Wiederholung (ab 0)
+func (l Level) Step(n int) string {
+	steps := l.Steps()
+	if n >= len(steps) {
+		n = len(steps) - 1
+	}
+	return steps[n]
+}
+
+// ParseEscalation prüft eine kommagetrennte Liste von Eskalationsstufen
+func ParseEscalation(s string) ([]string, error) {
+	var steps []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "":
+			continue
+		case StepPopup, StepSound, StepOverlay:
+			steps = append(steps, part)
+		default:
+			return nil, fmt.Errorf("Unbekannte Eskalationsstufe: %s", part)
+		}
+	}
+	return steps, nil
+}
+
+// Alarm ist eine Vorwarnung vor dem Termin. Mit Via geht sie zusätzlich zur
+// Anzeige über diesen Benachrichtigungsweg, z.B. "mail".
+type Alarm struct {
+	Before time.Duration
+	Via    string
+}
+
+// Minutes liefert den Abstand zum Termin in Minuten
+func (a Alarm) Minutes() int {
+	return int(a.Before.Minutes())
+}
+
+// String liefert die Vorwarnung als "15" oder "15:mail"
+func (a Alarm) String() string {
+	if a.Via == "" {
+		return strconv.Itoa(a.Minutes())
+	}
+	return fmt.Sprintf("%d:%s", a.Minutes(), a.Via)
+}
+
+// ParseAlarm ist die Umkehrung von Alarm.String
+func ParseAlarm(s string) (Alarm, error) {
+	text, via, _ := strings.Cut(strings.TrimSpace(s), ":")
+	minutes, err := strconv.Atoi(strings.TrimSpace(text))
+	via = strings.TrimSpace(via)
+	if err != nil || minutes <= 0 || strings.ContainsAny(via, ", ") {
+		return Alarm{}, fmt.Errorf("Ungültige Vorwarnung: %s", s)
+	}
+	return Alarm{Before: time.Duration(minutes) * time.Minute, Via: via}, nil
+}
+
+// MarshalJSON schreibt Vorwarnungen ohne Via als Zahl wie bisher, sonst als "15:mail"
+func (a Alarm) MarshalJSON() ([]byte, error) {
+	if a.Via == "" {
+		return []byte(strconv.Itoa(a.Minutes())), nil
+	}
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON akzeptiert eine Minutenzahl oder "15:mail"
+func (a *Alarm) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	alarm, err := ParseAlarm(text)
+	if err != nil {
+		return err
+	}
+	*a = alarm
+	return nil
+}
+
+// FormatAlarms wandelt Vorwarnungen in eine kommagetrennte Minutenliste um ("60:mail,15")
+func FormatAlarms(alarms []Alarm) string {
+	parts := make([]string, len(alarms))
+	for i, a := range alarms {
+		parts[i] = a.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseAlarms ist die Umkehrung von FormatAlarms
+func ParseAlarms(s string) ([]Alarm, error) {
+	var alarms []Alarm
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		alarm, err := ParseAlarm(part)
+		if err != nil {
+			return nil, err
+		}
+		alarms = append(alarms, alarm)
+	}
+	return alarms, nil
+}
@@ -0,0 +1,179 @@
+// Package push sendet Benachrichtigungen über selbst betriebene Dienste
+// (ntfy, Gotify, Matrix) aufs Handy
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/priority"
+)
+
+// Höchstdauer einer Anfrage an einen Dienst
+const timeout = 15 * time.Second
+
+// Dringlichkeit einer Nachricht, wird auf die Stufen des Dienstes abgebildet
+const (
+	Low = iota
+	Normal
+	High
+	Urgent
+)
+
+// ForLevel bildet eine Prioritätsstufe auf die Dringlichkeit ab
+func ForLevel(level priority.Level) int {
+	switch {
+	case level.Value >= priority.Critical:
+		return Urgent
+	case level.Urgency == priority.UrgencyCritical:
+		return High
+	case level.Urgency == priority.UrgencyLow:
+		return Low
+	}
+	return Normal
+}
+
+// Message ist eine Benachrichtigung
+type Message struct {
+	Title    string
+	Body     string
+	Priority int // Low, Normal, High oder Urgent
+}
+
+// Sender ist ein Dienst, an den Benachrichtigungen gehen
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// send schickt body als JSON und prüft die Antwort
+func send(ctx context.Context, method, target string, header http.Header, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Reminder-Erinnerungs-App")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("Antwort %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return nil
+}
+
+func bearer(token string) http.Header {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return header
+}
+
+// Ntfy veröffentlicht Nachrichten in einem ntfy-Topic
+type Ntfy struct {
+	cfg config.Ntfy
+}
+
+// NewNtfy erstellt den Sender für ein ntfy-Topic
+func NewNtfy(cfg config.Ntfy) Ntfy {
+	return Ntfy{cfg: cfg}
+}
+
+// Stufen 1 (min) bis 5 (max) von ntfy
+var ntfyPriority = map[int]int{Low: 2, Normal: 3, High: 4, Urgent: 5}
+
+func (n Ntfy) Send(ctx context.Context, m Message) error {
+	body := map[string]interface{}{
+		"topic":    n.cfg.Topic,
+		"title":    m.Title,
+		"message":  m.Body,
+		"priority": ntfyPriority[m.Priority],
+		"tags":     []string{"alarm_clock"},
+	}
+	if err := send(ctx, http.MethodPost, strings.TrimSuffix(n.cfg.URL, "/"), bearer(n.cfg.Token), body); err != nil {
+		return fmt.Errorf("ntfy: %v", err)
+	}
+	return nil
+}
+
+// Gotify sendet Nachrichten an einen Gotify-Server
+type Gotify struct {
+	cfg config.Gotify
+}
+
+// NewGotify erstellt den Sender für eine Gotify-Anwendung
+func NewGotify(cfg config.Gotify) Gotify {
+	return Gotify{cfg: cfg}
+}
+
+// Stufen 0 bis 10 von Gotify; ab 8 klingelt die App auch im Ruhemodus
+var gotifyPriority = map[int]int{Low: 2, Normal: 5, High: 7, Urgent: 10}
+
+func (g Gotify) Send(ctx context.Context, m Message) error {
+	header := http.Header{}
+	header.Set("X-Gotify-Key", g.cfg.Token)
+	body := map[string]interface{}{
+		"title":    m.Title,
+		"message":  m.Body,
+		"priority": gotifyPriority[m.Priority],
+	}
+	if err := send(ctx, http.MethodPost, strings.TrimSuffix(g.cfg.URL, "/")+"/message", header, body); err != nil {
+		return fmt.Errorf("Gotify: %v", err)
+	}
+	return nil
+}
+
+// Matrix schreibt Nachrichten in einen Matrix-Raum
+type Matrix struct {
+	cfg config.Matrix
+}
+
+// NewMatrix erstellt den Sender für einen Matrix-Raum
+func NewMatrix(cfg config.Matrix) Matrix {
+	return Matrix{cfg: cfg}
+}
+
+func (x Matrix) Send(ctx context.Context, m Message) error {
+	// Jede Nachricht braucht eine eigene Transaktions-ID, sonst verwirft
+	// der Server sie als Wiederholung
+	b := make([]byte, 8)
+	rand.Read(b)
+	target := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%d-%s",
+		strings.TrimSuffix(x.cfg.Homeserver, "/"), url.PathEscape(x.cfg.Room),
+		time.Now().UnixMilli(), hex.EncodeToString(b))
+
+	text := m.Body
+	if m.Title != "" {
+		text = m.Title + "\n" + m.Body
+	}
+	body := map[string]string{"msgtype": "m.text", "body": text}
+	if err := send(ctx, http.MethodPut, target, bearer(x.cfg.Token), body); err != nil {
+		return fmt.Errorf("Matrix: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"Reminder_Erinnerungs_App/internal/config"
+)
+
+// request ist eine beim Testserver eingegangene Anfrage
+type request struct {
+	method, path, auth, gotifyKey string
+	body                          map[string]interface{}
+}
+
+// service nimmt Anfragen an und antwortet mit status
+type service struct {
+	mu       sync.Mutex
+	status   int
+	requests []request
+}
+
+func newService(t *testing.T, status int) (*service, string) {
+	s := &service{status: status}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv.URL
+}
+
+func (s *service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := request{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), gotifyKey: r.Header.Get("X-Gotify-Key")}
+	json.NewDecoder(r.Body).Decode(&req.body)
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	if s.status >= 300 {
+		http.Error(w, "Token ungültig", s.status)
+		return
+	}
+	w.Write([]byte(`{"id":1}`))
+}
+
+func (s *service) received(t *testing.T) []request {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func TestSend(t *testing.T) {
+	m := Message{Title: "Zahnarzt", Body: "In 15 Minuten", Priority: Urgent}
+
+	s, url := newService(t, http.StatusOK)
+	if err := NewNtfy(config.Ntfy{URL: url + "/", Topic: "erinnerungen", Token: "tk"}).Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	got := s.received(t)[0]
+	if got.method != http.MethodPost || got.path != "/" || got.auth != "Bearer tk" {
+		t.Errorf("ntfy: %s %s, Authorization %q", got.method, got.path, got.auth)
+	}
+	if got.body["topic"] != "erinnerungen" || got.body["title"] != "Zahnarzt" || got.body["priority"] != 5.0 {
+		t.Errorf("ntfy: %v", got.body)
+	}
+
+	s, url = newService(t, http.StatusOK)
+	if err := NewGotify(config.Gotify{URL: url, Token: "app"}).Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	got = s.received(t)[0]
+	if got.method != http.MethodPost || got.path != "/message" || got.gotifyKey != "app" || got.auth != "" {
+		t.Errorf("Gotify: %s %s, Key %q, Authorization %q", got.method, got.path, got.gotifyKey, got.auth)
+	}
+	if got.body["message"] != "In 15 Minuten" || got.body["priority"] != 10.0 {
+		t.Errorf("Gotify: %v", got.body)
+	}
+
+	s, url = newService(t, http.StatusOK)
+	matrix := NewMatrix(config.Matrix{Homeserver: url, Room: "!abc:example.org", Token: "mx"})
+	for i := 0; i < 2; i++ {
+		if err := matrix.Send(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requests := s.received(t)
+	prefix := "/_matrix/client/v3/rooms/!abc:example.org/send/m.room.message/"
+	for _, got := range requests {
+		if got.method != http.MethodPut || !strings.HasPrefix(got.path, prefix) || got.auth != "Bearer mx" {
+			t.Errorf("Matrix: %s %s, Authorization %q", got.method, got.path, got.auth)
+		}
+		if got.body["msgtype"] != "m.text" || got.body["body"] != "Zahnarzt\nIn 15 Minuten" {
+			t.Errorf("Matrix: %v", got.body)
+		}
+	}
+	if requests[0].path == requests[1].path {
+		t.Error("Matrix: gleiche Transaktions-ID für zwei Nachrichten")
+	}
+}
+
+func TestSendError(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusInternalServerError} {
+		_, url := newService(t, status)
+		senders := map[string]Sender{
+			"ntfy":   NewNtfy(config.Ntfy{URL: url, Topic: "erinnerungen"}),
+			"Gotify": NewGotify(config.Gotify{URL: url}),
+			"Matrix": NewMatrix(config.Matrix{Homeserver: url, Room: "!abc:example.org"}),
+		}
+		for name, sender := range senders {
+			err := sender.Send(context.Background(), Message{Title: "Test"})
+			if err == nil {
+				t.Errorf("%s: kein Fehler bei %d", name, status)
+				continue
+			}
+			if msg := err.Error(); !strings.HasPrefix(msg, name+":") || !strings.Contains(msg, http.StatusText(status)) ||
+				!strings.Contains(msg, "Token ungültig") {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+
+	// Nicht erreichbar
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	if err := NewGotify(config.Gotify{URL: srv.URL}).Send(context.Background(), Message{}); err == nil {
+		t.Error("Gotify: kein Fehler, obwohl der Server nicht läuft")
+	}
+}
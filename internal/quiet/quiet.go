@@ -0,0 +1,58 @@
+package quiet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Hours ist ein täglicher Zeitraum, z.B. 22:00-07:00. Liegt End vor Start,
+// reicht der Zeitraum über Mitternacht.
+type Hours struct {
+	Start, End time.Duration // seit Mitternacht
+}
+
+// Parse liest einen Zeitraum im Format "HH:MM-HH:MM"; ein leerer String
+// bedeutet keine Ruhezeit
+func Parse(s string) (Hours, error) {
+	if s == "" {
+		return Hours{}, nil
+	}
+	var h1, m1, h2, m2 int
+	if _, err := fmt.Sscanf(s, "%d:%d-%d:%d", &h1, &m1, &h2, &m2); err != nil ||
+		h1 > 23 || h2 > 23 || m1 > 59 || m2 > 59 || h1 < 0 || h2 < 0 || m1 < 0 || m2 < 0 {
+		return Hours{}, fmt.Errorf("Ungültige Ruhezeit: %s (erwartet HH:MM-HH:MM)", s)
+	}
+	return Hours{
+		Start: time.Duration(h1)*time.Hour + time.Duration(m1)*time.Minute,
+		End:   time.Duration(h2)*time.Hour + time.Duration(m2)*time.Minute,
+	}, nil
+}
+
+// IsZero gibt an, ob keine Ruhezeit festgelegt ist
+func (h Hours) IsZero() bool {
+	return h.Start == h.End
+}
+
+// Contains prüft, ob t in der Ruhezeit liegt
+func (h Hours) Contains(t time.Time) bool {
+	if h.IsZero() {
+		return false
+	}
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if h.Start < h.End {
+		return d >= h.Start && d < h.End
+	}
+	return d >= h.Start || d < h.End
+}
+
+// String liefert den Zeitraum im Format von Parse
+func (h Hours) String() string {
+	if h.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s", clock(h.Start), clock(h.End))
+}
+
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
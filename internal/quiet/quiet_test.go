@@ -0,0 +1,83 @@
+package quiet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Hours
+		wantErr bool
+	}{
+		{"", Hours{}, false},
+		{"22:00-07:00", Hours{Start: 22 * time.Hour, End: 7 * time.Hour}, false},
+		{"12:30-13:45", Hours{Start: 12*time.Hour + 30*time.Minute, End: 13*time.Hour + 45*time.Minute}, false},
+		{"8:05-9:00", Hours{Start: 8*time.Hour + 5*time.Minute, End: 9 * time.Hour}, false},
+		{"22:00-22:00", Hours{Start: 22 * time.Hour, End: 22 * time.Hour}, false},
+		{"22:00", Hours{}, true},
+		{"22-07", Hours{}, true},
+		{"24:00-07:00", Hours{}, true},
+		{"22:60-07:00", Hours{}, true},
+		{"-1:00-07:00", Hours{}, true},
+		{"nachts", Hours{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q): Fehler %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, erwartet %+v", tt.in, got, tt.want)
+		}
+	}
+
+	// String liefert das Format von Parse
+	for in, want := range map[string]string{"22:00-07:00": "22:00-07:00", "8:05-9:00": "08:05-09:00", "10:00-10:00": ""} {
+		h, err := Parse(in)
+		if err != nil || h.String() != want {
+			t.Errorf("Parse(%q).String() = %q, %v, erwartet %q", in, h.String(), err, want)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", "2025-03-14 "+hhmm, time.Local)
+		return t
+	}
+	tests := []struct {
+		hours string
+		at    string
+		want  bool
+	}{
+		// über Mitternacht
+		{"22:00-07:00", "21:59", false},
+		{"22:00-07:00", "22:00", true},
+		{"22:00-07:00", "23:59", true},
+		{"22:00-07:00", "00:00", true},
+		{"22:00-07:00", "06:59", true},
+		{"22:00-07:00", "07:00", false},
+		{"22:00-07:00", "12:00", false},
+		// am selben Tag
+		{"12:00-13:30", "11:59", false},
+		{"12:00-13:30", "12:00", true},
+		{"12:00-13:30", "13:29", true},
+		{"12:00-13:30", "13:30", false},
+		// gleicher Anfang und gleiches Ende: keine Ruhezeit
+		{"22:00-22:00", "22:00", false},
+		{"22:00-22:00", "03:00", false},
+		{"", "03:00", false},
+	}
+	for _, tt := range tests {
+		h, err := Parse(tt.hours)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Contains(at(tt.at)); got != tt.want {
+			t.Errorf("%q enthält %s: %v, erwartet %v", tt.hours, tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package reminder
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Nicht stören: "on" oder das Ende im RFC3339-Format; leer = aus. Liegt in
+// der Datenbank, damit GUI, Daemon und reminderctl denselben Stand sehen.
+const SettingDoNotDisturb = "dnd"
+
+const dndOn = "on"
+
+// DoNotDisturb liest den manuellen Nicht-stören-Modus. until ist leer, wenn
+// der Modus unbefristet gilt; ein abgelaufener Modus gilt als aus.
+func DoNotDisturb(s *store.Store, now time.Time) (on bool, until time.Time, err error) {
+	value, err := s.Setting(SettingDoNotDisturb, "")
+	if err != nil || value == "" {
+		return false, time.Time{}, err
+	}
+	if value == dndOn {
+		return true, time.Time{}, nil
+	}
+	until, err = time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("Ungültige Einstellung %s=%s", SettingDoNotDisturb, value)
+	}
+	return now.Before(until), until, nil
+}
+
+// SetDoNotDisturb schaltet Nicht stören ein oder aus. Mit d > 0 endet der
+// Modus nach dieser Zeit von selbst.
+func SetDoNotDisturb(s store.SettingWriter, on bool, d time.Duration) error {
+	value := ""
+	if on {
+		value = dndOn
+		if d > 0 {
+			value = time.Now().Add(d).Format(time.RFC3339)
+		}
+	}
+	return s.SetSetting(SettingDoNotDisturb, value)
+}
+
+// isQuiet gibt an, ob gerade Ruhezeit oder Nicht stören gilt
+func (r *ReminderService) isQuiet(now time.Time) bool {
+	dnd, _, err := DoNotDisturb(r.store, now)
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	return dnd || r.config().quietHours.Contains(now)
+}
+
+// Eine während der Ruhezeit zurückgehaltene Erinnerung
+type heldReminder struct {
+	appointment store.Appointment
+	due         time.Time
+	minutes     int // Vorwarnung so viele Minuten vorher, 0 = zum Termin
+}
+
+// hold hält eine Erinnerung zurück, wenn gerade Ruhezeit ist. Kritische
+// Termine kommen immer durch. Läuft nur im Ticker-Goroutine.
+func (r *ReminderService) hold(quietNow bool, a store.Appointment, due time.Time, level priority.Level, minutes int) bool {
+	if !quietNow || level.Value >= priority.Critical {
+		return false
+	}
+	// Pro Termin nur die letzte Erinnerung behalten
+	r.held[a.ID] = heldReminder{appointment: a, due: due, minutes: minutes}
+	log.Printf("Ruhezeit: Erinnerung an Termin ID=%d zurückgehalten (%d Minuten vorher)", a.ID, minutes)
+	return true
+}
+
+// deliverHeld zeigt nach der Ruhezeit alle zurückgehaltenen Erinnerungen in
+// einer Zusammenfassung an. Bestätigen der Zusammenfassung bestätigt alle.
+func (r *ReminderService) deliverHeld() {
+	if len(r.held) == 0 {
+		return
+	}
+	held := make([]heldReminder, 0, len(r.held))
+	for _, h := range r.held {
+		held = append(held, h)
+	}
+	r.held = make(map[int64]heldReminder)
+
+	sort.Slice(held, func(i, j int) bool { return held[i].due.Before(held[j].due) })
+	lines := make([]string, len(held))
+	for i, h := range held {
+		timing := i18n.T("reminder.atDue")
+		if h.minutes > 0 {
+			timing = i18n.T("reminder.before", "Count", h.minutes)
+		}
+		lines[i] = fmt.Sprintf("%s  %s (%s)", dates.FormatTime(h.due.Format("15:04")), h.appointment.Title, timing)
+	}
+
+	go func() {
+		title := i18n.T("reminder.heldSummary", "Count", len(held))
+		if r.showNotification(title, strings.Join(lines, "\n"), priority.None) {
+			for _, h := range held {
+				r.acknowledge(h.appointment.ID, h.due)
+			}
+		}
+	}()
+}
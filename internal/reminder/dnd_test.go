@@ -0,0 +1,131 @@
+package reminder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/quiet"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func TestDoNotDisturb(t *testing.T) {
+	r, s, _, _ := newTestService(t)
+	now := time.Now()
+	if r.isQuiet(now) {
+		t.Fatal("Ruhe ohne Ruhezeit und Nicht stören")
+	}
+	if err := SetDoNotDisturb(s, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !r.isQuiet(now) {
+		t.Error("Nicht stören ohne Ende wirkt nicht")
+	}
+	if err := SetDoNotDisturb(s, true, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if !r.isQuiet(now) || r.isQuiet(now.Add(2*time.Hour)) {
+		t.Error("Nicht stören für eine Stunde")
+	}
+	if err := SetDoNotDisturb(s, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if r.isQuiet(now) {
+		t.Error("Nicht stören nicht ausgeschaltet")
+	}
+
+	// Ruhezeit über Mitternacht
+	hours, err := quiet.Parse("22:00-07:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.mu.Lock()
+	r.cfg.quietHours = hours
+	r.mu.Unlock()
+	night := time.Date(2030, 3, 14, 23, 30, 0, 0, time.Local)
+	if !r.isQuiet(night) || r.isQuiet(night.Add(9*time.Hour)) {
+		t.Error("Ruhezeit 22:00-07:00")
+	}
+}
+
+func TestHoldDuringQuietHours(t *testing.T) {
+	r, s, rec, _ := newTestService(t)
+	rec.acknowledge = true
+	normal := priority.Level{Value: priority.Normal, Name: "Normal"}
+	critical := priority.Level{Value: priority.Critical, Name: "Kritisch"}
+
+	var appointments []store.Appointment
+	for _, a := range []store.Appointment{
+		{Title: "Müll rausbringen", Date: "2030-03-14", Time: "06:00"},
+		{Title: "Zeitung", Date: "2030-03-14", Time: "05:30"},
+		{Title: "Flug", Date: "2030-03-14", Time: "05:45"},
+	} {
+		if err := s.AddAppointment(&a); err != nil {
+			t.Fatal(err)
+		}
+		appointments = append(appointments, a)
+	}
+	due := func(a store.Appointment) time.Time {
+		d, _ := a.Due()
+		return d
+	}
+	trash, paper, flight := appointments[0], appointments[1], appointments[2]
+
+	// Außerhalb der Ruhezeit wird nichts zurückgehalten
+	if r.hold(false, trash, due(trash), normal, 15) {
+		t.Error("Ohne Ruhezeit zurückgehalten")
+	}
+	// Kritische Termine kommen durch, die übrigen werden gesammelt
+	if r.hold(true, flight, due(flight), critical, 0) {
+		t.Error("Kritischer Termin zurückgehalten")
+	}
+	for _, h := range []struct {
+		a       store.Appointment
+		minutes int
+	}{{trash, 15}, {trash, 0}, {paper, 0}} {
+		if !r.hold(true, h.a, due(h.a), normal, h.minutes) {
+			t.Errorf("%s nicht zurückgehalten", h.a.Title)
+		}
+	}
+	rec.none(t)
+
+	// Je Termin nur die letzte Erinnerung
+	if len(r.held) != 2 || r.held[trash.ID].minutes != 0 {
+		t.Errorf("Zurückgehalten: %+v", r.held)
+	}
+	if _, ok := r.held[flight.ID]; ok {
+		t.Error("Kritischer Termin gesammelt")
+	}
+
+	// Nach der Ruhezeit eine Zusammenfassung, je Termin nur die letzte Erinnerung
+	r.deliverHeld()
+	m := rec.expect(t)
+	paperLine := strings.Index(m.Message, "05:30  Zeitung")
+	trashLine := strings.Index(m.Message, "06:00  Müll rausbringen (zum Termin)")
+	if paperLine < 0 || trashLine < paperLine || strings.Contains(m.Message, "Flug") ||
+		!strings.Contains(m.Message, "2 Erinnerungen") {
+		t.Errorf("Zusammenfassung:\n%s", m.Message)
+	}
+	rec.none(t)
+
+	// Bestätigen der Zusammenfassung bestätigt alle gesammelten Erinnerungen
+	for _, a := range []store.Appointment{trash, paper} {
+		for i := 0; ; i++ {
+			if ok, err := s.Acknowledged(a.ID, due(a)); err != nil || ok {
+				break
+			}
+			if i == 100 {
+				t.Fatalf("%s nicht bestätigt", a.Title)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if ok, _ := s.Acknowledged(flight.ID, due(flight)); ok {
+		t.Error("Durchgekommener Termin mitbestätigt")
+	}
+
+	// Danach ist nichts mehr gesammelt
+	r.deliverHeld()
+	rec.none(t)
+}
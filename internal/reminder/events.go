@@ -0,0 +1,90 @@
+package reminder
+
+import (
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Actions führt aus, was in Erinnerungsdialogen ausgelöst wird: direkt im
+// Store oder, wenn die GUI mit reminderd verbunden ist, über den Daemon
+type Actions interface {
+	Acknowledge(id int64, due time.Time) error
+	PostponeAppointment(id int64, d time.Duration) (store.Appointment, error)
+	DeleteAllAppointments() (store.Operation, error)
+}
+
+// Arten von Erinnerungen, die an ein anderes Programm weitergegeben werden
+const (
+	KindDue      = "due"      // zum Termin
+	KindEarly    = "early"    // zusätzliche Vorwarnung der Prioritätsstufe oder des Termins
+	KindReminder = "reminder" // Vorwarnung mit Möglichkeit zum Verschieben
+	KindNag      = "nag"      // Wiederholung einer unbestätigten Erinnerung
+	KindOverlay  = "overlay"  // letzte Eskalationsstufe, bildschirmfüllend
+)
+
+// Event ist eine ausgelöste Erinnerung
+type Event struct {
+	Kind        string
+	Appointment store.Appointment
+	Due         time.Time
+	Level       priority.Level
+	Minutes     int // bei KindReminder: Minuten bis zum Termin
+}
+
+// SetActions legt fest, wie Bestätigen, Verschieben und Löschen ausgeführt werden
+func (r *ReminderService) SetActions(a Actions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions = a
+}
+
+func (r *ReminderService) getActions() Actions {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.actions
+}
+
+// SetUndoHint legt fest, wie nach dem Löschen aller Termine das Rückgängigmachen
+// der Änderung op angeboten wird; ohne zeigt der Dienst nur eine Bestätigung an
+func (r *ReminderService) SetUndoHint(fn func(op store.Operation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.undo = fn
+}
+
+func (r *ReminderService) undoHint() func(store.Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.undo
+}
+
+// Forward gibt ausgelöste Erinnerungen an fn weiter, z.B. an eine mit
+// reminderd verbundene GUI. Liefert fn true, wurde die Erinnerung dort
+// angezeigt und der Dienst zeigt selbst keine an; KindDue und KindEarly
+// zeigt er in jedem Fall selbst an.
+func (r *ReminderService) Forward(fn func(Event) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.forward = fn
+}
+
+func (r *ReminderService) forwarded(e Event) bool {
+	r.mu.Lock()
+	fn := r.forward
+	r.mu.Unlock()
+	return fn != nil && fn(e)
+}
+
+// Show zeigt eine von reminderd weitergegebene Erinnerung im eigenen Fenster an
+func (r *ReminderService) Show(e Event) {
+	switch e.Kind {
+	case KindReminder:
+		r.showReminder(e.Appointment, e.Level, e.Minutes)
+	case KindNag:
+		r.showNagPopup(nag{appointment: e.Appointment, due: e.Due, level: e.Level})
+	case KindOverlay:
+		r.showOverlay(nag{appointment: e.Appointment, due: e.Due, level: e.Level})
+	}
+}
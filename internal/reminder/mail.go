@@ -0,0 +1,47 @@
+package reminder
+
+import (
+	"log"
+	"strings"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/mail"
+)
+
+// mailNotifier versendet Erinnerungen per E-Mail, zu einem Termin mit dem
+// Termin als .ics-Anhang
+type mailNotifier struct {
+	cfg      config.Mail
+	language string
+}
+
+func newMailNotifier(cfg config.Mail, locale string) mailNotifier {
+	language := cfg.Language
+	if language == "" {
+		language = locale
+	}
+	return mailNotifier{cfg: cfg, language: language}
+}
+
+func (m mailNotifier) Notify(n Notification) (bool, bool) {
+	// Benachrichtigungen ohne Termin, z.B. die Zusammenfassung nach der
+	// Ruhezeit, gehen als einfacher Text
+	msg := mail.Message{Subject: n.Title, Body: n.Message}
+	var err error
+	if n.Appointment.ID != 0 {
+		level := ""
+		if n.Level.Value != 0 {
+			level = n.Level.Name
+		}
+		msg, err = mail.Reminder(m.language, n.Appointment, level, n.Minutes)
+	}
+	if err == nil {
+		err = mail.Send(m.cfg, msg)
+	}
+	if err != nil {
+		log.Printf("Fehler beim Versenden der Erinnerung per E-Mail: %v", err)
+		return false, false
+	}
+	log.Printf("Erinnerung per E-Mail an %s versendet", strings.Join(m.cfg.To, ", "))
+	return false, true
+}
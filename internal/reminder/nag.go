@@ -0,0 +1,188 @@
+package reminder
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/screens"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Ein fälliger Termin, dessen Erinnerung noch nicht bestätigt wurde
+type nag struct {
+	appointment store.Appointment
+	due         time.Time
+	level       priority.Level
+	count       int       // bisherige Wiederholungen
+	next        time.Time // nächste Wiederholung
+}
+
+// trackNag nimmt einen fälligen Termin in die Wiederholung auf, sofern er
+// noch nicht bestätigt wurde. Läuft nur im Ticker-Goroutine.
+func (r *ReminderService) trackNag(a store.Appointment, due time.Time, level priority.Level) {
+	if n, ok := r.nags[a.ID]; ok && n.due.Equal(due) {
+		// Änderungen an der Prioritätsstufe übernehmen
+		n.level = level
+		return
+	}
+
+	acknowledged, err := r.store.Acknowledged(a.ID, due)
+	if err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	if acknowledged {
+		return
+	}
+
+	r.nags[a.ID] = &nag{
+		appointment: a,
+		due:         due,
+		level:       level,
+		next:        due.Add(level.NagInterval),
+	}
+}
+
+// processNags wiederholt fällige Erinnerungen und eskaliert dabei schrittweise.
+// Bestätigte, gelöschte und verschobene Termine fallen heraus. Während der
+// Ruhezeit wird nur bei kritischen Terminen nachgehakt.
+func (r *ReminderService) processNags(now time.Time, quietNow bool) {
+	for id, n := range r.nags {
+		acknowledged, err := r.store.Acknowledged(id, n.due)
+		if err != nil {
+			log.Printf("%v", err)
+			continue
+		}
+		if acknowledged || n.level.NagInterval <= 0 {
+			delete(r.nags, id)
+			continue
+		}
+
+		current, err := r.store.GetAppointment(id)
+		if err != nil {
+			delete(r.nags, id)
+			continue
+		}
+		if due, err := current.Due(); err != nil || !due.Equal(n.due) {
+			delete(r.nags, id)
+			continue
+		}
+
+		if now.Before(n.next) || (quietNow && n.level.Value < priority.Critical) {
+			continue
+		}
+		step := n.level.Step(n.count)
+		n.count++
+		n.next = now.Add(n.level.NagInterval)
+		n.appointment = current
+
+		log.Printf("Erinnerung an Termin ID=%d nicht bestätigt, Wiederholung %d (%s)", id, n.count, step)
+		go r.escalate(*n, step)
+	}
+}
+
+func (r *ReminderService) escalate(n nag, step string) {
+	for _, name := range r.config().routesFor(n.level) {
+		go r.notifyVia(name, Notification{Title: i18n.T("reminder.title"), Message: nagMessage(n), Level: n.level})
+	}
+	switch step {
+	case priority.StepSound:
+		go r.PlaySound(n.appointment, n.level)
+		r.showNagPopup(n)
+	case priority.StepOverlay:
+		go r.PlaySound(n.appointment, n.level)
+		r.showOverlay(n)
+	default:
+		r.showNagPopup(n)
+	}
+}
+
+func nagMessage(n nag) string {
+	a := n.appointment
+	return i18n.T("reminder.notConfirmed", "Since", dates.FormatTime(n.due.Format("15:04"))) + "\n" +
+		i18n.T("reminder.details", "Title", a.Title, "Date", dates.Format(a.Date),
+			"Time", dates.FormatTime(a.Time), "Priority", n.level.Name)
+}
+
+// showNagPopup zeigt die Erinnerung erneut an, bis zur nächsten Wiederholung
+func (r *ReminderService) showNagPopup(n nag) {
+	if r.forwarded(Event{Kind: KindNag, Appointment: n.appointment, Due: n.due, Level: n.level}) {
+		return
+	}
+	if r.window == nil {
+		if r.notify(Notification{Title: i18n.T("reminder.title"), Message: nagMessage(n), Level: n.level,
+			Style: StyleWarning, Timeout: n.level.NagInterval}) {
+			r.acknowledge(n.appointment.ID, n.due)
+		}
+		return
+	}
+
+	var dismiss func()
+	confirm := func() {
+		r.acknowledge(n.appointment.ID, n.due)
+		dismiss()
+	}
+	button := widget.NewButton(i18n.T("reminder.confirmEnter"), confirm)
+	button.Importance = widget.HighImportance
+
+	// Die nächste Wiederholung bringt ein neues Fenster
+	_, dismiss = r.openPopup(i18n.T("reminder.title"), widget.NewLabel(nagMessage(n)),
+		[]fyne.CanvasObject{button}, n.level.NagInterval,
+		map[fyne.KeyName]func(){fyne.KeyReturn: confirm, fyne.KeyEnter: confirm})
+}
+
+// showOverlay ist die letzte Eskalationsstufe: ein bildschirmfüllendes Fenster
+func (r *ReminderService) showOverlay(n nag) {
+	if r.forwarded(Event{Kind: KindOverlay, Appointment: n.appointment, Due: n.due, Level: n.level}) {
+		return
+	}
+	if r.window == nil {
+		if r.notify(Notification{Title: i18n.T("reminder.title"), Message: nagMessage(n), Level: n.level,
+			Style: StyleError, Timeout: n.level.NagInterval}) {
+			r.acknowledge(n.appointment.ID, n.due)
+		}
+		return
+	}
+
+	w := fyne.CurrentApp().NewWindow(i18n.T("reminder.title"))
+	var once sync.Once
+	closeWindow := func() { once.Do(w.Close) }
+
+	title := canvas.NewText(n.appointment.Title, theme.Color(theme.ColorNameError))
+	title.TextSize = 48
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.Alignment = fyne.TextAlignCenter
+
+	confirm := widget.NewButton(i18n.T("reminder.confirm"), func() {
+		r.acknowledge(n.appointment.ID, n.due)
+		closeWindow()
+	})
+	confirm.Importance = widget.HighImportance
+
+	w.SetContent(container.NewCenter(container.NewVBox(
+		title,
+		widget.NewLabel(nagMessage(n)),
+		container.NewHBox(confirm, widget.NewButton(i18n.T("reminder.later"), closeWindow)),
+	)))
+	// Vollbild auf dem Monitor, auf dem das Fenster steht
+	w.Show()
+	if monitor := r.config().monitor; monitor != "" {
+		if err := screens.MoveTo(w, monitor); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+	w.SetFullScreen(true)
+	w.RequestFocus()
+
+	time.AfterFunc(n.level.NagInterval, closeWindow)
+}
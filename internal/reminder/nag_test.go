@@ -0,0 +1,164 @@
+package reminder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/sound"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// recorder ist ein Benachrichtigungsweg, der alles auf einen Kanal schreibt
+type recorder struct {
+	sent        chan Notification
+	acknowledge bool
+}
+
+func (n *recorder) Notify(m Notification) (bool, bool) {
+	n.sent <- m
+	return n.acknowledge, true
+}
+
+// newTestService erstellt einen Dienst ohne Fenster, der über einen
+// recorder benachrichtigt und Töne in einen sound.NullSink spielt
+func newTestService(t *testing.T) (*ReminderService, *store.Store, *recorder, *sound.NullSink) {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	r := NewReminderService(s, nil)
+	i18n.SetLanguage("de") // unabhängig von LANG
+	sink := &sound.NullSink{}
+	r.SetSoundSink(sink)
+	rec := &recorder{sent: make(chan Notification, 16)}
+	r.mu.Lock()
+	r.cfg.notifiers = []Notifier{rec}
+	r.mu.Unlock()
+	return r, s, rec, sink
+}
+
+// expect wartet auf eine Benachrichtigung
+func (n *recorder) expect(t *testing.T) Notification {
+	t.Helper()
+	select {
+	case m := <-n.sent:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("Keine Benachrichtigung")
+		return Notification{}
+	}
+}
+
+// none prüft, dass keine Benachrichtigung kommt
+func (n *recorder) none(t *testing.T) {
+	t.Helper()
+	select {
+	case m := <-n.sent:
+		t.Errorf("Unerwartete Benachrichtigung: %+v", m)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// played wartet, bis der NullSink n Töne gespielt hat
+func played(t *testing.T, sink *sound.NullSink, n int) {
+	t.Helper()
+	for i := 0; i < 100 && len(sink.Played()) < n; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(sink.Played()); got != n {
+		t.Errorf("%d Töne gespielt, erwartet %d", got, n)
+	}
+}
+
+func TestNagEscalation(t *testing.T) {
+	r, s, rec, sink := newTestService(t)
+	level := priority.Level{
+		Value: priority.Critical, Name: "Kritisch", NagInterval: time.Minute,
+		Escalation: []string{priority.StepPopup, priority.StepSound, priority.StepOverlay},
+	}
+	a := store.Appointment{Title: "Zahnarzt", Date: "2030-03-14", Time: "09:30", Priority: &level.Value}
+	if err := s.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	due, _ := a.Due()
+	r.trackNag(a, due, level)
+
+	// Vor Ablauf des Abstands wird nicht nachgehakt
+	r.processNags(due.Add(30*time.Second), false)
+	rec.none(t)
+
+	steps := []struct {
+		style  string
+		sounds int
+	}{
+		{StyleWarning, 0}, // Fenster
+		{StyleWarning, 1}, // Fenster mit Ton
+		{StyleError, 2},   // bildschirmfüllend mit Ton
+		{StyleError, 3},   // die letzte Stufe bleibt
+		{StyleError, 4},
+	}
+	for i, step := range steps {
+		r.processNags(due.Add(time.Duration(i+1)*time.Minute), false)
+		if m := rec.expect(t); m.Style != step.style || m.Level.Value != priority.Critical {
+			t.Errorf("Wiederholung %d: Stil %q, erwartet %q", i+1, m.Style, step.style)
+		}
+		played(t, sink, step.sounds)
+		// Im selben Abstand nicht noch einmal
+		r.processNags(due.Add(time.Duration(i+1)*time.Minute+30*time.Second), false)
+		rec.none(t)
+	}
+
+	// Nach der Bestätigung ist Schluss
+	if err := s.Acknowledge(a.ID, due); err != nil {
+		t.Fatal(err)
+	}
+	r.processNags(due.Add(time.Hour), false)
+	rec.none(t)
+	if len(r.nags) != 0 {
+		t.Errorf("Bestätigter Termin wird weiter verfolgt: %+v", r.nags)
+	}
+	// Ein bestätigter Termin wird gar nicht erst aufgenommen
+	r.trackNag(a, due, level)
+	if len(r.nags) != 0 {
+		t.Error("Bestätigter Termin aufgenommen")
+	}
+}
+
+func TestNagStops(t *testing.T) {
+	r, s, rec, _ := newTestService(t)
+	high := priority.Level{Value: priority.High, Name: "Hoch", NagInterval: time.Minute}
+	moved := store.Appointment{Title: "Verschoben", Date: "2030-03-14", Time: "09:30"}
+	deleted := store.Appointment{Title: "Gelöscht", Date: "2030-03-14", Time: "09:30"}
+	quiet := store.Appointment{Title: "Ruhezeit", Date: "2030-03-14", Time: "09:30"}
+	for _, a := range []*store.Appointment{&moved, &deleted, &quiet} {
+		if err := s.AddAppointment(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	due, _ := moved.Due()
+	for _, a := range []store.Appointment{moved, deleted, quiet} {
+		r.trackNag(a, due, high)
+	}
+
+	if _, err := s.PostponeAppointment(moved.ID, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteAppointment(deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Während der Ruhezeit wird nur bei kritischen Terminen nachgehakt
+	r.processNags(due.Add(time.Minute), true)
+	rec.none(t)
+	if _, ok := r.nags[quiet.ID]; len(r.nags) != 1 || !ok {
+		t.Errorf("Verfolgte Termine: %+v", r.nags)
+	}
+	r.processNags(due.Add(time.Minute), false)
+	if m := rec.expect(t); m.Style != StyleWarning {
+		t.Errorf("Stil %q nach der Ruhezeit", m.Style)
+	}
+}
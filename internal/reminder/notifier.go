@@ -0,0 +1,185 @@
+package reminder
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os/exec"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/push"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Darstellung einer Benachrichtigung, entspricht den Zenity-Dialogtypen
+const (
+	StyleInfo    = "info"
+	StyleWarning = "warning" // wiederholte Erinnerung
+	StyleError   = "error"   // letzte Eskalationsstufe
+)
+
+// Notification ist eine Erinnerung, wie sie an die Benachrichtigungswege geht
+type Notification struct {
+	Title   string
+	Message string
+	Level   priority.Level
+	Style   string
+	Timeout time.Duration // 0 = bleibt offen bis zur Bestätigung
+
+	// Termin der Erinnerung, für Wege wie E-Mail, die mehr als den Text
+	// zeigen; ID 0, wenn es um keinen einzelnen Termin geht
+	Appointment store.Appointment
+	Minutes     int // Minuten bis zum Termin, 0 = zum Termin
+}
+
+// Notifier ist ein Weg, den Benutzer zu benachrichtigen. shown ist false,
+// wenn der Weg gerade nicht verfügbar ist; dann kommt der nächste in der
+// Kette an die Reihe. acknowledged meldet eine Bestätigung durch den Benutzer.
+type Notifier interface {
+	Notify(n Notification) (acknowledged, shown bool)
+}
+
+// Bekannte Benachrichtigungswege für die Einstellung notifiers
+var notifiers = map[string]Notifier{
+	"zenity":      zenityNotifier{},
+	"notify-send": notifySendNotifier{},
+	"log":         logNotifier{},
+}
+
+// Benachrichtigungswege, die erst mit der Konfiguration verfügbar sind, und
+// der Eintrag, der sie einrichtet
+var configured = map[string]string{
+	"mail":   "mail.host",
+	"ntfy":   "push.ntfy.topic",
+	"gotify": "push.gotify.url",
+	"matrix": "push.matrix.room",
+}
+
+// NewNotifiers baut die Kette der Benachrichtigungswege aus c.Notifiers.
+// byName enthält alle verfügbaren Wege, auch die erst mit der
+// Konfiguration eingerichteten wie "mail" oder "ntfy", für Vorwarnungen
+// mit Via und push.routes.
+func NewNotifiers(c config.Config) (chain []Notifier, byName map[string]Notifier, err error) {
+	byName = maps.Clone(notifiers)
+	if c.Mail.Enabled() {
+		byName["mail"] = newMailNotifier(c.Mail, i18n.Match(c.Locale))
+	}
+	if c.Push.Ntfy.Enabled() {
+		byName["ntfy"] = pushNotifier{name: "ntfy", sender: push.NewNtfy(c.Push.Ntfy)}
+	}
+	if c.Push.Gotify.Enabled() {
+		byName["gotify"] = pushNotifier{name: "Gotify", sender: push.NewGotify(c.Push.Gotify)}
+	}
+	if c.Push.Matrix.Enabled() {
+		byName["matrix"] = pushNotifier{name: "Matrix", sender: push.NewMatrix(c.Push.Matrix)}
+	}
+
+	chain = make([]Notifier, 0, len(c.Notifiers))
+	for _, name := range c.Notifiers {
+		n, err := lookupNotifier(byName, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, n)
+	}
+	for level, names := range c.Push.Routes {
+		for _, name := range names {
+			if _, err := lookupNotifier(byName, name); err != nil {
+				return nil, nil, fmt.Errorf("push.routes %s: %v", level, err)
+			}
+		}
+	}
+	return chain, byName, nil
+}
+
+func lookupNotifier(byName map[string]Notifier, name string) (Notifier, error) {
+	if n, ok := byName[name]; ok {
+		return n, nil
+	}
+	if setting, ok := configured[name]; ok {
+		return nil, fmt.Errorf("Benachrichtigungsweg %s ist nicht eingerichtet, %s fehlt", name, setting)
+	}
+	return nil, fmt.Errorf("Unbekannter Benachrichtigungsweg: %s", name)
+}
+
+// notify gibt die Benachrichtigung an den ersten verfügbaren Weg der Kette
+// und liefert true, wenn der Benutzer sie bestätigt hat
+func (r *ReminderService) notify(n Notification) bool {
+	for _, notifier := range r.config().notifiers {
+		if acknowledged, shown := notifier.Notify(n); shown {
+			return acknowledged
+		}
+	}
+	log.Printf("Keine Benachrichtigung möglich: %s", n.Message)
+	return false
+}
+
+// notifyVia benachrichtigt zusätzlich über den Weg einer Vorwarnung mit Via
+// oder aus push.routes
+func (r *ReminderService) notifyVia(via string, n Notification) {
+	notifier, ok := r.config().byName[via]
+	if !ok {
+		log.Printf("Benachrichtigungsweg %s der Vorwarnung ist nicht verfügbar", via)
+		return
+	}
+	if _, shown := notifier.Notify(n); !shown {
+		log.Printf("Keine Benachrichtigung über %s möglich: %s", via, n.Message)
+	}
+}
+
+type zenityNotifier struct{}
+
+func (zenityNotifier) Notify(n Notification) (bool, bool) {
+	style := n.Style
+	if style == "" {
+		style = StyleInfo
+	}
+	width, height := 400, 200
+	if style == StyleError {
+		// Ohne GUI bleibt für die letzte Stufe nur ein möglichst großer Dialog
+		width, height = 1200, 700
+	}
+	args := []string{"--" + style,
+		"--title=" + n.Title,
+		"--text=" + n.Message,
+		fmt.Sprintf("--width=%d", width),
+		fmt.Sprintf("--height=%d", height)}
+	if style != StyleInfo {
+		args = append(args, "--ok-label="+i18n.T("reminder.confirm"))
+	}
+	if n.Timeout > 0 {
+		args = append(args, fmt.Sprintf("--timeout=%d", int(n.Timeout.Seconds())))
+	}
+	return zenity(args...)
+}
+
+type notifySendNotifier struct{}
+
+func (notifySendNotifier) Notify(n Notification) (bool, bool) {
+	urgency := n.Level.Urgency
+	if urgency == "" {
+		urgency = priority.UrgencyNormal
+	}
+	cmd := exec.Command("notify-send",
+		"--urgency="+urgency,
+		"--app-name=Terminerinnerung",
+		n.Title,
+		n.Message)
+	if err := cmd.Run(); err != nil {
+		log.Printf("Fehler beim Anzeigen der Benachrichtigung: %v", err)
+		return false, false
+	}
+	return false, true
+}
+
+// logNotifier schreibt die Erinnerung nur ins Log, z.B. für den Daemon ohne Desktop
+type logNotifier struct{}
+
+func (logNotifier) Notify(n Notification) (bool, bool) {
+	log.Printf("%s %s", n.Title, strings.ReplaceAll(n.Message, "\n", " | "))
+	return false, true
+}
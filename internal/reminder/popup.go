@@ -0,0 +1,128 @@
+package reminder
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/screens"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Größe eines Erinnerungsfensters
+var popupSize = fyne.NewSize(420, 280)
+
+// Jedes weitere offene Erinnerungsfenster erscheint um so viele Pixel versetzt
+const popupCascade = 32
+
+// openPopup zeigt eine Erinnerung in einem eigenen Fenster, das über allen
+// anderen bleibt, auch wenn das Hauptfenster minimiert oder im Tray ist.
+// Mit timeout > 0 schließt es sich danach von selbst und zeigt die
+// verbleibende Zeit an. keys ordnet Tasten Aktionen zu; Escape schließt das
+// Fenster ohne Bestätigung. dismiss schließt es.
+func (r *ReminderService) openPopup(title string, body fyne.CanvasObject, buttons []fyne.CanvasObject,
+	timeout time.Duration, keys map[fyne.KeyName]func()) (w fyne.Window, dismiss func()) {
+	w = fyne.CurrentApp().NewWindow(title)
+	dismiss = w.Close // mehrfaches Schließen schadet nicht
+
+	stop := make(chan struct{})
+	w.SetOnClosed(func() {
+		close(stop)
+		r.removePopup(w)
+	})
+	w.Canvas().SetOnTypedKey(func(e *fyne.KeyEvent) {
+		if action, ok := keys[e.Name]; ok {
+			action()
+		} else if e.Name == fyne.KeyEscape {
+			dismiss()
+		}
+	})
+
+	status := widget.NewLabel("")
+	w.SetContent(container.NewBorder(nil,
+		container.NewVBox(container.NewHBox(buttons...), status), nil, nil,
+		body))
+	w.Resize(popupSize)
+
+	index := r.addPopup(w)
+	w.Show()
+	r.placePopup(w, index)
+	if err := screens.KeepAbove(w); err != nil {
+		log.Printf("%v", err)
+	}
+	if r.config().stealFocus {
+		w.RequestFocus()
+	}
+
+	if timeout > 0 {
+		deadline := time.Now().Add(timeout)
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				left := time.Until(deadline).Round(time.Second)
+				if left <= 0 {
+					dismiss()
+					return
+				}
+				status.SetText(i18n.T("reminder.closesIn", "Time", fmt.Sprintf("%d:%02d", int(left.Minutes()), int(left.Seconds())%60)))
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	return w, dismiss
+}
+
+// addPopup merkt sich ein offenes Erinnerungsfenster und liefert, wie viele
+// schon offen sind
+func (r *ReminderService) addPopup(w fyne.Window) int {
+	r.popupMu.Lock()
+	defer r.popupMu.Unlock()
+	r.popups = append(r.popups, w)
+	return len(r.popups) - 1
+}
+
+func (r *ReminderService) removePopup(w fyne.Window) {
+	r.popupMu.Lock()
+	defer r.popupMu.Unlock()
+	r.popups = slices.DeleteFunc(r.popups, func(p fyne.Window) bool { return p == w })
+}
+
+// placePopup stellt ein Erinnerungsfenster mittig auf den Monitor aus
+// [reminders] monitor, ohne Angabe auf den des Hauptfensters, und versetzt
+// es um die Zahl der schon offenen. Ohne xrandr zentriert Fyne es.
+func (r *ReminderService) placePopup(w fyne.Window, index int) {
+	monitors, err := screens.List()
+	if err != nil {
+		w.CenterOnScreen()
+		return
+	}
+	m := monitors[0]
+	if want := r.config().monitor; want != "" {
+		m = screens.Choose(monitors, want)
+	} else if main, err := screens.Geometry(r.window); err == nil {
+		if found, ok := screens.At(monitors, main.X+main.Width/2, main.Y+main.Height/2); ok {
+			m = found
+		}
+	}
+	size, err := screens.Geometry(w)
+	if err != nil {
+		log.Printf("%v", err)
+		w.CenterOnScreen()
+		return
+	}
+	x, y := m.Center(size.Width, size.Height)
+	offset := index * popupCascade
+	if err := screens.Move(w, x+offset, y+offset); err != nil {
+		log.Printf("%v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package reminder
+
+import (
+	"context"
+	"log"
+
+	"Reminder_Erinnerungs_App/internal/push"
+)
+
+// pushNotifier sendet Erinnerungen über ntfy, Gotify oder Matrix aufs Handy
+type pushNotifier struct {
+	name   string
+	sender push.Sender
+}
+
+func (p pushNotifier) Notify(n Notification) (bool, bool) {
+	m := push.Message{Title: n.Title, Body: n.Message, Priority: push.ForLevel(n.Level)}
+	if err := p.sender.Send(context.Background(), m); err != nil {
+		log.Printf("Fehler beim Senden der Erinnerung: %v", err)
+		return false, false
+	}
+	log.Printf("Erinnerung über %s gesendet", p.name)
+	return false, true
+}
@@ -1,13 +1,25 @@
 package reminder
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/quiet"
+	"Reminder_Erinnerungs_App/internal/sound"
+	"Reminder_Erinnerungs_App/internal/store"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -15,18 +27,92 @@ import (
 )
 
 type ReminderService struct {
-	db             *sql.DB
+	store          *store.Store
 	window         fyne.Window
 	stopChan       chan struct{}
-	shownReminders map[int]bool
+	shownReminders map[int64]bool
+	nags           map[int64]*nag         // unbestätigte Termine, an die wiederholt erinnert wird
+	held           map[int64]heldReminder // während der Ruhezeit zurückgehalten
+	sink           sound.Sink
+	sounds         sound.Cache
+
+	mu      sync.Mutex
+	cfg     serviceConfig
+	actions Actions
+	forward func(Event) bool
+	undo    func(store.Operation) // zeigt nach dem Löschen aller Termine „Rückgängig“ an
+
+	popupMu sync.Mutex
+	popups  []fyne.Window // offene Erinnerungsfenster, zum Versetzen weiterer
+}
+
+// Aus der Konfigurationsdatei übernommene Einstellungen des Dienstes
+type serviceConfig struct {
+	alarms     []time.Duration // Vorwarnungen für alle Termine
+	autoClose  time.Duration   // Nicht-dauerhafte Erinnerungen schließen sich danach von selbst
+	quietHours quiet.Hours
+	notifiers  []Notifier
+	byName     map[string]Notifier // alle verfügbaren Wege, für Vorwarnungen mit Via
+	routes     map[string][]string // Prioritätsstufe (klein geschrieben) → zusätzliche Wege
+	monitor    string              // Monitor für Erinnerungen, "" = wo das Fenster ist
+	stealFocus bool                // Erinnerungsfenster bekommen den Fokus
 }
 
-func NewReminderService(db *sql.DB, window fyne.Window) *ReminderService {
-	return &ReminderService{
-		db:             db,
+func NewReminderService(s *store.Store, window fyne.Window) *ReminderService {
+	r := &ReminderService{
+		store:          s,
 		window:         window,
-		shownReminders: make(map[int]bool),
+		shownReminders: make(map[int64]bool),
+		nags:           make(map[int64]*nag),
+		held:           make(map[int64]heldReminder),
+		sink:           sound.DefaultSink(),
+		actions:        s,
+	}
+	if err := r.SetConfig(config.Default()); err != nil {
+		panic(err)
 	}
+	return r
+}
+
+// SetConfig übernimmt eine neue Konfiguration, auch während der Dienst läuft
+func (r *ReminderService) SetConfig(c config.Config) error {
+	chain, byName, err := NewNotifiers(c)
+	if err != nil {
+		return err
+	}
+	hours, err := quiet.Parse(c.QuietHours)
+	if err != nil {
+		return err
+	}
+	alarms := make([]time.Duration, len(c.Reminders.Alarms))
+	for i, m := range c.Reminders.Alarms {
+		alarms[i] = time.Duration(m) * time.Minute
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = serviceConfig{
+		alarms:     alarms,
+		autoClose:  time.Duration(c.Reminders.AutoClose) * time.Minute,
+		quietHours: hours,
+		notifiers:  chain,
+		byName:     byName,
+		routes:     make(map[string][]string, len(c.Push.Routes)),
+		monitor:    c.Reminders.Monitor,
+		stealFocus: c.Reminders.StealFocus,
+	}
+	for level, names := range c.Push.Routes {
+		r.cfg.routes[strings.ToLower(level)] = names
+	}
+	// Erinnerungen erscheinen in der Sprache aus locale
+	i18n.SetLanguage(c.Locale)
+	return nil
+}
+
+func (r *ReminderService) config() serviceConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg
 }
 
 func (r *ReminderService) Start() {
@@ -52,31 +138,30 @@ func (r *ReminderService) Stop() {
 
 func (r *ReminderService) checkAppointments() {
 	now := time.Now()
-	rows, err := r.db.Query(`
-		SELECT id, title, date, time, priority 
-		FROM appointments 
-		WHERE date = ? 
-		AND time IS NOT NULL`,
-		now.Format("2006-01-02"))
+	today := now.Format("2006-01-02")
+	appointments, err := r.store.QueryAppointments(store.Filter{From: today, To: today})
 	if err != nil {
 		log.Printf("Fehler beim Abrufen der Termine: %v", err)
 		return
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var id int
-		var title string
-		var date string
-		var timeStr string
-		var priority sql.NullInt64
-		if err := rows.Scan(&id, &title, &date, &timeStr, &priority); err != nil {
-			log.Printf("Fehler beim Scannen der Termine: %v", err)
+	levels, err := r.store.PriorityLevels()
+	if err != nil {
+		log.Printf("Fehler beim Abrufen der Prioritäten, verwende Standardwerte: %v", err)
+		levels = priority.Defaults()
+	}
+	cfg := r.config()
+	quietNow := r.isQuiet(now)
+	remind := r.remindedSubscriptions()
+
+	for _, a := range appointments {
+		if a.Time == "" || (a.ReadOnly() && !remind[a.SubscriptionID]) {
 			continue
 		}
+		level := levels.Lookup(a.Priority)
 
 		// Parse appointment time
-		appointmentTime, err := time.Parse("15:04", timeStr)
+		appointmentTime, err := time.Parse("15:04", a.Time)
 		if err != nil {
 			log.Printf("Fehler beim Parsen der Zeit: %v", err)
 			continue
@@ -93,28 +178,139 @@ func (r *ReminderService) checkAppointments() {
 		diffMinutes := int(diff.Minutes())
 
 		// Exakt zum Termin (0-1 Minute Differenz)
-		if diffMinutes >= 0 && diffMinutes < 1 {
-			// Formatiere die Zeit für die Anzeige
-			formattedTime := appointmentTime.Format("15:04")
-			formattedDate := now.Format("02.01.2006")
-			notificationText := fmt.Sprintf("%s, %s, %s", title, formattedTime, formattedDate)
-			go r.showZenityNotification(notificationText, "", priority)
+		if diffMinutes >= 0 && diffMinutes < 1 && !r.hold(quietNow, a, appointmentDateTime, level, 0) {
+			// Formatiere Zeit und Datum in der eingestellten Sprache
+			formattedTime := dates.FormatTime(a.Time)
+			formattedDate := dates.Format(now.Format("2006-01-02"))
+			notificationText := fmt.Sprintf("%s, %s, %s", a.Title, formattedTime, formattedDate)
+			r.forwarded(Event{Kind: KindDue, Appointment: a, Due: appointmentDateTime, Level: level})
+			for _, name := range cfg.routesFor(level) {
+				go r.notifyVia(name, Notification{
+					Title: i18n.T("reminder.title"), Message: notificationText, Level: level, Appointment: a,
+				})
+			}
+			go r.PlaySound(a, level)
+			go func() {
+				if r.showNotification(notificationText, "", level) {
+					r.acknowledge(a.ID, appointmentDateTime)
+				}
+			}()
+		}
+		// Vorwarnungen für alle Termine mit Möglichkeit zum Verschieben,
+		// zusätzliche der Prioritätsstufe und des Termins selbst als
+		// Benachrichtigung; mit Via auch über diesen Weg, z.B. per E-Mail
+		early, via := cfg.alarmsFor(level, a)
+		for _, before := range early {
+			minutes := int(before.Minutes())
+			if diffMinutes != minutes-1 ||
+				r.hold(quietNow, a, appointmentDateTime, level, minutes) {
+				continue
+			}
+			if slices.Contains(cfg.alarms, before) {
+				go r.showReminder(a, level, minutes)
+			} else {
+				timing := i18n.T("reminder.in", "Count", minutes)
+				notificationText := fmt.Sprintf("%s, %s", a.Title, dates.FormatTime(a.Time))
+				r.forwarded(Event{Kind: KindEarly, Appointment: a, Due: appointmentDateTime, Level: level, Minutes: minutes})
+				go r.showNotification(notificationText, timing, level)
+			}
+			for _, name := range mergeNames(via[before], cfg.routesFor(level)) {
+				go r.notifyVia(name, Notification{
+					Title:       i18n.T("reminder.title"),
+					Message:     fmt.Sprintf("%s, %s\n%s", a.Title, dates.FormatTime(a.Time), i18n.T("reminder.in", "Count", minutes)),
+					Level:       level,
+					Appointment: a,
+					Minutes:     minutes,
+				})
+			}
+		}
+		// Nach Fälligkeit wiederholen, bis die Erinnerung bestätigt wird
+		if level.NagInterval > 0 && diff < 0 {
+			r.trackNag(a, appointmentDateTime, level)
 		}
-		// 5-Minuten-Vorwarnung
-		if diffMinutes >= 4 && diffMinutes < 5 {
-			go r.showReminder(id, title, date, timeStr, priority)
+	}
+
+	r.processNags(now, quietNow)
+	if !quietNow {
+		r.deliverHeld()
+	}
+}
+
+// alarmsFor liefert die Abstände aller Vorwarnungen für einen Termin, die
+// für alle Termine zuerst, und je Abstand die zusätzlichen Wege aus Via
+func (cfg serviceConfig) alarmsFor(level priority.Level, a store.Appointment) ([]time.Duration, map[time.Duration][]string) {
+	early := slices.Clone(cfg.alarms)
+	via := make(map[time.Duration][]string)
+	for _, alarm := range append(slices.Clone(level.EarlyAlarms), a.Alarms...) {
+		if !slices.Contains(early, alarm.Before) {
+			early = append(early, alarm.Before)
+		}
+		if alarm.Via != "" && !slices.Contains(via[alarm.Before], alarm.Via) {
+			via[alarm.Before] = append(via[alarm.Before], alarm.Via)
+		}
+	}
+	return early, via
+}
+
+// routesFor liefert die zusätzlichen Wege aus push.routes für eine
+// Prioritätsstufe: nach Name, nach Wert oder "*"
+func (cfg serviceConfig) routesFor(level priority.Level) []string {
+	for _, key := range []string{strings.ToLower(level.Name), strconv.Itoa(level.Value)} {
+		if names, ok := cfg.routes[key]; ok {
+			return names
+		}
+	}
+	return cfg.routes["*"]
+}
+
+// mergeNames hängt an a die Namen aus b an, die noch fehlen
+func mergeNames(a, b []string) []string {
+	merged := slices.Clone(a)
+	for _, name := range b {
+		if !slices.Contains(merged, name) {
+			merged = append(merged, name)
 		}
 	}
+	return merged
 }
 
-func (r *ReminderService) showZenityNotification(title string, timing string, priority sql.NullInt64) {
+// remindedSubscriptions liefert die Abos, an deren Termine erinnert wird
+func (r *ReminderService) remindedSubscriptions() map[int64]bool {
+	subs, err := r.store.Subscriptions()
+	if err != nil {
+		log.Printf("Fehler beim Abrufen der Kalender-Abos: %v", err)
+		return nil
+	}
+	remind := make(map[int64]bool)
+	for _, sub := range subs {
+		remind[sub.ID] = sub.Remind
+	}
+	return remind
+}
+
+// showNotification zeigt eine Benachrichtigung an und liefert true,
+// wenn der Benutzer sie mit OK bestätigt hat
+func (r *ReminderService) showNotification(title string, timing string, level priority.Level) bool {
 	priorityText := ""
-	if priority.Valid {
-		priorityText = fmt.Sprintf("\nPriorität: %d", priority.Int64)
+	if level.Value != 0 {
+		priorityText = "\n" + i18n.T("reminder.priorityLine", "Priority", level.Name)
 	}
 
-	message := fmt.Sprintf("%s\n%s%s", title, timing, priorityText)
+	n := Notification{
+		Title:   i18n.T("reminder.title"),
+		Message: fmt.Sprintf("%s\n%s%s", title, timing, priorityText),
+		Level:   level,
+	}
+	if !level.Sticky {
+		n.Timeout = r.config().autoClose
+	}
+	return r.notify(n)
+}
 
+// zenity zeigt einen Dialog auf dem ersten erreichbaren Display an.
+// acknowledged ist true, wenn der Benutzer mit OK bestätigt hat, shown,
+// wenn der Dialog überhaupt erschienen ist.
+func zenity(args ...string) (acknowledged, shown bool) {
 	// Hole die aktuelle Umgebung
 	env := os.Environ()
 
@@ -122,172 +318,164 @@ func (r *ReminderService) showZenityNotification(title string, timing string, pr
 	displays := []string{":0", ":0.0", ":1", ":1.0"}
 
 	for _, display := range displays {
-		cmd := exec.Command("zenity", "--info",
-			"--title=Terminerinnerung!",
-			"--text="+message,
-			"--width=400",
-			"--height=200")
+		cmd := exec.Command("zenity", args...)
 
 		// Setze die komplette Umgebung inkl. DISPLAY
 		cmd.Env = append(env, "DISPLAY="+display)
 
-		if err := cmd.Run(); err == nil {
+		err := cmd.Run()
+		if err == nil {
 			// Wenn erfolgreich, breche die Schleife ab
-			return
+			return true, true
+		}
+		// Exit-Code 5: Dialog wurde angezeigt, aber nach --timeout geschlossen
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 5 {
+			return false, true
 		}
 	}
+	return false, false
+}
 
-	// Fallback: Wenn Zenity nicht funktioniert, verwende notify-send
-	fallbackCmd := exec.Command("notify-send",
-		"--urgency=critical",
-		"--app-name=Terminerinnerung",
-		"Terminerinnerung",
-		message)
-
-	if err := fallbackCmd.Run(); err != nil {
-		log.Printf("Fehler beim Anzeigen der Benachrichtigung: %v", err)
+// acknowledge vermerkt die Bestätigung in der Datenbank und beendet das Nachhaken
+func (r *ReminderService) acknowledge(id int64, due time.Time) {
+	if err := r.getActions().Acknowledge(id, due); err != nil {
+		log.Printf("Fehler beim Bestätigen der Erinnerung: %v", err)
+		return
 	}
+	log.Printf("Erinnerung an Termin ID=%d bestätigt", id)
+}
 
-	// Wenn keine GUI verfügbar ist, logge zusätzlich
-	if r.window == nil {
-		log.Printf("Termin: %s", message)
+func (r *ReminderService) showReminder(a store.Appointment, level priority.Level, minutes int) {
+	priorityStr := level.Name
+
+	due, err := a.Due()
+	if err != nil {
+		log.Printf("Fehler beim Parsen von Datum/Zeit: %v", err)
+		return
+	}
+	if r.forwarded(Event{Kind: KindReminder, Appointment: a, Due: due, Level: level, Minutes: minutes}) {
+		return
 	}
-}
 
-func (r *ReminderService) showReminder(id int, title, date, timeStr string, priority sql.NullInt64) {
 	// Wenn kein Fenster verfügbar ist, zeige nur die Zenity-Benachrichtigung
 	if r.window == nil {
-		priorityStr := "Keine"
-		if priority.Valid {
-			priorityStr = fmt.Sprintf("%d", priority.Int64)
+		message := i18n.T("reminder.in", "Count", minutes) + ":\n" + i18n.T("reminder.details",
+			"Title", a.Title, "Date", dates.Format(a.Date), "Time", dates.FormatTime(a.Time), "Priority", priorityStr)
+		if r.showNotification(message, "", level) {
+			r.acknowledge(a.ID, due)
 		}
-		message := fmt.Sprintf("Termin in 5 Minuten:\n%s\nDatum: %s\nZeit: %s\nPriorität: %s",
-			title, date, timeStr, priorityStr)
-		r.showZenityNotification(message, "", priority)
 		return
 	}
 
-	priorityStr := "Keine"
-	if priority.Valid {
-		priorityStr = fmt.Sprintf("%d", priority.Int64)
-	}
-
 	content := widget.NewForm(
-		widget.NewFormItem("Titel", widget.NewLabel(title)),
-		widget.NewFormItem("Datum", widget.NewLabel(date)),
-		widget.NewFormItem("Zeit", widget.NewLabel(timeStr)),
-		widget.NewFormItem("Priorität", widget.NewLabel(priorityStr)),
+		widget.NewFormItem(i18n.T("common.title"), widget.NewLabel(a.Title)),
+		widget.NewFormItem(i18n.T("common.date"), widget.NewLabel(dates.Format(a.Date))),
+		widget.NewFormItem(i18n.T("common.time"), widget.NewLabel(dates.FormatTime(a.Time))),
+		widget.NewFormItem(i18n.T("common.priority"), widget.NewLabel(priorityStr)),
 	)
 
-	// Erstelle den Dialog zuerst
-	var d dialog.Dialog
-
-	// Container für Buttons
-	buttons := container.NewHBox(
-		widget.NewButton("5 Min verschieben", func() {
-			r.postponeAppointment(id, 5)
-			// Schließe den Dialog erst nach der Verschiebung
-			if d != nil {
-				d.Hide()
-			}
-		}),
-		widget.NewButton("Neu planen", func() {
-			r.rescheduleAppointment(id, title)
-			if d != nil {
-				d.Hide()
-			}
-		}),
-		widget.NewButton("OK", func() {
-			if d != nil {
-				d.Hide()
-			}
+	// Das Fenster wird erst nach der Aktion geschlossen
+	var w fyne.Window
+	var dismiss func()
+	snooze := func() {
+		if err := r.postponeAppointment(a.ID, 5); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		dismiss()
+	}
+	confirm := func() {
+		r.acknowledge(a.ID, due)
+		dismiss()
+	}
+	ok := widget.NewButton(i18n.T("reminder.ok"), confirm)
+	ok.Importance = widget.HighImportance
+	buttons := []fyne.CanvasObject{
+		widget.NewButton(i18n.T("reminder.snooze"), snooze),
+		widget.NewButton(i18n.T("reminder.reschedule"), func() {
+			r.rescheduleAppointment(w, a, due, dismiss)
 		}),
-	)
-
-	// Vertikaler Container für Content und Buttons
-	vBox := container.NewVBox(
-		widget.NewLabel("In 5 Minuten beginnt:"),
-		content,
-		buttons,
-	)
-
-	// Custom Dialog
-	d = dialog.NewCustom(
-		"Terminerinnerung!",
-		"", // Kein Standard-Button
-		vBox,
-		r.window,
-	)
-	d.Resize(fyne.NewSize(400, 300))
-	d.Show()
-}
-
-func (r *ReminderService) postponeAppointment(id int, minutes int) {
-	// Erst die aktuellen Werte abrufen
-	var date string
-	var timeStr sql.NullString
-	err := r.db.QueryRow("SELECT date, time FROM appointments WHERE id = ?", id).Scan(&date, &timeStr)
-	if err != nil {
-		log.Printf("Fehler beim Abrufen des Termins: %v", err)
-		dialog.ShowError(err, r.window)
-		return
+		ok,
 	}
-
-	if !timeStr.Valid {
-		log.Printf("Keine gültige Zeit für Termin ID=%d gefunden", id)
-		dialog.ShowError(fmt.Errorf("Keine gültige Zeit für diesen Termin"), r.window)
-		return
+	keys := map[fyne.KeyName]func(){fyne.KeyReturn: confirm, fyne.KeyEnter: confirm, fyne.KeyS: snooze}
+	// Termine aus abonnierten Kalendern lassen sich nicht verschieben
+	if a.ReadOnly() {
+		buttons = buttons[2:]
+		delete(keys, fyne.KeyS)
 	}
 
-	// Parse das aktuelle Datum und Zeit
-	dateTime, err := time.Parse("2006-01-02 15:04", date+" "+timeStr.String)
-	if err != nil {
-		log.Printf("Fehler beim Parsen von Datum/Zeit: %v", err)
-		dialog.ShowError(err, r.window)
-		return
+	// Dauerhafte Erinnerungen bleiben offen, bis sie bestätigt werden
+	var timeout time.Duration
+	if !level.Sticky {
+		timeout = r.config().autoClose
 	}
+	w, dismiss = r.openPopup(i18n.T("reminder.title"),
+		container.NewVBox(widget.NewLabel(i18n.T("reminder.startsIn", "Count", minutes)), content),
+		buttons, timeout, keys)
+}
 
-	// Addiere nur 1 Minute zu der ursprünglichen Zeit
+func (r *ReminderService) postponeAppointment(id int64, minutes int) error {
+	// Nur 1 Minute zur ursprünglichen Zeit addieren
 	// (da wir bereits 5 Minuten vor dem Termin sind)
-	newDateTime := dateTime.Add(time.Duration(1) * time.Minute)
-
-	// Update mit den neuen Werten
-	_, err = r.db.Exec(`
-		UPDATE appointments 
-		SET time = ?
-		WHERE id = ?`,
-		newDateTime.Format("15:04"),
-		id)
+	appointment, err := r.getActions().PostponeAppointment(id, time.Minute)
 	if err != nil {
 		log.Printf("Fehler beim Verschieben des Termins: %v", err)
-		dialog.ShowError(err, r.window)
-		return
+		return err
 	}
 
 	delete(r.shownReminders, id)
-	log.Printf("Termin ID=%d um 1 Minute verschoben auf %s", id, newDateTime.Format("15:04"))
-
-	dialog.ShowInformation("Termin verschoben",
-		fmt.Sprintf("Der Termin wurde auf %s verschoben.", newDateTime.Format("15:04")),
-		r.window)
+	log.Printf("Termin ID=%d um 1 Minute verschoben auf %s", id, appointment.Time)
+	return nil
 }
 
-func (r *ReminderService) rescheduleAppointment(id int, title string) {
-	// Hier können wir die bestehende editAppointment Funktion wiederverwenden
-	// oder eine neue Variante erstellen
-	// ... Implementation ...
+// rescheduleAppointment fragt im Erinnerungsfenster w nach einem neuen
+// Beginn und verschiebt den Termin dorthin; danach wird das Fenster mit
+// dismiss geschlossen
+func (r *ReminderService) rescheduleAppointment(w fyne.Window, a store.Appointment, due time.Time, dismiss func()) {
+	dateEntry := widget.NewEntry()
+	dateEntry.PlaceHolder = i18n.T("common.datePlaceholder")
+	dateEntry.SetText(dates.Format(a.Date))
+	timeEntry := widget.NewEntry()
+	timeEntry.PlaceHolder = i18n.T("common.timePlaceholder")
+	timeEntry.SetText(a.Time)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("common.date"), dateEntry),
+		widget.NewFormItem(i18n.T("common.time"), timeEntry),
+	}
+	d := dialog.NewForm(i18n.T("reminder.rescheduleTitle"), i18n.T("common.save"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		value := strings.TrimSpace(dateEntry.Text) + " " + strings.TrimSpace(timeEntry.Text)
+		start, err := time.ParseInLocation("2006-01-02 15:04",
+			dates.Parse(strings.TrimSpace(dateEntry.Text))+" "+strings.TrimSpace(timeEntry.Text), time.Local)
+		if err != nil {
+			dialog.ShowError(errors.New(i18n.T("reminder.invalidStart", "Value", value)), w)
+			return
+		}
+		moved, err := r.getActions().PostponeAppointment(a.ID, start.Sub(due))
+		if err != nil {
+			log.Printf("Fehler beim Verschieben des Termins: %v", err)
+			dialog.ShowError(err, w)
+			return
+		}
+		delete(r.shownReminders, a.ID)
+		log.Printf("Termin ID=%d verschoben auf %s %s", a.ID, moved.Date, moved.Time)
+		dismiss()
+	}, w)
+	d.Show()
 }
 
 func (r *ReminderService) resetShownReminders() {
-	r.shownReminders = make(map[int]bool)
+	r.shownReminders = make(map[int64]bool)
 }
 
 func (r *ReminderService) DeleteAllAppointments() error {
 	// Wenn kein Fenster verfügbar ist, führe die Operation direkt aus
 	if r.window == nil {
-		_, err := r.db.Exec("DELETE FROM appointments")
-		if err != nil {
-			return fmt.Errorf("Fehler beim Löschen aller Termine: %v", err)
+		if _, err := r.getActions().DeleteAllAppointments(); err != nil {
+			return err
 		}
 		r.resetShownReminders()
 		return nil
@@ -295,19 +483,23 @@ func (r *ReminderService) DeleteAllAppointments() error {
 
 	// Dialog zur Bestätigung
 	confirmDialog := dialog.NewConfirm(
-		"Alle Termine löschen",
-		"Möchten Sie wirklich alle Termine unwiderruflich löschen?",
+		i18n.T("lists.deleteAllAppointments"),
+		i18n.T("reminder.deleteAllConfirm"),
 		func(confirm bool) {
 			if confirm {
 				// Führe das Löschen durch
-				_, err := r.db.Exec("DELETE FROM appointments")
+				op, err := r.getActions().DeleteAllAppointments()
 				if err != nil {
-					dialog.ShowError(fmt.Errorf("Fehler beim Löschen aller Termine: %v", err), r.window)
+					dialog.ShowError(err, r.window)
 					return
 				}
 
-				// Zeige Bestätigung
-				dialog.ShowInformation("Erfolg", "Alle Termine wurden gelöscht.", r.window)
+				// Zeige Bestätigung, mit Rückgängig falls möglich
+				if hint := r.undoHint(); hint != nil && op.ID != 0 {
+					hint(op)
+				} else {
+					dialog.ShowInformation(i18n.T("common.success"), i18n.T("reminder.deleteAllDone"), r.window)
+				}
 
 				// Setze die shownReminders zurück
 				r.resetShownReminders()
@@ -315,8 +507,8 @@ func (r *ReminderService) DeleteAllAppointments() error {
 		},
 		r.window,
 	)
-	confirmDialog.SetDismissText("Abbrechen")
-	confirmDialog.SetConfirmText("Ja, alle löschen")
+	confirmDialog.SetDismissText(i18n.T("common.cancel"))
+	confirmDialog.SetConfirmText(i18n.T("reminder.deleteAllYes"))
 	confirmDialog.Show()
 
 	return nil
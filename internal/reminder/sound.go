@@ -0,0 +1,91 @@
+package reminder
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/sound"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+// Schlüssel der Toneinstellungen in der Tabelle settings
+const (
+	SettingVolume   = "sound.volume" // Lautstärke in Prozent, Standard 100
+	SettingTagSound = "sound.tag."   // + Tag: Tondatei für Termine mit diesem Tag
+)
+
+// SoundFor wählt die Tondatei für einen Termin: ein Ton für einen seiner Tags
+// hat Vorrang vor dem Ton der Prioritätsstufe. Leer bedeutet eingebauter Ton.
+func SoundFor(a store.Appointment, level priority.Level, tagSounds map[string]string) string {
+	for _, tag := range a.Tags {
+		if file, ok := tagSounds[strings.ToLower(tag)]; ok {
+			return file
+		}
+	}
+	return level.Sound
+}
+
+// builtinSound liefert den eingebauten Signalton einer Stufe: je dringender,
+// desto höher und öfter
+func builtinSound(level priority.Level) *sound.Clip {
+	switch {
+	case level.Value >= priority.Critical:
+		return sound.Beeps(988, 5)
+	case level.Value == priority.High:
+		return sound.Beeps(880, 3)
+	case level.Value == priority.Low:
+		return sound.Beeps(660, 1)
+	}
+	return sound.Beeps(784, 2)
+}
+
+// Volume liefert die eingestellte Lautstärke (0..1)
+func (r *ReminderService) Volume() float64 {
+	return r.percentSetting(SettingVolume, 100)
+}
+
+func (r *ReminderService) percentSetting(key string, def int) float64 {
+	text, err := r.store.Setting(key, strconv.Itoa(def))
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	percent, err := strconv.Atoi(text)
+	if err != nil || percent < 0 || percent > 100 {
+		log.Printf("Ungültige Einstellung %s=%s, verwende %d", key, text, def)
+		percent = def
+	}
+	return float64(percent) / 100
+}
+
+// PlaySound spielt den Ton für einen Termin ab und blockiert bis zum Ende.
+// Nicht lesbare Tondateien werden durch den eingebauten Ton ersetzt.
+func (r *ReminderService) PlaySound(a store.Appointment, level priority.Level) {
+	volume := r.Volume()
+	if volume <= 0 {
+		return
+	}
+
+	tagSounds, err := r.store.Settings(SettingTagSound)
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	clip := builtinSound(level)
+	if file := SoundFor(a, level, tagSounds); file != "" {
+		if c, err := r.sounds.Load(file); err != nil {
+			log.Printf("Fehler beim Laden des Tons %s: %v", file, err)
+		} else {
+			clip = c
+		}
+	}
+
+	if err := r.sink.Play(clip, volume); err != nil {
+		log.Printf("Fehler beim Abspielen des Tons: %v", err)
+	}
+}
+
+// SetSoundSink ersetzt die Audioausgabe, z.B. durch einen sound.NullSink
+func (r *ReminderService) SetSoundSink(sink sound.Sink) {
+	r.sink = sink
+}
@@ -0,0 +1,51 @@
+package reminder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/sound"
+	"Reminder_Erinnerungs_App/internal/store"
+)
+
+func TestPlaySound(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	r := NewReminderService(s, nil)
+	sink := &sound.NullSink{}
+	r.SetSoundSink(sink)
+
+	high := priority.Level{Value: priority.High}
+	if err := s.SetSetting(SettingVolume, "40"); err != nil {
+		t.Fatal(err)
+	}
+	// Eine fehlende Tondatei für einen Tag fällt auf den eingebauten Ton zurück
+	if err := s.SetSetting(SettingTagSound+"arbeit", filepath.Join(t.TempDir(), "fehlt.wav")); err != nil {
+		t.Fatal(err)
+	}
+	r.PlaySound(store.Appointment{Title: "Meeting", Tags: []string{"Arbeit"}}, high)
+
+	played := sink.Played()
+	if len(played) != 1 {
+		t.Fatalf("%d Töne gespielt, erwartet 1", len(played))
+	}
+	if played[0].Volume != 0.4 {
+		t.Errorf("Lautstärke %v, erwartet 0.4", played[0].Volume)
+	}
+	if want := builtinSound(high); len(played[0].Clip.Samples) != len(want.Samples) {
+		t.Errorf("Nicht der eingebaute Ton der Stufe Hoch")
+	}
+
+	// Lautstärke 0 spielt nichts
+	if err := s.SetSetting(SettingVolume, "0"); err != nil {
+		t.Fatal(err)
+	}
+	r.PlaySound(store.Appointment{Title: "Leise"}, high)
+	if n := len(sink.Played()); n != 1 {
+		t.Errorf("%d Töne nach Lautstärke 0, erwartet 1", n)
+	}
+}
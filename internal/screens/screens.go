@@ -0,0 +1,143 @@
+// Package screens ermittelt die angeschlossenen Monitore und platziert
+// Fenster darauf. Fyne kennt keine Fensterposition, deshalb geht das unter
+// X11 über xrandr und xdotool; ohne sie (z.B. unter Wayland) bleibt die
+// Platzierung dem Fenstermanager überlassen.
+package screens
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Besondere Werte für die Wahl eines Monitors; sonst gilt der Name aus
+// "xrandr --listmonitors", z.B. "HDMI-1"
+const (
+	Primary = "primary" // Hauptmonitor
+	Mouse   = "mouse"   // Monitor mit dem Mauszeiger
+)
+
+// Monitor ist ein Bildschirm mit Position und Größe in Pixeln
+type Monitor struct {
+	Name          string
+	X, Y          int
+	Width, Height int
+	Primary       bool
+}
+
+// Contains gibt an, ob der Punkt x/y auf dem Monitor liegt
+func (m Monitor) Contains(x, y int) bool {
+	return x >= m.X && x < m.X+m.Width && y >= m.Y && y < m.Y+m.Height
+}
+
+// Center liefert die Position, an der ein Fenster dieser Größe mittig steht
+func (m Monitor) Center(width, height int) (int, int) {
+	return m.X + max(0, (m.Width-width)/2), m.Y + max(0, (m.Height-height)/2)
+}
+
+// List liefert alle Monitore, der Hauptmonitor zuerst
+func List() ([]Monitor, error) {
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abfragen der Monitore: %v", err)
+	}
+	return parseMonitors(string(out))
+}
+
+// parseMonitors liest die Ausgabe von "xrandr --listmonitors":
+//
+//	Monitors: 2
+//	 0: +*DP-1 2560/597x1440/336+0+0  DP-1
+//	 1: +HDMI-1 1920/527x1080/296+2560+0  HDMI-1
+func parseMonitors(out string) ([]Monitor, error) {
+	var monitors []Monitor
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], ":") || fields[0] == "Monitors:" {
+			continue
+		}
+		m := Monitor{
+			Name:    strings.TrimLeft(fields[1], "+*"),
+			Primary: strings.Contains(fields[1], "*"),
+		}
+		var wmm, hmm int
+		if _, err := fmt.Sscanf(fields[2], "%d/%dx%d/%d+%d+%d", &m.Width, &wmm, &m.Height, &hmm, &m.X, &m.Y); err != nil {
+			return nil, fmt.Errorf("Unbekannte Angabe von xrandr: %s", scanner.Text())
+		}
+		if m.Primary {
+			monitors = append([]Monitor{m}, monitors...)
+		} else {
+			monitors = append(monitors, m)
+		}
+	}
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("Keine Monitore gefunden")
+	}
+	return monitors, nil
+}
+
+// MousePosition liefert die Position des Mauszeigers
+func MousePosition() (int, int, error) {
+	out, err := exec.Command("xdotool", "getmouselocation", "--shell").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("Fehler beim Abfragen des Mauszeigers: %v", err)
+	}
+	values := parseShell(string(out))
+	x, errX := strconv.Atoi(values["X"])
+	y, errY := strconv.Atoi(values["Y"])
+	if errX != nil || errY != nil {
+		return 0, 0, fmt.Errorf("Unbekannte Angabe von xdotool: %s", strings.TrimSpace(string(out)))
+	}
+	return x, y, nil
+}
+
+// parseShell liest die Ausgabe von "xdotool … --shell" (NAME=WERT je Zeile)
+func parseShell(out string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// At liefert den Monitor, auf dem der Punkt x/y liegt
+func At(monitors []Monitor, x, y int) (Monitor, bool) {
+	for _, m := range monitors {
+		if m.Contains(x, y) {
+			return m, true
+		}
+	}
+	return Monitor{}, false
+}
+
+// Choose wählt einen Monitor nach Primary, Mouse oder Namen. Fehlt der
+// gewünschte Monitor, z.B. weil er abgesteckt ist, wird der Hauptmonitor
+// verwendet.
+func Choose(monitors []Monitor, want string) Monitor {
+	switch want {
+	case "", Primary:
+	case Mouse:
+		x, y, err := MousePosition()
+		if err != nil {
+			log.Printf("%v", err)
+			break
+		}
+		if m, ok := At(monitors, x, y); ok {
+			return m
+		}
+	default:
+		for _, m := range monitors {
+			if m.Name == want {
+				return m
+			}
+		}
+		log.Printf("Monitor %s nicht gefunden, verwende %s", want, monitors[0].Name)
+	}
+	return monitors[0]
+}
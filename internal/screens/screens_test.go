@@ -0,0 +1,185 @@
+package screens
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const xrandr = `Monitors: 3
+ 0: +HDMI-1 1920/527x1080/296+2560+0  HDMI-1
+ 1: +*DP-1 2560/597x1440/336+0+0  DP-1
+ 2: +DP-2 1080/300x1920/530+4480+0  DP-2
+`
+
+func TestParseMonitors(t *testing.T) {
+	monitors, err := parseMonitors(xrandr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Der Hauptmonitor steht vorn
+	want := []Monitor{
+		{Name: "DP-1", Width: 2560, Height: 1440, Primary: true},
+		{Name: "HDMI-1", X: 2560, Width: 1920, Height: 1080},
+		{Name: "DP-2", X: 4480, Width: 1080, Height: 1920},
+	}
+	if !reflect.DeepEqual(monitors, want) {
+		t.Errorf("%+v", monitors)
+	}
+
+	for _, out := range []string{"", "Monitors: 0\n", "Monitors: 1\n 0: +*DP-1 2560x1440+0+0  DP-1\n"} {
+		if _, err := parseMonitors(out); err == nil {
+			t.Errorf("%q ohne Fehler gelesen", out)
+		}
+	}
+}
+
+func TestParseRect(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rect
+		wantErr bool
+	}{
+		{"100,50,800,600", Rect{X: 100, Y: 50, Width: 800, Height: 600}, false},
+		{" -1920, 0, 800 ,600", Rect{X: -1920, Width: 800, Height: 600}, false},
+		{"100,50,800", Rect{}, true},
+		{"100,50,800,600,1", Rect{}, true},
+		{"x,50,800,600", Rect{}, true},
+		{"100,50,0,600", Rect{}, true},
+		{"100,50,800,-600", Rect{}, true},
+		{"", Rect{}, true},
+	}
+	for _, tt := range tests {
+		r, err := ParseRect(tt.value)
+		if (err != nil) != tt.wantErr || r != tt.want {
+			t.Errorf("%q: %+v, %v", tt.value, r, err)
+		}
+		if err == nil {
+			if back, _ := ParseRect(r.String()); back != r {
+				t.Errorf("%q: String %s", tt.value, r.String())
+			}
+		}
+	}
+}
+
+func TestAt(t *testing.T) {
+	monitors, _ := parseMonitors(xrandr)
+	tests := []struct {
+		x, y int
+		want string
+	}{
+		{0, 0, "DP-1"},
+		{2559, 1439, "DP-1"},
+		{2560, 0, "HDMI-1"},
+		{3000, 1079, "HDMI-1"},
+		{3000, 1080, ""}, // unter HDMI-1 ist kein Monitor
+		{4480, 1900, "DP-2"},
+		{5560, 0, ""},
+		{-1, 0, ""},
+	}
+	for _, tt := range tests {
+		m, ok := At(monitors, tt.x, tt.y)
+		if ok != (tt.want != "") || m.Name != tt.want {
+			t.Errorf("%d/%d: %s, %v, erwartet %q", tt.x, tt.y, m.Name, ok, tt.want)
+		}
+	}
+}
+
+// fakeXdotool ersetzt xdotool durch ein Skript, das den Mauszeiger bei x/y meldet
+func fakeXdotool(t *testing.T, x, y string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'X=" + x + "\\nY=" + y + "\\nSCREEN=0\\nWINDOW=1\\n'\n"
+	if err := os.WriteFile(filepath.Join(dir, "xdotool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestChoose(t *testing.T) {
+	monitors, _ := parseMonitors(xrandr)
+	tests := []struct {
+		want   string
+		mouseX string
+		result string
+	}{
+		{"", "", "DP-1"},
+		{Primary, "", "DP-1"},
+		{"HDMI-1", "", "HDMI-1"},
+		{"DP-2", "", "DP-2"},
+		{"HDMI-2", "", "DP-1"}, // abgesteckt
+		{Mouse, "3000", "HDMI-1"},
+		{Mouse, "9999", "DP-1"}, // Mauszeiger außerhalb aller Monitore
+		{Mouse, "", "DP-1"},     // ohne xdotool
+	}
+	for _, tt := range tests {
+		if tt.mouseX != "" {
+			fakeXdotool(t, tt.mouseX, "500")
+		} else {
+			t.Setenv("PATH", t.TempDir())
+		}
+		if m := Choose(monitors, tt.want); m.Name != tt.result {
+			t.Errorf("%q: %s, erwartet %s", tt.want, m.Name, tt.result)
+		}
+	}
+}
+
+func TestMousePosition(t *testing.T) {
+	fakeXdotool(t, "3000", "500")
+	if x, y, err := MousePosition(); err != nil || x != 3000 || y != 500 {
+		t.Errorf("%d/%d, %v", x, y, err)
+	}
+	fakeXdotool(t, "", "500")
+	if _, _, err := MousePosition(); err == nil {
+		t.Error("Unvollständige Angabe ohne Fehler gelesen")
+	}
+}
+
+func TestCenter(t *testing.T) {
+	monitors, _ := parseMonitors(xrandr)
+	tests := []struct {
+		monitor       int
+		width, height int
+		x, y          int
+	}{
+		{0, 800, 600, 880, 420},
+		{1, 800, 600, 3120, 240},
+		{1, 1920, 1080, 2560, 0},
+		// Breiter oder höher als der Monitor: an dessen Rand
+		{2, 1920, 1080, 4480, 420},
+		{2, 1200, 2000, 4480, 0},
+	}
+	for _, tt := range tests {
+		m := monitors[tt.monitor]
+		if x, y := m.Center(tt.width, tt.height); x != tt.x || y != tt.y {
+			t.Errorf("%s, %dx%d: %d/%d, erwartet %d/%d", m.Name, tt.width, tt.height, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+// Eine gespeicherte Position gilt nur, wenn sie noch auf dem gewählten
+// Monitor liegt, etwa nach geänderter Anordnung der Monitore
+func TestSavedPosition(t *testing.T) {
+	monitors, _ := parseMonitors(xrandr)
+	hdmi := Choose(monitors, "HDMI-1")
+	tests := []struct {
+		saved string
+		on    bool
+	}{
+		{"2600,100,800,600", true},
+		{"2560,0,1920,1080", true},
+		{"100,100,800,600", false},   // auf DP-1
+		{"2600,1200,800,600", false}, // unterhalb von HDMI-1
+		{"4480,0,800,600", false},    // rechts daneben
+	}
+	for _, tt := range tests {
+		r, err := ParseRect(tt.saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if on := hdmi.Contains(r.X, r.Y); on != tt.on {
+			t.Errorf("%s auf %s: %v", tt.saved, hdmi.Name, on)
+		}
+	}
+}
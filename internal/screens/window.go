@@ -0,0 +1,138 @@
+package screens
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+// Rect ist Position und Größe eines Fensters
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// String liefert das Rechteck als "X,Y,BREITE,HÖHE"
+func (r Rect) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+}
+
+// ParseRect ist die Umkehrung von Rect.String
+func ParseRect(s string) (Rect, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Rect{}, fmt.Errorf("Ungültige Fenstergeometrie: %q", s)
+	}
+	var values [4]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return Rect{}, fmt.Errorf("Ungültige Fenstergeometrie: %q", s)
+		}
+		values[i] = v
+	}
+	r := Rect{X: values[0], Y: values[1], Width: values[2], Height: values[3]}
+	if r.Width <= 0 || r.Height <= 0 {
+		return Rect{}, fmt.Errorf("Ungültige Fenstergeometrie: %q", s)
+	}
+	return r, nil
+}
+
+// windowID liefert die X11-Kennung eines sichtbaren Fensters
+func windowID(w fyne.Window) (string, error) {
+	handle, err := windowHandle(w)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(handle), 10), nil
+}
+
+func windowHandle(w fyne.Window) (uintptr, error) {
+	native, ok := w.(driver.NativeWindow)
+	if !ok {
+		return 0, fmt.Errorf("Fensterposition wird nicht unterstützt")
+	}
+	var handle uintptr
+	native.RunNative(func(context any) {
+		if x11, ok := context.(driver.X11WindowContext); ok {
+			handle = x11.WindowHandle
+		}
+	})
+	if handle == 0 {
+		return 0, fmt.Errorf("Fensterposition wird nur unter X11 unterstützt")
+	}
+	return handle, nil
+}
+
+// Geometry liefert Position und Größe eines sichtbaren Fensters in Pixeln
+func Geometry(w fyne.Window) (Rect, error) {
+	id, err := windowID(w)
+	if err != nil {
+		return Rect{}, err
+	}
+	out, err := exec.Command("xdotool", "getwindowgeometry", "--shell", id).Output()
+	if err != nil {
+		return Rect{}, fmt.Errorf("Fehler beim Abfragen der Fensterposition: %v", err)
+	}
+	values := parseShell(string(out))
+	var r Rect
+	for _, f := range []struct {
+		name  string
+		value *int
+	}{{"X", &r.X}, {"Y", &r.Y}, {"WIDTH", &r.Width}, {"HEIGHT", &r.Height}} {
+		if *f.value, err = strconv.Atoi(values[f.name]); err != nil {
+			return Rect{}, fmt.Errorf("Unbekannte Angabe von xdotool: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return r, nil
+}
+
+// Move verschiebt ein sichtbares Fenster an die Position x/y
+func Move(w fyne.Window, x, y int) error {
+	id, err := windowID(w)
+	if err != nil {
+		return err
+	}
+	out, err := exec.Command("xdotool", "windowmove", id, strconv.Itoa(x), strconv.Itoa(y)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Fehler beim Verschieben des Fensters: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// KeepAbove hält ein sichtbares Fenster über allen anderen, mit wmctrl oder,
+// falls es fehlt, mit xdotool
+func KeepAbove(w fyne.Window) error {
+	handle, err := windowHandle(w)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("wmctrl", "-i", "-r", fmt.Sprintf("0x%x", handle), "-b", "add,above")
+	if _, err := exec.LookPath("wmctrl"); err != nil {
+		cmd = exec.Command("xdotool", "windowstate", "--add", "ABOVE", strconv.FormatUint(uint64(handle), 10))
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("Fehler beim Festlegen im Vordergrund: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// MoveTo verschiebt ein sichtbares Fenster mittig auf den gewählten
+// Monitor (Primary, Mouse oder Name)
+func MoveTo(w fyne.Window, want string) error {
+	monitors, err := List()
+	if err != nil {
+		return err
+	}
+	m := Choose(monitors, want)
+	r, err := Geometry(w)
+	if err != nil {
+		return err
+	}
+	x, y := m.Center(r.Width, r.Height)
+	return Move(w, x, y)
+}
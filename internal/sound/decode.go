@@ -0,0 +1,163 @@
+package sound
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// Load liest eine WAV- oder OGG/Vorbis-Datei; das Format wird am Inhalt erkannt
+func Load(path string) (*Clip, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	switch string(magic) {
+	case "RIFF":
+		return DecodeWAV(r)
+	case "OggS":
+		return DecodeOGG(r)
+	}
+	return nil, fmt.Errorf("%s: unbekanntes Audioformat", path)
+}
+
+// DecodeOGG dekodiert Ogg/Vorbis
+func DecodeOGG(r io.Reader) (*Clip, error) {
+	samples, format, err := oggvorbis.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Dekodieren von OGG: %v", err)
+	}
+	return &Clip{SampleRate: format.SampleRate, Channels: format.Channels, Samples: samples}, nil
+}
+
+// WAV-Formatkennungen aus dem fmt-Chunk
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xFFFE
+)
+
+// DecodeWAV dekodiert RIFF/WAVE mit 8/16/24/32-Bit-PCM oder 32-Bit-Float
+func DecodeWAV(r io.Reader) (*Clip, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("Ungültige WAV-Datei: %v", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("Ungültige WAV-Datei: kein RIFF/WAVE-Header")
+	}
+
+	var format, channels, bits uint16
+	var rate uint32
+	haveFormat := false
+
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("Ungültige WAV-Datei: data-Chunk fehlt")
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			data, err := readChunk(r, size)
+			if err != nil || len(data) < int(size) || size < 16 {
+				return nil, fmt.Errorf("Ungültige WAV-Datei: fmt-Chunk defekt")
+			}
+			format = binary.LittleEndian.Uint16(data[0:2])
+			channels = binary.LittleEndian.Uint16(data[2:4])
+			rate = binary.LittleEndian.Uint32(data[4:8])
+			bits = binary.LittleEndian.Uint16(data[14:16])
+			if format == wavExtensible && size >= 26 {
+				// Die eigentliche Kennung steht am Anfang der SubFormat-GUID
+				format = binary.LittleEndian.Uint16(data[24:26])
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("Ungültige WAV-Datei: data vor fmt")
+			}
+			// Ein abgeschnittener data-Chunk wird so weit wie vorhanden gespielt
+			data, err := readChunk(r, size)
+			if err != nil {
+				return nil, fmt.Errorf("Fehler beim Lesen der WAV-Daten: %v", err)
+			}
+			samples, err := wavSamples(data, format, bits)
+			if err != nil {
+				return nil, err
+			}
+			return &Clip{SampleRate: int(rate), Channels: int(channels), Samples: samples}, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+				return nil, fmt.Errorf("Ungültige WAV-Datei: %v", err)
+			}
+		}
+		// Chunks sind auf gerade Längen aufgefüllt
+		if size%2 == 1 && id != "data" {
+			if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+				return nil, fmt.Errorf("Ungültige WAV-Datei: %v", err)
+			}
+		}
+	}
+}
+
+// readChunk liest höchstens size Bytes. Der Puffer wächst mit den
+// tatsächlich gelesenen Daten, damit eine falsche Größe im Header bei einer
+// kurzen Datei nicht Gigabytes belegt.
+func readChunk(r io.Reader, size uint32) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(r, int64(size)))
+}
+
+func wavSamples(data []byte, format, bits uint16) ([]float32, error) {
+	width := int(bits / 8)
+	if width == 0 {
+		return nil, fmt.Errorf("Ungültige Bittiefe: %d", bits)
+	}
+	samples := make([]float32, len(data)/width)
+	br := bytes.NewReader(data)
+
+	switch {
+	case format == wavPCM && bits == 8:
+		for i := range samples {
+			b, _ := br.ReadByte()
+			samples[i] = (float32(b) - 128) / 128
+		}
+	case format == wavPCM && bits == 16:
+		for i := range samples {
+			v := int16(binary.LittleEndian.Uint16(data[i*2:]))
+			samples[i] = float32(v) / (1 << 15)
+		}
+	case format == wavPCM && bits == 24:
+		for i := range samples {
+			b := data[i*3:]
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			samples[i] = float32(v) / (1 << 23)
+		}
+	case format == wavPCM && bits == 32:
+		for i := range samples {
+			v := int32(binary.LittleEndian.Uint32(data[i*4:]))
+			samples[i] = float32(v) / (1 << 31)
+		}
+	case format == wavFloat && bits == 32:
+		for i := range samples {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+	default:
+		return nil, fmt.Errorf("Nicht unterstütztes WAV-Format %d mit %d Bit", format, bits)
+	}
+	return samples, nil
+}
@@ -0,0 +1,130 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wav baut eine WAV-Datei; dataSize < 0 trägt die Länge von data ein
+func wav(format, channels, bits uint16, rate uint32, data []byte, dataSize int64) []byte {
+	var b bytes.Buffer
+	le := binary.LittleEndian
+	b.WriteString("RIFF")
+	binary.Write(&b, le, uint32(36+len(data)))
+	b.WriteString("WAVE")
+
+	b.WriteString("fmt ")
+	binary.Write(&b, le, uint32(16))
+	binary.Write(&b, le, format)
+	binary.Write(&b, le, channels)
+	binary.Write(&b, le, rate)
+	binary.Write(&b, le, rate*uint32(channels)*uint32(bits/8))
+	binary.Write(&b, le, channels*bits/8)
+	binary.Write(&b, le, bits)
+
+	// Unbekannte Chunks mit ungerader Länge werden übersprungen
+	b.WriteString("LIST")
+	binary.Write(&b, le, uint32(3))
+	b.WriteString("abc\x00")
+
+	if dataSize < 0 {
+		dataSize = int64(len(data))
+	}
+	b.WriteString("data")
+	binary.Write(&b, le, uint32(dataSize))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func le16(values ...int16) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, values)
+	return b.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	floats := make([]byte, 8)
+	binary.LittleEndian.PutUint32(floats, math.Float32bits(0.25))
+	binary.LittleEndian.PutUint32(floats[4:], math.Float32bits(-1))
+
+	tests := []struct {
+		name     string
+		file     []byte
+		channels int
+		want     []float32
+	}{
+		{"PCM 8 Bit", wav(wavPCM, 1, 8, 8000, []byte{128, 192, 0}, -1), 1, []float32{0, 0.5, -1}},
+		{"PCM 16 Bit stereo", wav(wavPCM, 2, 16, 44100, le16(0, 16384, -32768, 0), -1), 2, []float32{0, 0.5, -1, 0}},
+		{"PCM 24 Bit", wav(wavPCM, 1, 24, 48000, []byte{0, 0, 0x40, 0, 0, 0x80}, -1), 1, []float32{0.5, -1}},
+		{"Float 32 Bit", wav(wavFloat, 1, 32, 48000, floats, -1), 1, []float32{0.25, -1}},
+		// Der Header verspricht 4 GB, die Datei endet nach zwei Samples
+		{"abgeschnitten", wav(wavPCM, 1, 16, 8000, le16(16384, 0), math.MaxUint32), 1, []float32{0.5, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := DecodeWAV(bytes.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Channels != tt.channels || len(c.Samples) != len(tt.want) {
+				t.Fatalf("%d Kanäle, %d Samples: %v", c.Channels, len(c.Samples), c.Samples)
+			}
+			for i, want := range tt.want {
+				if math.Abs(float64(c.Samples[i]-want)) > 1e-6 {
+					t.Errorf("Sample %d = %v, erwartet %v", i, c.Samples[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeWAVErrors(t *testing.T) {
+	valid := wav(wavPCM, 1, 16, 8000, le16(1, 2), -1)
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"leer", nil},
+		{"kein WAVE", append([]byte("RIFF\x00\x00\x00\x00AVI "), valid[12:]...)},
+		{"ohne data", valid[:12+8+16]},
+		{"fmt abgeschnitten", valid[:12+8+10]},
+		{"nicht unterstützt", wav(wavPCM, 1, 12, 8000, []byte{1, 2, 3}, -1)},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeWAV(bytes.NewReader(tt.file)); err == nil {
+			t.Errorf("%s: kein Fehler", tt.name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ton.wav")
+	if err := os.WriteFile(path, wav(wavPCM, 1, 16, 8000, make([]byte, 16000), -1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var cache Cache
+	c, err := cache.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := c.Duration(); d != time.Second {
+		t.Errorf("Dauer %v, erwartet 1s", d)
+	}
+	if again, _ := cache.Load(path); again != c {
+		t.Error("Cache hat die Datei erneut geladen")
+	}
+
+	other := filepath.Join(dir, "text.wav")
+	if err := os.WriteFile(other, []byte("kein Ton"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(other); err == nil {
+		t.Error("Unbekanntes Format ohne Fehler geladen")
+	}
+}
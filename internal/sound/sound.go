@@ -0,0 +1,155 @@
+package sound
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jfreymuth/pulse"
+)
+
+// Clip ist ein dekodierter Ton: verschachtelte float32-Samples im Bereich -1..1
+type Clip struct {
+	SampleRate int
+	Channels   int
+	Samples    []float32
+}
+
+// Duration liefert die Spieldauer des Tons
+func (c *Clip) Duration() time.Duration {
+	if c.SampleRate == 0 || c.Channels == 0 {
+		return 0
+	}
+	frames := len(c.Samples) / c.Channels
+	return time.Duration(frames) * time.Second / time.Duration(c.SampleRate)
+}
+
+// Sink gibt Töne aus. Play blockiert, bis der Ton zu Ende gespielt ist.
+type Sink interface {
+	Play(c *Clip, volume float64) error
+}
+
+// DefaultSink liefert die Audioausgabe des Systems. Mit REMINDER_AUDIO=null
+// werden Töne verworfen, z.B. auf Rechnern ohne Soundkarte.
+func DefaultSink() Sink {
+	if os.Getenv("REMINDER_AUDIO") == "null" {
+		return &NullSink{}
+	}
+	return &PulseSink{}
+}
+
+// PulseSink spielt Töne über PulseAudio bzw. PipeWire ab, ohne cgo
+type PulseSink struct{}
+
+func (s *PulseSink) Play(c *Clip, volume float64) error {
+	client, err := pulse.NewClient(pulse.ClientApplicationName("Reminder App"))
+	if err != nil {
+		return fmt.Errorf("Keine Verbindung zu PulseAudio: %v", err)
+	}
+	defer client.Close()
+
+	channels := pulse.PlaybackMono
+	if c.Channels == 2 {
+		channels = pulse.PlaybackStereo
+	} else if c.Channels != 1 {
+		return fmt.Errorf("Nicht unterstützte Kanalanzahl: %d", c.Channels)
+	}
+
+	pos := 0
+	reader := pulse.Float32Reader(func(out []float32) (int, error) {
+		n := copy(out, c.Samples[pos:])
+		for i := 0; i < n; i++ {
+			out[i] *= float32(volume)
+		}
+		pos += n
+		if pos >= len(c.Samples) {
+			return n, pulse.EndOfData
+		}
+		return n, nil
+	})
+
+	stream, err := client.NewPlayback(reader,
+		channels,
+		pulse.PlaybackSampleRate(c.SampleRate),
+		pulse.PlaybackLatency(.1),
+		pulse.PlaybackMediaName("Terminerinnerung"))
+	if err != nil {
+		return fmt.Errorf("Fehler beim Öffnen der Audioausgabe: %v", err)
+	}
+	defer stream.Close()
+
+	stream.Start()
+	stream.Drain()
+	return stream.Error()
+}
+
+// Played ist ein Eintrag im Protokoll des NullSink
+type Played struct {
+	Clip   *Clip
+	Volume float64
+}
+
+// NullSink verwirft alle Töne und merkt sich nur, was abgespielt worden wäre
+type NullSink struct {
+	mu     sync.Mutex
+	played []Played
+}
+
+func (s *NullSink) Play(c *Clip, volume float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.played = append(s.played, Played{Clip: c, Volume: volume})
+	return nil
+}
+
+// Played liefert alle bisher "abgespielten" Töne
+func (s *NullSink) Played() []Played {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Played(nil), s.played...)
+}
+
+// Beeps erzeugt einen Signalton aus count kurzen Pieptönen
+func Beeps(freq float64, count int) *Clip {
+	const rate = 44100
+	const beep = 150 * time.Millisecond
+	const pause = 100 * time.Millisecond
+
+	beepFrames := int(beep.Seconds() * rate)
+	pauseFrames := int(pause.Seconds() * rate)
+	c := &Clip{SampleRate: rate, Channels: 1}
+	for i := 0; i < count; i++ {
+		for f := 0; f < beepFrames; f++ {
+			// Kurzes Ein- und Ausblenden verhindert Knacken
+			envelope := math.Min(1, math.Min(float64(f), float64(beepFrames-f))/200)
+			c.Samples = append(c.Samples, float32(0.5*envelope*math.Sin(2*math.Pi*freq*float64(f)/rate)))
+		}
+		c.Samples = append(c.Samples, make([]float32, pauseFrames)...)
+	}
+	return c
+}
+
+// Cache lädt Tondateien nur einmal
+type Cache struct {
+	mu    sync.Mutex
+	clips map[string]*Clip
+}
+
+func (c *Cache) Load(path string) (*Clip, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if clip, ok := c.clips[path]; ok {
+		return clip, nil
+	}
+	clip, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.clips == nil {
+		c.clips = make(map[string]*Clip)
+	}
+	c.clips[path] = clip
+	return clip, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bestätigte Erinnerungen, je Termin und Fälligkeit. Wird ein Termin
+// verschoben, gilt die Bestätigung für den neuen Zeitpunkt nicht mehr.
+const acksSQL = `
+CREATE TABLE IF NOT EXISTS reminder_acks (
+	appointment_id INTEGER NOT NULL,
+	due TEXT NOT NULL,  -- YYYY-MM-DD HH:MM
+	acknowledged_at TEXT NOT NULL,
+	PRIMARY KEY (appointment_id, due)
+);
+`
+
+const dueLayout = "2006-01-02 15:04"
+
+// Due liefert den Zeitpunkt des Termins in lokaler Zeit
+func (a Appointment) Due() (time.Time, error) {
+	if a.Time == "" {
+		return time.Time{}, fmt.Errorf("Keine gültige Zeit für Termin ID=%d", a.ID)
+	}
+	return time.ParseInLocation(dueLayout, a.Date+" "+a.Time, time.Local)
+}
+
+// Acknowledge vermerkt, dass die Erinnerung an einen Termin bestätigt wurde
+func (s *Store) Acknowledge(id int64, due time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO reminder_acks (appointment_id, due, acknowledged_at)
+		VALUES (?, ?, ?)`,
+		id, due.Format(dueLayout), time.Now().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("Fehler beim Bestätigen der Erinnerung: %v", err)
+	}
+	s.notify(Change{Table: TableReminderAcks, ID: id})
+	return nil
+}
+
+// Acknowledged prüft, ob die Erinnerung bereits bestätigt wurde
+func (s *Store) Acknowledged(id int64, due time.Time) (bool, error) {
+	var n int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM reminder_acks WHERE appointment_id = ? AND due = ?",
+		id, due.Format(dueLayout)).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("Fehler beim Abrufen der Bestätigung: %v", err)
+	}
+	return n > 0, nil
+}
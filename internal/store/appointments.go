@@ -0,0 +1,247 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+)
+
+// Struktur für Termine
+type Appointment struct {
+	ID       int64
+	Title    string
+	Date     string // YYYY-MM-DD
+	Time     string // HH:MM, leer wenn keine Zeit gesetzt ist
+	Priority *int   // nil = keine Priorität
+	Notes    string
+	Tags     []string
+	EndTime  string           // HH:MM, leer wenn kein Ende bekannt ist
+	Alarms   []priority.Alarm // eigene Vorwarnungen zusätzlich zu denen der Priorität
+	UID      string           // iCalendar-UID, leer bei hier angelegten Terminen
+
+	SubscriptionID int64 // abonnierter Kalender, 0 bei eigenen Terminen
+	Sync           SyncState
+}
+
+// ErrReadOnly wird geliefert, wenn ein Termin aus einem abonnierten
+// Kalender geändert oder gelöscht werden soll
+var ErrReadOnly = errors.New("Termine aus abonnierten Kalendern können nicht geändert werden")
+
+// ReadOnly gibt an, ob der Termin aus einem abonnierten Kalender stammt
+// und daher nicht geändert werden kann
+func (a Appointment) ReadOnly() bool {
+	return a.SubscriptionID != 0
+}
+
+const appointmentColumns = "id, title, date, time, priority, notes, tags, end_time, alarms, uid, subscription_id, href, dirty, modified"
+
+func scanAppointment(row interface{ Scan(...interface{}) error }) (Appointment, error) {
+	var a Appointment
+	var title, date, timeStr sql.NullString
+	var prio sql.NullInt64
+	var tags, alarms, modified string
+	if err := row.Scan(&a.ID, &title, &date, &timeStr, &prio, &a.Notes, &tags, &a.EndTime, &alarms, &a.UID,
+		&a.SubscriptionID, &a.Sync.Href, &a.Sync.Dirty, &modified); err != nil {
+		return a, err
+	}
+	a.Sync.Modified = parseModified(modified)
+	a.Title = title.String
+	a.Date = date.String
+	a.Time = timeStr.String
+	if prio.Valid {
+		p := int(prio.Int64)
+		a.Priority = &p
+	}
+	a.Tags = SplitTags(tags)
+	var err error
+	a.Alarms, err = priority.ParseAlarms(alarms)
+	return a, err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// QueryAppointments liefert alle Termine, die zum Filter passen
+func (s *Store) QueryAppointments(f Filter) ([]Appointment, error) {
+	clause, args := s.buildQuery(TableAppointments, "date", f)
+	rows, err := s.db.Query("SELECT "+appointmentColumns+" FROM appointments"+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Termine: %v", err)
+	}
+	defer rows.Close()
+
+	var appointments []Appointment
+	for rows.Next() {
+		a, err := scanAppointment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Fehler beim Scannen der Termine: %v", err)
+		}
+		appointments = append(appointments, a)
+	}
+	return appointments, rows.Err()
+}
+
+// GetAppointment lädt einen einzelnen Termin
+func (s *Store) GetAppointment(id int64) (Appointment, error) {
+	row := s.db.QueryRow("SELECT "+appointmentColumns+" FROM appointments WHERE id = ?", id)
+	return scanAppointment(row)
+}
+
+// AppointmentByUID sucht einen eigenen Termin anhand seiner iCalendar-UID
+// und liefert sql.ErrNoRows, wenn es keinen gibt
+func (s *Store) AppointmentByUID(uid string) (Appointment, error) {
+	row := s.db.QueryRow("SELECT "+appointmentColumns+
+		" FROM appointments WHERE uid = ? AND uid != '' AND subscription_id = 0 LIMIT 1", uid)
+	return scanAppointment(row)
+}
+
+const insertAppointmentSQL = `
+	INSERT INTO appointments (title, date, time, priority, notes, tags, end_time, alarms, uid, subscription_id, href, dirty, modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func insertAppointmentArgs(a *Appointment) []interface{} {
+	return []interface{}{a.Title, a.Date, nullString(a.Time), a.Priority, a.Notes, JoinTags(a.Tags),
+		a.EndTime, priority.FormatAlarms(a.Alarms), a.UID, a.SubscriptionID,
+		a.Sync.Href, a.Sync.Dirty, formatModified(a.Sync.Modified)}
+}
+
+// AddAppointment speichert einen neuen Termin und setzt dessen ID
+func (s *Store) AddAppointment(a *Appointment) error {
+	a.Sync = SyncState{Modified: time.Now()}
+	res, err := s.db.Exec(insertAppointmentSQL, insertAppointmentArgs(a)...)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Termins: %v", err)
+	}
+	a.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.reindex(TableAppointments, a.ID)
+	s.notify(Change{Table: TableAppointments, ID: a.ID})
+	s.record(Operation{Kind: OpCreate, Table: TableAppointments, Title: a.Title, Count: 1},
+		snapshot{}, snapshot{Appointments: []Appointment{*a}})
+	return nil
+}
+
+// UpdateAppointment überschreibt einen bestehenden Termin. Termine aus
+// abonnierten Kalendern liefern ErrReadOnly.
+func (s *Store) UpdateAppointment(a Appointment) error {
+	if err := s.checkWritable(a.ID); err != nil {
+		return err
+	}
+	before, journalErr := s.GetAppointment(a.ID)
+	if err := writeAppointment(s.db, a); err != nil {
+		return err
+	}
+	s.reindex(TableAppointments, a.ID)
+	s.notify(Change{Table: TableAppointments, ID: a.ID})
+	if after, err := s.GetAppointment(a.ID); journalErr == nil && err == nil {
+		s.record(Operation{Kind: OpEdit, Table: TableAppointments, Title: a.Title, Count: 1},
+			snapshot{Appointments: []Appointment{before}}, snapshot{Appointments: []Appointment{after}})
+	}
+	return nil
+}
+
+// writeAppointment schreibt die Inhalte eines bestehenden Termins und
+// markiert ihn für den CalDAV-Abgleich
+func writeAppointment(db execer, a Appointment) error {
+	_, err := db.Exec(`
+		UPDATE appointments
+		SET title = ?, date = ?, time = ?, priority = ?, notes = ?, tags = ?, end_time = ?, alarms = ?, uid = ?,
+			dirty = 1, modified = ?
+		WHERE id = ?`,
+		a.Title, a.Date, nullString(a.Time), a.Priority, a.Notes, JoinTags(a.Tags),
+		a.EndTime, priority.FormatAlarms(a.Alarms), a.UID, formatModified(time.Now()), a.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Aktualisieren des Termins: %v", err)
+	}
+	return nil
+}
+
+// PostponeAppointment verschiebt einen Termin samt Ende um d und liefert
+// den neuen Stand
+func (s *Store) PostponeAppointment(id int64, d time.Duration) (Appointment, error) {
+	a, err := s.GetAppointment(id)
+	if err != nil {
+		return a, err
+	}
+	start, err := a.Due()
+	if err != nil {
+		return a, err
+	}
+	moved := start.Add(d)
+	a.Date = moved.Format("2006-01-02")
+	a.Time = moved.Format("15:04")
+	if end, err := time.Parse("15:04", a.EndTime); err == nil {
+		a.EndTime = end.Add(d).Format("15:04")
+	}
+	return a, s.UpdateAppointment(a)
+}
+
+// DeleteAppointment löscht einen Termin und liefert den Eintrag im
+// Änderungsjournal, etwa für UndoOp; ohne Eintrag ist dessen ID 0
+func (s *Store) DeleteAppointment(id int64) (Operation, error) {
+	op := Operation{Kind: OpDelete, Table: TableAppointments, Count: 1}
+	if err := s.checkWritable(id); err != nil {
+		return op, err
+	}
+	before, journalErr := s.GetAppointment(id)
+	if err := removeAppointment(s.db, id); err != nil {
+		return op, err
+	}
+	s.reindex(TableAppointments, id)
+	s.notify(Change{Table: TableAppointments, ID: id})
+	if journalErr == nil {
+		op.Title = before.Title
+		op = s.record(op, snapshot{Appointments: []Appointment{before}}, snapshot{})
+	}
+	return op, nil
+}
+
+func removeAppointment(db execer, id int64) error {
+	if _, err := db.Exec("DELETE FROM appointments WHERE id = ?", id); err != nil {
+		return fmt.Errorf("Fehler beim Löschen des Termins: %v", err)
+	}
+	if _, err := db.Exec("DELETE FROM reminder_acks WHERE appointment_id = ?", id); err != nil {
+		log.Printf("Fehler beim Löschen der Bestätigungen: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) checkWritable(id int64) error {
+	var subscription int64
+	err := s.db.QueryRow("SELECT subscription_id FROM appointments WHERE id = ?", id).Scan(&subscription)
+	if err == nil && subscription != 0 {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// DeleteAllAppointments löscht alle eigenen Termine und liefert den Eintrag
+// im Änderungsjournal; Termine aus abonnierten Kalendern bleiben erhalten
+func (s *Store) DeleteAllAppointments() (Operation, error) {
+	op := Operation{Kind: OpDelete, Table: TableAppointments}
+	before, journalErr := s.QueryAppointments(Filter{OwnOnly: true})
+	if _, err := s.db.Exec("DELETE FROM appointments WHERE subscription_id = 0"); err != nil {
+		return op, fmt.Errorf("Fehler beim Löschen aller Termine: %v", err)
+	}
+	if _, err := s.db.Exec("DELETE FROM reminder_acks WHERE appointment_id NOT IN (SELECT id FROM appointments)"); err != nil {
+		log.Printf("Fehler beim Löschen der Bestätigungen: %v", err)
+	}
+	if s.fts {
+		if _, err := s.db.Exec("DELETE FROM appointments_fts WHERE rowid NOT IN (SELECT id FROM appointments)"); err != nil {
+			log.Printf("Fehler beim Aktualisieren des Suchindex: %v", err)
+		}
+	}
+	s.notify(Change{Table: TableAppointments})
+	if journalErr == nil && len(before) > 0 {
+		op.Title, op.Count = before[0].Title, len(before)
+		op = s.record(op, snapshot{Appointments: before}, snapshot{})
+	}
+	return op, nil
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Zuletzt bekannter Stand der Objekte auf dem CalDAV-Server. Termine und
+// Aufgaben verweisen über href darauf; ein Objekt ohne zugehörige Einträge
+// wurde hier gelöscht und wird beim nächsten Abgleich auf dem Server entfernt.
+const caldavSQL = `
+CREATE TABLE IF NOT EXISTS caldav_objects (
+	href TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,  -- appointments oder tasks
+	etag TEXT NOT NULL DEFAULT '',
+	data TEXT NOT NULL,  -- iCalendar-Inhalt wie auf dem Server
+	series BOOLEAN NOT NULL DEFAULT 0,
+	applied TEXT NOT NULL DEFAULT ''  -- Tag, an dem Wiederholungen zuletzt aufgelöst wurden
+);
+`
+
+// SyncState ist der Abgleichstand eines Termins oder einer Aufgabe
+type SyncState struct {
+	Href     string    // Objekt auf dem CalDAV-Server, leer = noch nicht übertragen
+	Dirty    bool      // hier geändert seit dem letzten Abgleich
+	Modified time.Time // letzte Änderung hier
+}
+
+func parseModified(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+func formatModified(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// RemoteObject ist ein Objekt auf dem CalDAV-Server
+type RemoteObject struct {
+	Href    string
+	Kind    string // TableAppointments oder TableTasks
+	ETag    string
+	Data    string
+	Series  bool   // Serie oder nicht übernehmbarer Inhalt: wird hier weder geändert noch gelöscht
+	Applied string // YYYY-MM-DD
+}
+
+// RemoteObjects liefert alle bekannten Objekte des CalDAV-Servers nach href
+func (s *Store) RemoteObjects() (map[string]RemoteObject, error) {
+	rows, err := s.db.Query("SELECT href, kind, etag, data, series, applied FROM caldav_objects")
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Abgleichstands: %v", err)
+	}
+	defer rows.Close()
+
+	objects := make(map[string]RemoteObject)
+	for rows.Next() {
+		var o RemoteObject
+		if err := rows.Scan(&o.Href, &o.Kind, &o.ETag, &o.Data, &o.Series, &o.Applied); err != nil {
+			return nil, err
+		}
+		objects[o.Href] = o
+	}
+	return objects, rows.Err()
+}
+
+func saveRemoteObject(tx *sql.Tx, o RemoteObject) error {
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO caldav_objects (href, kind, etag, data, series, applied)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		o.Href, o.Kind, o.ETag, o.Data, o.Series, o.Applied)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Abgleichstands: %v", err)
+	}
+	return nil
+}
+
+// DeleteRemoteObject vergisst ein Objekt, das es auf dem Server nicht mehr
+// gibt, und löscht die zugehörigen Termine und Aufgaben
+func (s *Store) DeleteRemoteObject(href string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		"DELETE FROM reminder_acks WHERE appointment_id IN (SELECT id FROM appointments WHERE href = ? AND subscription_id = 0)",
+		"DELETE FROM appointments WHERE href = ? AND subscription_id = 0",
+		"DELETE FROM tasks WHERE href = ?",
+		"DELETE FROM caldav_objects WHERE href = ?",
+	} {
+		if _, err := tx.Exec(stmt, href); err != nil {
+			return fmt.Errorf("Fehler beim Löschen von %s: %v", href, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.reindexAll()
+	s.notify(Change{})
+	return nil
+}
+
+// ApplyRemoteAppointments übernimmt den Stand eines Objekts vom Server.
+// Vorhandene Termine mit gleicher UID behalten ihre ID und damit ihre
+// Bestätigungen; lokale Änderungen daran werden verworfen.
+func (s *Store) ApplyRemoteAppointments(o RemoteObject, appointments []Appointment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	existing := make(map[string]int64)
+	rows, err := tx.Query("SELECT id, uid FROM appointments WHERE href = ? AND subscription_id = 0", o.Href)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int64
+		var uid string
+		if err := rows.Scan(&id, &uid); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[uid] = id
+	}
+	rows.Close()
+
+	for i := range appointments {
+		a := &appointments[i]
+		a.SubscriptionID = 0
+		a.Sync = SyncState{Href: o.Href}
+		if id, ok := existing[a.UID]; ok {
+			delete(existing, a.UID)
+			a.ID = id
+			args := append(insertAppointmentArgs(a), id)
+			if _, err := tx.Exec(`
+				UPDATE appointments
+				SET title = ?, date = ?, time = ?, priority = ?, notes = ?, tags = ?, end_time = ?, alarms = ?, uid = ?,
+					subscription_id = ?, href = ?, dirty = ?, modified = ?
+				WHERE id = ?`, args...); err != nil {
+				return fmt.Errorf("Fehler beim Aktualisieren des Termins %q: %v", a.Title, err)
+			}
+			continue
+		}
+		res, err := tx.Exec(insertAppointmentSQL, insertAppointmentArgs(a)...)
+		if err != nil {
+			return fmt.Errorf("Fehler beim Speichern des Termins %q: %v", a.Title, err)
+		}
+		if a.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	}
+	for _, id := range existing {
+		for _, stmt := range []string{
+			"DELETE FROM reminder_acks WHERE appointment_id = ?",
+			"DELETE FROM appointments WHERE id = ?",
+		} {
+			if _, err := tx.Exec(stmt, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	o.Kind = TableAppointments
+	if err := saveRemoteObject(tx, o); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.reindexAll()
+	s.notify(Change{Table: TableAppointments})
+	return nil
+}
+
+// ApplyRemoteTask übernimmt eine Aufgabe vom Server
+func (s *Store) ApplyRemoteTask(o RemoteObject, t Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.Sync = SyncState{Href: o.Href}
+	err = tx.QueryRow("SELECT id FROM tasks WHERE href = ? LIMIT 1", o.Href).Scan(&t.ID)
+	switch {
+	case err == sql.ErrNoRows:
+		res, err := tx.Exec(insertTaskSQL, insertTaskArgs(&t)...)
+		if err != nil {
+			return fmt.Errorf("Fehler beim Speichern der Aufgabe %q: %v", t.Title, err)
+		}
+		if t.ID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		args := append(insertTaskArgs(&t), t.ID)
+		if _, err := tx.Exec(`
+			UPDATE tasks
+			SET title = ?, completed = ?, priority = ?, due_date = ?, notes = ?, tags = ?, uid = ?,
+				href = ?, dirty = ?, modified = ?
+			WHERE id = ?`, args...); err != nil {
+			return fmt.Errorf("Fehler beim Aktualisieren der Aufgabe %q: %v", t.Title, err)
+		}
+	}
+
+	o.Kind = TableTasks
+	if err := saveRemoteObject(tx, o); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.reindex(TableTasks, t.ID)
+	s.notify(Change{Table: TableTasks, ID: t.ID})
+	return nil
+}
+
+// MarkSynced vermerkt, dass ein Termin oder eine Aufgabe auf den Server
+// übertragen wurde. Wurde der Eintrag inzwischen erneut geändert (modified
+// weicht ab), bleibt er für den nächsten Abgleich vorgemerkt.
+func (s *Store) MarkSynced(table string, id int64, uid string, modified time.Time, o RemoteObject) error {
+	if table != TableAppointments && table != TableTasks {
+		return fmt.Errorf("Unbekannte Tabelle: %s", table)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf(
+		"UPDATE %s SET href = ?, uid = ?, dirty = (modified != ?) WHERE id = ?", table),
+		o.Href, uid, formatModified(modified), id); err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Abgleichstands: %v", err)
+	}
+	o.Kind = table
+	if err := saveRemoteObject(tx, o); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PendingSync zählt die Einträge, die noch auf den Server übertragen oder
+// dort gelöscht werden müssen
+func (s *Store) PendingSync() (int, error) {
+	var n int
+	err := s.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM appointments WHERE subscription_id = 0 AND (href = '' OR dirty))
+			+ (SELECT COUNT(*) FROM tasks WHERE href = '' OR dirty)
+			+ (SELECT COUNT(*) FROM caldav_objects o WHERE NOT o.series
+				AND NOT EXISTS (SELECT 1 FROM appointments WHERE href = o.href)
+				AND NOT EXISTS (SELECT 1 FROM tasks WHERE href = o.href))`).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("Fehler beim Zählen der ausstehenden Änderungen: %v", err)
+	}
+	return n, nil
+}
+
+// ResetSync vergisst den Abgleichstand, z.B. nach dem Wechsel des Servers.
+// Alle Einträge werden beim nächsten Abgleich neu übertragen.
+func (s *Store) ResetSync() error {
+	for _, stmt := range []string{
+		"DELETE FROM caldav_objects",
+		"UPDATE appointments SET href = '', dirty = 0 WHERE href != ''",
+		"UPDATE tasks SET href = '', dirty = 0 WHERE href != ''",
+	} {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("Fehler beim Zurücksetzen des Abgleichs: %v", err)
+		}
+	}
+	return nil
+}
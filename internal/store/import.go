@@ -0,0 +1,156 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Endung der UIDs, die Einträge ohne eigene UID beim Export bekommen
+const derivedUIDSuffix = "@reminder-erinnerungs-app"
+
+// Imported fasst das Ergebnis von Import zusammen
+type Imported struct {
+	Appointments int
+	Tasks        int
+	Duplicates   int       // übersprungen, weil die UID schon bekannt ist
+	Operation    Operation // Eintrag im Änderungsjournal, ID 0 ohne neue Einträge
+}
+
+// Import speichert Termine und Aufgaben aus einer Datei in einer Transaktion
+// und trägt sie als eine Änderung ins Journal ein, damit sich der ganze
+// Import auf einmal rückgängig machen lässt. Einträge mit einer schon
+// bekannten UID werden übersprungen, ebenso eigene Exporte von Einträgen
+// ohne UID, siehe DerivedUID.
+func (s *Store) Import(appointments []Appointment, tasks []Task) (Imported, error) {
+	var result Imported
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	var added snapshot
+	for _, a := range appointments {
+		known, err := uidExists(tx, TableAppointments, a.UID, a.Title)
+		if err != nil {
+			return result, err
+		}
+		if known {
+			result.Duplicates++
+			continue
+		}
+		a.SubscriptionID, a.Sync = 0, SyncState{Modified: time.Now()}
+		res, err := tx.Exec(insertAppointmentSQL, insertAppointmentArgs(&a)...)
+		if err != nil {
+			return result, fmt.Errorf("Fehler beim Speichern des Termins: %v", err)
+		}
+		if a.ID, err = res.LastInsertId(); err != nil {
+			return result, err
+		}
+		added.Appointments = append(added.Appointments, a)
+	}
+	for _, t := range tasks {
+		known, err := uidExists(tx, TableTasks, t.UID, t.Title)
+		if err != nil {
+			return result, err
+		}
+		if known {
+			result.Duplicates++
+			continue
+		}
+		t.Sync = SyncState{Modified: time.Now()}
+		res, err := tx.Exec(insertTaskSQL, insertTaskArgs(&t)...)
+		if err != nil {
+			return result, fmt.Errorf("Fehler beim Speichern der Aufgabe: %v", err)
+		}
+		if t.ID, err = res.LastInsertId(); err != nil {
+			return result, err
+		}
+		added.Tasks = append(added.Tasks, t)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("Fehler beim Import: %v", err)
+	}
+
+	result.Appointments, result.Tasks = len(added.Appointments), len(added.Tasks)
+	for _, a := range added.Appointments {
+		s.reindex(TableAppointments, a.ID)
+	}
+	for _, t := range added.Tasks {
+		s.reindex(TableTasks, t.ID)
+	}
+	op := Operation{Kind: OpImport, Count: result.Appointments + result.Tasks}
+	switch {
+	case op.Count == 0:
+		return result, nil
+	case result.Tasks == 0:
+		op.Table, op.Title = TableAppointments, added.Appointments[0].Title
+	case result.Appointments == 0:
+		op.Table, op.Title = TableTasks, added.Tasks[0].Title
+	default:
+		op.Title = added.Appointments[0].Title
+	}
+	s.notify(Change{Table: op.Table})
+	result.Operation = s.record(op, snapshot{}, added)
+	return result, nil
+}
+
+// DerivedUID ist die UID, mit der ein Eintrag ohne eigene UID exportiert
+// wird, z.B. appointment-42@reminder-erinnerungs-app
+func DerivedUID(table string, id int64) string {
+	return fmt.Sprintf("%s-%d%s", uidKind(table), id, derivedUIDSuffix)
+}
+
+func uidKind(table string) string {
+	if table == TableTasks {
+		return "task"
+	}
+	return "appointment"
+}
+
+// derivedID liefert die ID aus einer UID von DerivedUID
+func derivedID(table, uid string) (int64, bool) {
+	rest, ok := strings.CutPrefix(uid, uidKind(table)+"-")
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, derivedUIDSuffix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil
+}
+
+// uidExists prüft, ob es einen eigenen Eintrag mit der iCalendar-UID uid
+// gibt. Eine UID von DerivedUID gilt als bekannt, wenn der Eintrag mit
+// dieser ID noch keine UID und denselben Titel hat; der Titel verhindert,
+// dass der Export einer anderen Datenbank fremde Einträge verdeckt.
+func uidExists(db execer, table, uid, title string) (bool, error) {
+	if uid == "" {
+		return false, nil
+	}
+	query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE (uid = ?"
+	args := []interface{}{uid}
+	if id, ok := derivedID(table, uid); ok {
+		query += " OR (id = ? AND uid = '' AND title = ?)"
+		args = append(args, id, title)
+	}
+	query += ")"
+	if table == TableAppointments {
+		query += " AND subscription_id = 0"
+	}
+	var exists bool
+	if err := db.QueryRow(query+")", args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("Fehler beim Suchen der UID: %v", err)
+	}
+	return exists, nil
+}
+
+// Importer speichert importierte Einträge: der Store selbst oder der Client
+// für reminderd
+type Importer interface {
+	Import(appointments []Appointment, tasks []Task) (Imported, error)
+}
@@ -0,0 +1,79 @@
+package store
+
+import "testing"
+
+func TestImport(t *testing.T) {
+	s := openTest(t)
+	known := Appointment{Title: "Arzt", Date: "2025-03-14", UID: "arzt@example.org"}
+	if err := s.AddAppointment(&known); err != nil {
+		t.Fatal(err)
+	}
+	changes, stop := s.Subscribe()
+	defer stop()
+
+	imported, err := s.Import(
+		[]Appointment{
+			{Title: "Arzt", Date: "2025-03-14", UID: "arzt@example.org"},
+			{Title: "Bank", Date: "2025-03-15", UID: "bank@example.org"},
+			{Title: "Chor", Date: "2025-03-16"},
+		},
+		[]Task{{Title: "Einkaufen", UID: "einkaufen@example.org"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Appointments != 2 || imported.Tasks != 1 || imported.Duplicates != 1 {
+		t.Errorf("Import lieferte %+v", imported)
+	}
+	op := imported.Operation
+	if op.ID == 0 || op.Kind != OpImport || op.Count != 3 || op.Table != "" {
+		t.Errorf("Journaleintrag %+v", op)
+	}
+	if got := op.String(); got != "3 Einträge importiert" {
+		t.Errorf("String() = %q", got)
+	}
+	// Eine Benachrichtigung für den ganzen Import
+	if c := <-changes; c.Table != "" || len(changes) != 0 {
+		t.Errorf("Änderung %+v, danach %d weitere", c, len(changes))
+	}
+
+	// Ein Schritt macht den ganzen Import rückgängig
+	if _, err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(t, s); !equal(got, []string{"Arzt"}) {
+		t.Errorf("nach Undo: %v", got)
+	}
+	if tasks, err := s.QueryTasks(Filter{}); err != nil || len(tasks) != 0 {
+		t.Errorf("Aufgaben nach Undo: %v, %v", tasks, err)
+	}
+
+	if _, err := s.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(t, s); !equal(got, []string{"Arzt", "Bank", "Chor"}) {
+		t.Errorf("nach Redo: %v", got)
+	}
+	if _, err := s.TaskByUID("einkaufen@example.org"); err != nil {
+		t.Errorf("Aufgabe nach Redo: %v", err)
+	}
+
+	// Eigene Exporte von Einträgen ohne UID werden wiedererkannt, fremde
+	// Einträge mit derselben ID nicht
+	all, err := s.QueryAppointments(Filter{SortBy: SortTitle})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("Termine: %v, %v", all, err)
+	}
+	imported, err = s.Import([]Appointment{
+		{Title: "Chor", Date: "2025-03-16", UID: DerivedUID(TableAppointments, all[2].ID)},
+		{Title: "Zahnarzt", Date: "2025-03-17", UID: DerivedUID(TableAppointments, known.ID)},
+	}, nil)
+	if err != nil || imported.Appointments != 1 || imported.Duplicates != 1 {
+		t.Errorf("Import eigener Exporte: %+v, %v", imported, err)
+	}
+
+	// Ohne neue Einträge kein Journaleintrag
+	imported, err = s.Import([]Appointment{{Title: "Bank", Date: "2025-03-15", UID: "bank@example.org"}}, nil)
+	if err != nil || imported.Duplicates != 1 || imported.Operation.ID != 0 {
+		t.Errorf("wiederholter Import: %+v, %v", imported, err)
+	}
+}
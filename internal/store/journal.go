@@ -0,0 +1,430 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+)
+
+// Änderungsjournal für Rückgängig und Wiederherstellen. Jeder Eintrag hält
+// die betroffenen Termine und Aufgaben vor und nach der Änderung als JSON;
+// Rückgängig stellt den Stand davor her, Wiederherstellen den danach.
+const journalSQL = `
+CREATE TABLE IF NOT EXISTS journal (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,        -- create, edit, complete, delete oder import
+	item_table TEXT NOT NULL,  -- appointments, tasks oder leer für beide
+	title TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	before TEXT NOT NULL,      -- snapshot der Einträge vor der Änderung
+	after TEXT NOT NULL,       -- und danach
+	undone BOOLEAN NOT NULL DEFAULT 0,
+	created TEXT NOT NULL
+);
+`
+
+// So viele Änderungen lassen sich rückgängig machen
+const journalLimit = 100
+
+// Arten von Änderungen im Journal
+const (
+	OpCreate   = "create"
+	OpEdit     = "edit"
+	OpComplete = "complete" // Aufgabe als erledigt markiert
+	OpDelete   = "delete"
+	OpImport   = "import" // Termine und Aufgaben aus einer Datei
+)
+
+var (
+	ErrNothingToUndo = errors.New("Nichts rückgängig zu machen")
+	ErrNothingToRedo = errors.New("Nichts wiederherzustellen")
+	// ErrUndoConflict meldet UndoOp und RedoOp, wenn spätere Änderungen
+	// dieselben Einträge betreffen
+	ErrUndoConflict = errors.New("Die Einträge wurden seitdem erneut geändert")
+)
+
+// Operation ist ein Eintrag im Änderungsjournal
+type Operation struct {
+	ID      int64
+	Kind    string // OpCreate, OpEdit, OpComplete, OpDelete oder OpImport
+	Table   string // TableAppointments, TableTasks oder leer für beide
+	Title   string // Titel des (ersten) betroffenen Eintrags
+	Count   int    // Anzahl der betroffenen Einträge
+	Undone  bool   // rückgängig gemacht und nicht wiederhergestellt
+	Created time.Time
+}
+
+// String beschreibt die Änderung in der eingestellten Sprache, z.B.
+// Termin "Zahnarzt" gelöscht
+func (op Operation) String() string {
+	if op.Kind == OpImport {
+		return i18n.T("undo.import", "Count", op.Count)
+	}
+	return i18n.T("undo."+op.Table+"."+op.Kind, "Title", op.Title, "Count", op.Count)
+}
+
+// snapshot hält die von einer Änderung betroffenen Einträge vor oder nach ihr
+type snapshot struct {
+	Appointments []Appointment `json:"appointments,omitempty"`
+	Tasks        []Task        `json:"tasks,omitempty"`
+}
+
+// record trägt eine Änderung ins Journal ein und liefert den Eintrag mit
+// seiner ID. Ein neuer Eintrag verwirft, was rückgängig gemacht und noch
+// nicht wiederhergestellt wurde. Fehler werden nur protokolliert, die
+// Änderung selbst ist schon gespeichert; die ID bleibt dann 0.
+func (s *Store) record(op Operation, before, after snapshot) Operation {
+	op.Created = time.Now()
+	id, err := s.writeJournal(op, before, after)
+	// Das Journal wird erst nach notify geschrieben
+	s.wrote()
+	if err != nil {
+		log.Printf("Fehler beim Schreiben des Änderungsjournals: %v", err)
+		return op
+	}
+	op.ID = id
+	return op
+}
+
+func (s *Store) writeJournal(op Operation, before, after snapshot) (int64, error) {
+	b, err := json.Marshal(before)
+	if err != nil {
+		return 0, err
+	}
+	a, err := json.Marshal(after)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM journal WHERE undone = 1"); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`
+		INSERT INTO journal (kind, item_table, title, count, before, after, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		op.Kind, op.Table, op.Title, op.Count, string(b), string(a), formatModified(op.Created))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("DELETE FROM journal WHERE id <= ?", id-journalLimit); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+const journalColumns = "id, kind, item_table, title, count, undone, created"
+
+func scanOperation(row interface{ Scan(...interface{}) error }, extra ...interface{}) (Operation, error) {
+	var op Operation
+	var created string
+	err := row.Scan(append([]interface{}{&op.ID, &op.Kind, &op.Table, &op.Title, &op.Count, &op.Undone, &created}, extra...)...)
+	op.Created = parseModified(created)
+	return op, err
+}
+
+// Journal liefert die letzten n Änderungen, die neueste zuerst
+func (s *Store) Journal(n int) ([]Operation, error) {
+	rows, err := s.db.Query("SELECT "+journalColumns+" FROM journal ORDER BY id DESC LIMIT ?", n)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen des Änderungsjournals: %v", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+// execer ist *sql.DB oder *sql.Tx, damit ein Schritt im Journal in einer
+// Transaktion laufen kann
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Undo macht die letzte Änderung rückgängig, die noch nicht rückgängig
+// gemacht wurde, und liefert sie. Spätere Änderungen durch den CalDAV-Abgleich
+// oder an Abos stehen nicht im Journal und werden dabei überschrieben.
+func (s *Store) Undo() (Operation, error) {
+	return s.step("undone = 0 ORDER BY id DESC", true)
+}
+
+// Redo stellt die zuletzt rückgängig gemachte Änderung wieder her
+func (s *Store) Redo() (Operation, error) {
+	return s.step("undone = 1 ORDER BY id", false)
+}
+
+// UndoOp macht genau die Änderung mit der ID id rückgängig, z.B. die aus
+// einer Meldung nach dem Löschen. Haben spätere Änderungen dieselben
+// Einträge geändert, liefert es ErrUndoConflict.
+func (s *Store) UndoOp(id int64) (Operation, error) {
+	return s.step("undone = 0 AND id = ?", true, id)
+}
+
+// RedoOp stellt genau die rückgängig gemachte Änderung mit der ID id wieder her
+func (s *Store) RedoOp(id int64) (Operation, error) {
+	return s.step("undone = 1 AND id = ?", false, id)
+}
+
+// step führt Rückgängig oder Wiederherstellen samt Eintrag im Journal in
+// einer Transaktion aus, damit ein Fehler keinen halben Stand hinterlässt
+func (s *Store) step(where string, undo bool, args ...interface{}) (Operation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Operation{}, err
+	}
+	defer tx.Rollback()
+
+	var b, a string
+	op, err := scanOperation(tx.QueryRow("SELECT "+journalColumns+", before, after FROM journal WHERE "+where+" LIMIT 1", args...),
+		&b, &a)
+	if errors.Is(err, sql.ErrNoRows) {
+		if undo {
+			return op, ErrNothingToUndo
+		}
+		return op, ErrNothingToRedo
+	}
+	if err != nil {
+		return op, fmt.Errorf("Fehler beim Lesen des Änderungsjournals: %v", err)
+	}
+	before, after, err := parseSnapshots(b, a)
+	if err != nil {
+		return op, err
+	}
+	if err := checkLater(tx, op, before, after); err != nil {
+		return op, err
+	}
+
+	from, to := after, before
+	if !undo {
+		from, to = before, after
+	}
+	appointments, err := restore(tx, from.Appointments, to.Appointments, removeAppointment, putAppointment)
+	if err != nil {
+		return op, err
+	}
+	tasks, err := restore(tx, from.Tasks, to.Tasks, removeTask, putTask)
+	if err != nil {
+		return op, err
+	}
+	if _, err := tx.Exec("UPDATE journal SET undone = ? WHERE id = ?", undo, op.ID); err != nil {
+		return op, fmt.Errorf("Fehler beim Schreiben des Änderungsjournals: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return op, fmt.Errorf("Fehler beim Schreiben des Änderungsjournals: %v", err)
+	}
+
+	for _, id := range appointments {
+		s.reindex(TableAppointments, id)
+	}
+	for _, id := range tasks {
+		s.reindex(TableTasks, id)
+	}
+	switch {
+	case len(appointments)+len(tasks) == 1 && op.Table != "":
+		s.notify(Change{Table: op.Table, ID: append(appointments, tasks...)[0]})
+	case len(tasks) == 0:
+		s.notify(Change{Table: TableAppointments})
+	case len(appointments) == 0:
+		s.notify(Change{Table: TableTasks})
+	default:
+		s.notify(Change{})
+	}
+	op.Undone = undo
+	return op, nil
+}
+
+func parseSnapshots(lists ...string) (before, after snapshot, err error) {
+	snapshots := []*snapshot{&before, &after}
+	for i, list := range lists {
+		if err := json.Unmarshal([]byte(list), snapshots[i]); err != nil {
+			return before, after, fmt.Errorf("Fehler beim Lesen des Änderungsjournals: %v", err)
+		}
+	}
+	return before, after, nil
+}
+
+// checkLater liefert ErrUndoConflict, wenn eine spätere, noch gültige
+// Änderung einen der Einträge von op betrifft
+func checkLater(tx *sql.Tx, op Operation, before, after snapshot) error {
+	ids := journalIDs(before, after)
+	rows, err := tx.Query("SELECT before, after FROM journal WHERE id > ? AND undone = 0", op.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Lesen des Änderungsjournals: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b, a string
+		if err := rows.Scan(&b, &a); err != nil {
+			return err
+		}
+		laterBefore, laterAfter, err := parseSnapshots(b, a)
+		if err != nil {
+			return err
+		}
+		for id := range journalIDs(laterBefore, laterAfter) {
+			if ids[id] {
+				return ErrUndoConflict
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// Eintrag in einer Tabelle, für checkLater
+type itemKey struct {
+	table string
+	id    int64
+}
+
+// Tabelle und ID der Einträge in den Snapshots eines Journaleintrags
+func journalIDs(snapshots ...snapshot) map[itemKey]bool {
+	ids := map[itemKey]bool{}
+	for _, s := range snapshots {
+		for _, a := range s.Appointments {
+			ids[itemKey{TableAppointments, a.ID}] = true
+		}
+		for _, t := range s.Tasks {
+			ids[itemKey{TableTasks, t.ID}] = true
+		}
+	}
+	return ids
+}
+
+// Eintrag mit ID, für restore
+type journaled interface {
+	Appointment | Task
+}
+
+func itemID[T journaled](item T) int64 {
+	switch v := any(item).(type) {
+	case Appointment:
+		return v.ID
+	case Task:
+		return v.ID
+	}
+	return 0
+}
+
+// restore bringt die Einträge vom Stand from auf den Stand to: was in to
+// fehlt, wird gelöscht, alles andere mit seiner alten ID geschrieben. Liefert
+// die IDs der geänderten Einträge.
+func restore[T journaled](db execer, old, state []T, remove func(execer, int64) error, put func(execer, T) error) ([]int64, error) {
+	keep := make(map[int64]bool, len(state))
+	for _, item := range state {
+		keep[itemID(item)] = true
+	}
+	var changed []int64
+	for _, item := range old {
+		if id := itemID(item); !keep[id] {
+			if err := remove(db, id); err != nil {
+				return nil, err
+			}
+			changed = append(changed, id)
+		}
+	}
+	for _, item := range state {
+		if err := put(db, item); err != nil {
+			return nil, err
+		}
+		changed = append(changed, itemID(item))
+	}
+	return changed, nil
+}
+
+// putAppointment schreibt einen Termin aus dem Journal zurück: ersetzt ihn
+// oder legt ihn mit seiner alten ID neu an. Ist sein Objekt auf dem
+// CalDAV-Server inzwischen gelöscht, wird er dort neu angelegt.
+func putAppointment(db execer, a Appointment) error {
+	exists, err := rowExists(db, TableAppointments, a.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return writeAppointment(db, a)
+	}
+	if err := checkHref(db, &a.Sync); err != nil {
+		return err
+	}
+	a.Sync.Dirty, a.Sync.Modified = true, time.Now()
+	res, err := db.Exec(insertAppointmentSQL, insertAppointmentArgs(&a)...)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Wiederherstellen des Termins: %v", err)
+	}
+	return restoreID(db, res, TableAppointments, a.ID)
+}
+
+// putTask schreibt eine Aufgabe aus dem Journal zurück, wie putAppointment
+func putTask(db execer, t Task) error {
+	exists, err := rowExists(db, TableTasks, t.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return writeTask(db, t)
+	}
+	if err := checkHref(db, &t.Sync); err != nil {
+		return err
+	}
+	t.Sync.Dirty, t.Sync.Modified = true, time.Now()
+	res, err := db.Exec(insertTaskSQL, insertTaskArgs(&t)...)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Wiederherstellen der Aufgabe: %v", err)
+	}
+	return restoreID(db, res, TableTasks, t.ID)
+}
+
+func rowExists(db execer, table string, id int64) (bool, error) {
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = ?)", id).Scan(&exists)
+	return exists, err
+}
+
+// checkHref vergisst das Objekt auf dem CalDAV-Server, wenn der Abgleich es
+// nach dem Löschen hier schon entfernt hat
+func checkHref(db execer, sync *SyncState) error {
+	if sync.Href == "" {
+		return nil
+	}
+	var known bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM caldav_objects WHERE href = ?)", sync.Href).Scan(&known); err != nil {
+		return err
+	}
+	if !known {
+		sync.Href = ""
+	}
+	return nil
+}
+
+// restoreID gibt einem neu eingefügten Eintrag seine alte ID zurück. Dank
+// AUTOINCREMENT wurde sie nicht wieder vergeben.
+func restoreID(db execer, res sql.Result, table string, id int64) error {
+	inserted, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec("UPDATE "+table+" SET id = ? WHERE id = ?", id, inserted); err != nil {
+		return fmt.Errorf("Fehler beim Wiederherstellen der ID %d: %v", id, err)
+	}
+	return nil
+}
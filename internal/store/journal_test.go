@@ -0,0 +1,164 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func titles(t *testing.T, s *Store) []string {
+	t.Helper()
+	appointments, err := s.QueryAppointments(Filter{SortBy: SortTitle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, a := range appointments {
+		out = append(out, a.Title)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUndoRedo(t *testing.T) {
+	s := openTest(t)
+	a := Appointment{Title: "Arzt", Date: "2025-03-14"}
+	if err := s.AddAppointment(&a); err != nil {
+		t.Fatal(err)
+	}
+	b := Appointment{Title: "Bank", Date: "2025-03-15"}
+	if err := s.AddAppointment(&b); err != nil {
+		t.Fatal(err)
+	}
+	a.Title = "Augenarzt"
+	if err := s.UpdateAppointment(a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteAllAppointments(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := [][]string{{"Augenarzt", "Bank"}, {"Arzt", "Bank"}, {"Arzt"}, nil}
+	for i, want := range steps {
+		if _, err := s.Undo(); err != nil {
+			t.Fatalf("Undo %d: %v", i, err)
+		}
+		if got := titles(t, s); !equal(got, want) {
+			t.Errorf("nach Undo %d: %v, erwartet %v", i, got, want)
+		}
+	}
+	if _, err := s.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo ohne Änderungen: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Redo(); err != nil {
+			t.Fatalf("Redo %d: %v", i, err)
+		}
+	}
+	if got := titles(t, s); !equal(got, []string{"Arzt", "Bank"}) {
+		t.Errorf("nach Redo: %v", got)
+	}
+	restored, err := s.GetAppointment(a.ID)
+	if err != nil || restored.ID != a.ID {
+		t.Errorf("Termin nicht mit alter ID wiederhergestellt: %v", err)
+	}
+
+	// Eine neue Änderung verwirft, was sich noch wiederherstellen ließe
+	c := Appointment{Title: "Chor", Date: "2025-03-16"}
+	if err := s.AddAppointment(&c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo nach neuer Änderung: %v", err)
+	}
+}
+
+func TestUndoOp(t *testing.T) {
+	s := openTest(t)
+	a := Appointment{Title: "Arzt", Date: "2025-03-14"}
+	b := Appointment{Title: "Bank", Date: "2025-03-15"}
+	for _, x := range []*Appointment{&a, &b} {
+		if err := s.AddAppointment(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, err := s.DeleteAppointment(a.ID)
+	if err != nil || deleted.ID == 0 {
+		t.Fatalf("DeleteAppointment: %v, %+v", err, deleted)
+	}
+
+	// Eine spätere Änderung an einem anderen Termin bleibt erhalten
+	b.Title = "Bäcker"
+	if err := s.UpdateAppointment(b); err != nil {
+		t.Fatal(err)
+	}
+	op, err := s.UndoOp(deleted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Kind != OpDelete || op.Title != "Arzt" {
+		t.Errorf("UndoOp lieferte %+v", op)
+	}
+	if got := op.String(); got != `Termin "Arzt" gelöscht` {
+		t.Errorf("String() = %q", got)
+	}
+	if got := titles(t, s); !equal(got, []string{"Arzt", "Bäcker"}) {
+		t.Errorf("nach UndoOp: %v", got)
+	}
+	if _, err := s.UndoOp(deleted.ID); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("zweites UndoOp: %v", err)
+	}
+
+	// Eine spätere Änderung am selben Termin verhindert UndoOp
+	edit, err := s.Journal(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Title = "Bibliothek"
+	if err := s.UpdateAppointment(b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UndoOp(edit[0].ID); !errors.Is(err, ErrUndoConflict) {
+		t.Errorf("UndoOp trotz späterer Änderung: %v", err)
+	}
+	if got := titles(t, s); !equal(got, []string{"Arzt", "Bibliothek"}) {
+		t.Errorf("nach abgelehntem UndoOp: %v", got)
+	}
+}
+
+func TestTaskComplete(t *testing.T) {
+	s := openTest(t)
+	task := Task{Title: "Einkaufen"}
+	if err := s.AddTask(&task); err != nil {
+		t.Fatal(err)
+	}
+	task.Completed = true
+	if err := s.UpdateTask(task); err != nil {
+		t.Fatal(err)
+	}
+	op, err := s.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Kind != OpComplete {
+		t.Errorf("Art %q, erwartet %q", op.Kind, OpComplete)
+	}
+	got, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Completed {
+		t.Error("Aufgabe nach Undo noch erledigt")
+	}
+}
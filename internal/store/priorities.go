@@ -0,0 +1,80 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+)
+
+const priorityLevelsSQL = `
+CREATE TABLE IF NOT EXISTS priority_levels (
+	value INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	urgency TEXT NOT NULL DEFAULT 'normal',
+	sticky BOOLEAN NOT NULL DEFAULT 0,
+	nag_minutes INTEGER NOT NULL DEFAULT 0,
+	early_alarms TEXT NOT NULL DEFAULT '',  -- Minuten vor dem Termin, kommagetrennt
+	sort_weight INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// seedPriorityLevels füllt fehlende Standardstufen auf
+func (s *Store) seedPriorityLevels() error {
+	for _, l := range priority.Defaults() {
+		_, err := s.db.Exec(`
+			INSERT OR IGNORE INTO priority_levels (value, name, urgency, sticky, nag_minutes, escalation, early_alarms, sort_weight, sound)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			l.Value, l.Name, l.Urgency, l.Sticky, int(l.NagInterval.Minutes()),
+			strings.Join(l.Escalation, ","), priority.FormatAlarms(l.EarlyAlarms), l.SortWeight, l.Sound)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PriorityLevels lädt alle Prioritätsstufen
+func (s *Store) PriorityLevels() (priority.Set, error) {
+	rows, err := s.db.Query(`
+		SELECT value, name, urgency, sticky, nag_minutes, escalation, early_alarms, sort_weight, sound
+		FROM priority_levels ORDER BY value`)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Prioritäten: %v", err)
+	}
+	defer rows.Close()
+
+	var levels priority.Set
+	for rows.Next() {
+		var l priority.Level
+		var nagMinutes int
+		var escalation, alarms string
+		if err := rows.Scan(&l.Value, &l.Name, &l.Urgency, &l.Sticky, &nagMinutes, &escalation, &alarms, &l.SortWeight, &l.Sound); err != nil {
+			return nil, fmt.Errorf("Fehler beim Scannen der Prioritäten: %v", err)
+		}
+		l.NagInterval = time.Duration(nagMinutes) * time.Minute
+		if l.Escalation, err = priority.ParseEscalation(escalation); err != nil {
+			return nil, err
+		}
+		if l.EarlyAlarms, err = priority.ParseAlarms(alarms); err != nil {
+			return nil, err
+		}
+		levels = append(levels, l)
+	}
+	return levels, rows.Err()
+}
+
+// SavePriorityLevel legt eine Stufe an oder überschreibt sie
+func (s *Store) SavePriorityLevel(l priority.Level) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO priority_levels (value, name, urgency, sticky, nag_minutes, escalation, early_alarms, sort_weight, sound)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.Value, l.Name, l.Urgency, l.Sticky, int(l.NagInterval.Minutes()),
+		strings.Join(l.Escalation, ","), priority.FormatAlarms(l.EarlyAlarms), l.SortWeight, l.Sound)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Priorität: %v", err)
+	}
+	s.notify(Change{Table: TablePriorityLevels})
+	return nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"strings"
+)
+
+// Sortierspalten für Termine und Aufgaben
+const (
+	SortTitle     = "title"
+	SortDate      = "date"
+	SortTime      = "time"
+	SortPriority  = "priority"
+	SortCompleted = "completed"
+)
+
+// Filter beschreibt eine Suche über Termine oder Aufgaben. Leere Felder
+// schränken das Ergebnis nicht ein.
+type Filter struct {
+	Text      string // Volltextsuche über Titel und Notizen
+	From      string // Datum ab (YYYY-MM-DD, inklusive)
+	To        string // Datum bis (YYYY-MM-DD, inklusive)
+	Priority  *int
+	Tag       string
+	Completed *bool // Nur für Aufgaben
+	OwnOnly   bool  // Nur für Termine: ohne Termine aus abonnierten Kalendern
+	SortBy    string
+	Desc      bool
+}
+
+// Priorität wird nach dem Gewicht der Stufe sortiert, nicht nach ihrem Wert
+const prioritySortWeight = "COALESCE((SELECT sort_weight FROM priority_levels WHERE value = priority), priority)"
+
+// Sortierausdrücke je Tabelle; NULL-Werte landen immer am Ende, ganztägige
+// Termine also nach denen mit Uhrzeit am selben Tag
+var sortColumns = map[string]map[string][]string{
+	"appointments": {
+		SortTitle:    {"title COLLATE NOCASE"},
+		SortDate:     {"date", "time IS NULL", "time"},
+		SortTime:     {"time IS NULL", "time"},
+		SortPriority: {"priority IS NULL", prioritySortWeight},
+	},
+	"tasks": {
+		SortTitle:     {"title COLLATE NOCASE"},
+		SortDate:      {"due_date IS NULL", "due_date"},
+		SortPriority:  {"priority IS NULL", prioritySortWeight},
+		SortCompleted: {"COALESCE(completed, 0)"},
+	},
+}
+
+var defaultSort = map[string]string{
+	"appointments": SortDate,
+	"tasks":        SortCompleted,
+}
+
+// DefaultSort liefert die Spalte, nach der die Tabelle ohne Filter.SortBy
+// sortiert wird, z.B. für den Pfeil in der Kopfzeile
+func DefaultSort(table string) string {
+	return defaultSort[table]
+}
+
+// buildQuery erzeugt WHERE- und ORDER BY-Teil für die angegebene Tabelle
+func (s *Store) buildQuery(table, dateColumn string, f Filter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	if tokens := strings.Fields(f.Text); len(tokens) > 0 {
+		if s.fts {
+			conds = append(conds, "id IN (SELECT rowid FROM "+table+"_fts WHERE "+table+"_fts MATCH ?)")
+			args = append(args, ftsQuery(tokens))
+		} else {
+			for _, t := range tokens {
+				conds = append(conds, `(title LIKE ? ESCAPE '\' OR notes LIKE ? ESCAPE '\')`)
+				pattern := "%" + escapeLike(t) + "%"
+				args = append(args, pattern, pattern)
+			}
+		}
+	}
+	if f.From != "" {
+		conds = append(conds, dateColumn+" >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != "" {
+		conds = append(conds, dateColumn+" <= ?")
+		args = append(args, f.To)
+	}
+	if f.Priority != nil {
+		conds = append(conds, "priority = ?")
+		args = append(args, *f.Priority)
+	}
+	if tag := normalizeTag(f.Tag); tag != "" {
+		conds = append(conds, `(',' || tags || ',') LIKE ? ESCAPE '\'`)
+		args = append(args, "%,"+escapeLike(tag)+",%")
+	}
+	if f.OwnOnly && table == "appointments" {
+		conds = append(conds, "subscription_id = 0")
+	}
+	if f.Completed != nil && table == "tasks" {
+		// Ältere Datenbanken enthalten NULL für nicht erledigt
+		conds = append(conds, "COALESCE(completed, 0) = ?")
+		args = append(args, *f.Completed)
+	}
+
+	var sb strings.Builder
+	if len(conds) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(conds, " AND "))
+	}
+
+	columns, ok := sortColumns[table][f.SortBy]
+	if !ok {
+		columns = sortColumns[table][defaultSort[table]]
+	}
+	order := make([]string, 0, len(columns)+1)
+	for _, c := range columns {
+		// "x IS NULL" bleibt aufsteigend, damit NULL-Werte unten stehen
+		if f.Desc && !strings.HasSuffix(c, "IS NULL") {
+			c += " DESC"
+		}
+		order = append(order, c)
+	}
+	order = append(order, "id")
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(strings.Join(order, ", "))
+
+	return sb.String(), args
+}
+
+// ftsQuery wandelt Suchbegriffe in eine FTS5-Abfrage mit Präfixsuche um
+func ftsQuery(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"*`
+	}
+	return strings.Join(parts, " ")
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// SplitTags zerlegt eine kommagetrennte Eingabe in einzelne Tags
+func SplitTags(s string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// JoinTags ist die Umkehrung von SplitTags und das Format der Datenbankspalte
+func JoinTags(tags []string) string {
+	return strings.Join(SplitTags(strings.Join(tags, ",")), ",")
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTest(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestCompletedFilterNull(t *testing.T) {
+	s := openTest(t)
+	for _, task := range []Task{{Title: "Einkaufen"}, {Title: "Putzen", Completed: true}} {
+		if err := s.AddTask(&task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Wie in älteren Datenbanken: nicht erledigt als NULL
+	if _, err := s.db.Exec("UPDATE tasks SET completed = NULL WHERE title = 'Einkaufen'"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, done := range []bool{false, true} {
+		tasks, err := s.QueryTasks(Filter{Completed: &done})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[bool]string{false: "Einkaufen", true: "Putzen"}[done]
+		if len(tasks) != 1 || tasks[0].Title != want {
+			t.Errorf("Erledigt=%v: %+v, erwartet %s", done, tasks, want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	for _, fts := range []bool{false, true} {
+		name := map[bool]string{false: "LIKE", true: "FTS5"}[fts]
+		t.Run(name, func(t *testing.T) {
+			s := openTest(t)
+			if fts && !s.fts {
+				t.Skip("SQLite ohne FTS5 (Build-Tag sqlite_fts5)")
+			}
+			s.fts = fts
+			for _, a := range []Appointment{
+				{Title: "Zahnarzt Kontrolle", Date: "2030-03-14", Notes: "Bonusheft mitnehmen"},
+				{Title: "Zahnreinigung", Date: "2030-04-01"},
+				{Title: "Rabatt 50%", Date: "2030-05-01", Notes: "nur_heute"},
+			} {
+				if err := s.AddAppointment(&a); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tests := []struct {
+				text string
+				want string
+			}{
+				{"zahn", "Zahnarzt Kontrolle,Zahnreinigung"},
+				{"ZAHN", "Zahnarzt Kontrolle,Zahnreinigung"},
+				{"zahn kontrolle", "Zahnarzt Kontrolle"}, // alle Begriffe
+				{"bonus", "Zahnarzt Kontrolle"},          // auch in den Notizen
+				{"  ", "Zahnarzt Kontrolle,Zahnreinigung,Rabatt 50%"},
+				{"friseur", ""},
+			}
+			if !fts {
+				// Nur die einfache Suche findet Teilwörter und muss % und _ maskieren
+				tests = append(tests, []struct {
+					text string
+					want string
+				}{
+					{"arzt", "Zahnarzt Kontrolle"},
+					{"50%", "Rabatt 50%"},
+					{"n_r", ""},
+					{"nur_", "Rabatt 50%"},
+				}...)
+			}
+			for _, tt := range tests {
+				got, err := s.QueryAppointments(Filter{Text: tt.text, SortBy: SortDate})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g := appointmentTitles(got); g != tt.want {
+					t.Errorf("Suche %q: %s, erwartet %s", tt.text, g, tt.want)
+				}
+			}
+
+			// Geänderte Einträge werden neu indiziert
+			task := Task{Title: "Einkaufen"}
+			if err := s.AddTask(&task); err != nil {
+				t.Fatal(err)
+			}
+			task.Title = "Wocheneinkauf"
+			if err := s.UpdateTask(task); err != nil {
+				t.Fatal(err)
+			}
+			for text, want := range map[string]int{"wochen": 1, "einkaufen": 0} {
+				if tasks, err := s.QueryTasks(Filter{Text: text}); err != nil || len(tasks) != want {
+					t.Errorf("Aufgaben mit %q: %+v, %v", text, tasks, err)
+				}
+			}
+		})
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	s := openTest(t)
+	for _, a := range []Appointment{
+		{Title: "Meeting", Date: "2030-03-14", Tags: []string{"Arbeit", "team_a"}},
+		{Title: "Sport", Date: "2030-03-15", Tags: []string{"privat"}},
+		{Title: "Ohne", Date: "2030-03-16"},
+		{Title: "Ähnlich", Date: "2030-03-17", Tags: []string{"arbeitsweg", "teamxa"}},
+	} {
+		if err := s.AddAppointment(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for tag, want := range map[string]string{
+		"arbeit":     "Meeting",
+		" Arbeit ":   "Meeting",
+		"arb":        "",
+		"team_a":     "Meeting",
+		"privat":     "Sport",
+		"arbeitsweg": "Ähnlich",
+		"":           "Meeting,Sport,Ohne,Ähnlich",
+	} {
+		got, err := s.QueryAppointments(Filter{Tag: tag})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g := appointmentTitles(got); g != want {
+			t.Errorf("Tag %q: %s, erwartet %s", tag, g, want)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	s := openTest(t)
+	low, high := 1, 3
+	for _, a := range []Appointment{
+		{Title: "b", Date: "2030-03-14", Time: "10:00", Priority: &high},
+		{Title: "C", Date: "2030-03-14"}, // ganztägig, ohne Priorität
+		{Title: "a", Date: "2030-03-13", Time: "11:00", Priority: &low},
+		{Title: "d", Date: "2030-03-14", Time: "09:00", Priority: &low},
+	} {
+		if err := s.AddAppointment(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, task := range []Task{
+		{Title: "z", DueDate: "2030-01-02", Completed: true},
+		{Title: "Y", Priority: &high},
+		{Title: "x", DueDate: "2030-01-01", Priority: &low},
+	} {
+		if err := s.AddTask(&task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appointments := []struct {
+		sortBy string
+		desc   bool
+		want   string
+	}{
+		{"", false, "a,d,b,C"},
+		{SortDate, false, "a,d,b,C"},
+		{SortDate, true, "b,d,C,a"}, // ganztägige Termine auch absteigend nach denen mit Uhrzeit
+		{SortTitle, false, "a,b,C,d"},
+		{SortTitle, true, "d,C,b,a"},
+		{SortTime, false, "d,b,a,C"},
+		{SortTime, true, "a,b,d,C"},
+		{SortPriority, false, "a,d,b,C"},
+		{SortPriority, true, "b,a,d,C"},
+		{"unbekannt", false, "a,d,b,C"},
+	}
+	for _, tt := range appointments {
+		got, err := s.QueryAppointments(Filter{SortBy: tt.sortBy, Desc: tt.desc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g := appointmentTitles(got); g != tt.want {
+			t.Errorf("Termine nach %q, absteigend %v: %s, erwartet %s", tt.sortBy, tt.desc, g, tt.want)
+		}
+	}
+
+	tasks := []struct {
+		sortBy string
+		desc   bool
+		want   string
+	}{
+		{"", false, "Y,x,z"},
+		{SortCompleted, true, "z,Y,x"},
+		{SortDate, false, "x,z,Y"},
+		{SortDate, true, "z,x,Y"},
+		{SortTitle, false, "x,Y,z"},
+		{SortPriority, false, "x,Y,z"},
+		{SortPriority, true, "Y,x,z"},
+	}
+	for _, tt := range tasks {
+		got, err := s.QueryTasks(Filter{SortBy: tt.sortBy, Desc: tt.desc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g := taskTitles(got); g != tt.want {
+			t.Errorf("Aufgaben nach %q, absteigend %v: %s, erwartet %s", tt.sortBy, tt.desc, g, tt.want)
+		}
+	}
+
+	// Die Priorität wird nach dem Gewicht der Stufe sortiert
+	if _, err := s.db.Exec("UPDATE priority_levels SET sort_weight = 50 WHERE value = ?", low); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.QueryAppointments(Filter{SortBy: SortPriority})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := appointmentTitles(got); g != "b,a,d,C" {
+		t.Errorf("Nach geändertem Gewicht: %s", g)
+	}
+}
+
+func appointmentTitles(list []Appointment) string {
+	titles := make([]string, len(list))
+	for i, a := range list {
+		titles[i] = a.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func taskTitles(list []Task) string {
+	titles := make([]string, len(list))
+	for i, t := range list {
+		titles[i] = t.Title
+	}
+	return strings.Join(titles, ",")
+}
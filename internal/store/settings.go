@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Einstellungen, die GUI, Daemon und reminderctl gemeinsam nutzen
+const settingsSQL = `
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Setting liefert den Wert einer Einstellung oder def, wenn sie nicht gesetzt ist
+func (s *Store) Setting(key, def string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return def, nil
+	}
+	if err != nil {
+		return def, fmt.Errorf("Fehler beim Lesen der Einstellung %s: %v", key, err)
+	}
+	return value, nil
+}
+
+// Settings liefert alle Einstellungen, deren Schlüssel mit prefix beginnt,
+// ohne das Präfix
+func (s *Store) Settings(prefix string) (map[string]string, error) {
+	rows, err := s.db.Query("SELECT key, value FROM settings WHERE substr(key, 1, ?) = ?", len(prefix), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Lesen der Einstellungen: %v", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("Fehler beim Lesen der Einstellungen: %v", err)
+		}
+		settings[strings.TrimPrefix(key, prefix)] = value
+	}
+	return settings, rows.Err()
+}
+
+// SetSetting speichert eine Einstellung; ein leerer Wert entfernt sie
+func (s *Store) SetSetting(key, value string) error {
+	var err error
+	if value == "" {
+		_, err = s.db.Exec("DELETE FROM settings WHERE key = ?", key)
+	} else {
+		_, err = s.db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
+	}
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Einstellung %s: %v", key, err)
+	}
+	s.notify(Change{Table: TableSettings})
+	return nil
+}
+
+// SettingWriter speichert Einstellungen: der Store selbst oder der Client
+// für reminderd
+type SettingWriter interface {
+	SetSetting(key, value string) error
+}
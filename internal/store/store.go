@@ -0,0 +1,220 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store kapselt den Zugriff auf die SQLite-Datenbank mit Terminen und Aufgaben
+type Store struct {
+	db  *sql.DB
+	fts bool // true, wenn SQLite mit FTS5 gebaut wurde (Build-Tag sqlite_fts5)
+
+	mu          sync.Mutex
+	subscribers map[chan Change]struct{}
+	written     chan struct{} // eigene Schreibzugriffe, für Watch
+	done        chan struct{}
+}
+
+const schemaSQL = `
+CREATE TABLE IF NOT EXISTS appointments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT,
+	date TEXT,  -- Separates Datumsfeld
+	time TEXT,  -- Separates Zeitfeld
+	priority INTEGER
+);
+CREATE TABLE IF NOT EXISTS tasks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT,
+	completed BOOLEAN
+);
+`
+
+// Spalten, die nach der ersten Version hinzugekommen sind
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"appointments", "notes", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "tags", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "notes", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "tags", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "priority", "INTEGER"},
+	{"tasks", "due_date", "TEXT"},
+	{"priority_levels", "escalation", "TEXT NOT NULL DEFAULT ''"},
+	{"priority_levels", "sound", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "end_time", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "alarms", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "uid", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "uid", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "subscription_id", "INTEGER NOT NULL DEFAULT 0"},
+	{"appointments", "href", "TEXT NOT NULL DEFAULT ''"},
+	{"appointments", "dirty", "BOOLEAN NOT NULL DEFAULT 0"},
+	{"appointments", "modified", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "href", "TEXT NOT NULL DEFAULT ''"},
+	{"tasks", "dirty", "BOOLEAN NOT NULL DEFAULT 0"},
+	{"tasks", "modified", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Indizes auf ergänzten Spalten, werden nach addedColumns angelegt
+var addedIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS appointments_uid ON appointments (uid) WHERE uid != ''",
+	"CREATE INDEX IF NOT EXISTS tasks_uid ON tasks (uid) WHERE uid != ''",
+	"CREATE INDEX IF NOT EXISTS appointments_subscription ON appointments (subscription_id) WHERE subscription_id != 0",
+	"CREATE INDEX IF NOT EXISTS appointments_href ON appointments (href) WHERE href != ''",
+	"CREATE INDEX IF NOT EXISTS tasks_href ON tasks (href) WHERE href != ''",
+}
+
+// Open öffnet die Datenbank und bringt das Schema auf den aktuellen Stand
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		db:          db,
+		subscribers: make(map[chan Change]struct{}),
+		written:     make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// DB liefert die zugrunde liegende Datenbankverbindung
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// FullText gibt an, ob die Suche über den FTS5-Index läuft
+func (s *Store) FullText() bool {
+	return s.fts
+}
+
+func (s *Store) Close() error {
+	close(s.done)
+
+	s.mu.Lock()
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+	s.mu.Unlock()
+
+	return s.db.Close()
+}
+
+func (s *Store) migrate() error {
+	for _, schema := range []string{schemaSQL, priorityLevelsSQL, acksSQL, settingsSQL, subscriptionsSQL, caldavSQL, journalSQL} {
+		if _, err := s.db.Exec(schema); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range addedColumns {
+		exists, err := s.columnExists(c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("Fehler beim Hinzufügen der Spalte %s.%s: %v", c.table, c.column, err)
+		}
+	}
+	for _, index := range addedIndexes {
+		if _, err := s.db.Exec(index); err != nil {
+			return fmt.Errorf("Fehler beim Anlegen eines Index: %v", err)
+		}
+	}
+
+	if err := s.seedPriorityLevels(); err != nil {
+		return fmt.Errorf("Fehler beim Anlegen der Prioritäten: %v", err)
+	}
+
+	// Ohne FTS5 wird auf eine LIKE-Suche zurückgegriffen
+	if err := s.initFTS(); err != nil {
+		log.Printf("Volltextsuche (FTS5) nicht verfügbar, verwende einfache Suche: %v", err)
+		s.fts = false
+	} else {
+		s.fts = true
+	}
+	return nil
+}
+
+func (s *Store) columnExists(table, column string) (bool, error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// initFTS legt die Suchindizes an und baut sie neu auf. Die Indizes werden
+// bewusst ohne Trigger gepflegt, damit Programme ohne FTS5 weiterhin
+// schreiben können; Änderungen von dort werden beim nächsten Öffnen übernommen.
+func (s *Store) initFTS() error {
+	_, err := s.db.Exec(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS appointments_fts USING fts5(title, notes);
+	CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, notes);
+	` + rebuildFTSSQL)
+	return err
+}
+
+const rebuildFTSSQL = `
+	DELETE FROM appointments_fts;
+	INSERT INTO appointments_fts(rowid, title, notes) SELECT id, COALESCE(title, ''), notes FROM appointments;
+	DELETE FROM tasks_fts;
+	INSERT INTO tasks_fts(rowid, title, notes) SELECT id, COALESCE(title, ''), notes FROM tasks;
+`
+
+// reindexAll baut den Suchindex nach Änderungen an vielen Zeilen neu auf
+func (s *Store) reindexAll() {
+	if !s.fts {
+		return
+	}
+	if _, err := s.db.Exec(rebuildFTSSQL); err != nil {
+		log.Printf("Fehler beim Aktualisieren des Suchindex: %v", err)
+	}
+}
+
+// reindex aktualisiert den Suchindex für eine einzelne Zeile
+func (s *Store) reindex(table string, id int64) {
+	if !s.fts {
+		return
+	}
+	fts := table + "_fts"
+	if _, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE rowid = ?", fts), id); err != nil {
+		log.Printf("Fehler beim Aktualisieren des Suchindex: %v", err)
+		return
+	}
+	_, err := s.db.Exec(fmt.Sprintf(
+		"INSERT INTO %s(rowid, title, notes) SELECT id, COALESCE(title, ''), notes FROM %s WHERE id = ?",
+		fts, table), id)
+	if err != nil {
+		log.Printf("Fehler beim Aktualisieren des Suchindex: %v", err)
+	}
+}
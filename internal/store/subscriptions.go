@@ -0,0 +1,255 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/priority"
+)
+
+// Abonnierte Kalender (ICS-Dateien oder -URLs). Ihre Termine werden beim
+// Aktualisieren anhand der UID abgeglichen und sind nur lesbar.
+const subscriptionsSQL = `
+CREATE TABLE IF NOT EXISTS subscriptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	source TEXT NOT NULL,  -- Dateipfad oder URL
+	refresh_minutes INTEGER NOT NULL DEFAULT 60,
+	color TEXT NOT NULL DEFAULT '',  -- #rrggbb
+	remind BOOLEAN NOT NULL DEFAULT 0,
+	last_refresh TEXT NOT NULL DEFAULT '',
+	last_error TEXT NOT NULL DEFAULT ''
+);
+`
+
+// Subscription ist ein abonnierter Kalender
+type Subscription struct {
+	ID          int64
+	Name        string
+	Source      string
+	Refresh     time.Duration // Abstand zwischen zwei Abrufen
+	Color       string        // #rrggbb, leer = Standardfarbe
+	Remind      bool          // an Termine des Kalenders erinnern
+	LastRefresh time.Time     // letzter Abruf, auch wenn er fehlschlug
+	LastError   string        // Fehler beim letzten Abruf
+}
+
+// Due gibt an, ob der Kalender erneut abgerufen werden soll
+func (sub Subscription) Due(now time.Time) bool {
+	return sub.LastRefresh.IsZero() || !now.Before(sub.LastRefresh.Add(sub.Refresh))
+}
+
+// Abstand für Abos ohne gültigen Abstand in der Datenbank
+const defaultRefresh = time.Hour
+
+// validate lehnt Abos ohne Quelle oder mit einem Abstand unter einer Minute ab
+func (sub Subscription) validate() error {
+	if sub.Source == "" {
+		return fmt.Errorf("Quelle des Kalender-Abos fehlt")
+	}
+	if sub.Refresh < time.Minute {
+		return fmt.Errorf("Ungültiger Abstand für das Kalender-Abo: %v (mindestens eine Minute)", sub.Refresh)
+	}
+	return nil
+}
+
+const subscriptionColumns = "id, name, source, refresh_minutes, color, remind, last_refresh, last_error"
+
+func scanSubscription(row interface{ Scan(...interface{}) error }) (Subscription, error) {
+	var sub Subscription
+	var minutes int
+	var lastRefresh string
+	if err := row.Scan(&sub.ID, &sub.Name, &sub.Source, &minutes, &sub.Color, &sub.Remind, &lastRefresh, &sub.LastError); err != nil {
+		return sub, err
+	}
+	sub.Refresh = time.Duration(minutes) * time.Minute
+	if minutes < 1 {
+		// Aus älteren Versionen; sonst würde das Abo jede Minute abgerufen
+		sub.Refresh = defaultRefresh
+	}
+	if lastRefresh != "" {
+		t, err := time.Parse(time.RFC3339, lastRefresh)
+		if err != nil {
+			return sub, err
+		}
+		sub.LastRefresh = t
+	}
+	return sub, nil
+}
+
+// Subscriptions liefert alle abonnierten Kalender
+func (s *Store) Subscriptions() ([]Subscription, error) {
+	rows, err := s.db.Query("SELECT " + subscriptionColumns + " FROM subscriptions ORDER BY name COLLATE NOCASE, id")
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Kalender-Abos: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Fehler beim Scannen der Kalender-Abos: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// GetSubscription lädt einen einzelnen abonnierten Kalender
+func (s *Store) GetSubscription(id int64) (Subscription, error) {
+	row := s.db.QueryRow("SELECT "+subscriptionColumns+" FROM subscriptions WHERE id = ?", id)
+	return scanSubscription(row)
+}
+
+// AddSubscription speichert einen neuen abonnierten Kalender und setzt dessen ID
+func (s *Store) AddSubscription(sub *Subscription) error {
+	if err := sub.validate(); err != nil {
+		return err
+	}
+	res, err := s.db.Exec(
+		"INSERT INTO subscriptions (name, source, refresh_minutes, color, remind) VALUES (?, ?, ?, ?, ?)",
+		sub.Name, sub.Source, int(sub.Refresh.Minutes()), sub.Color, sub.Remind)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern des Kalender-Abos: %v", err)
+	}
+	sub.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.notify(Change{Table: TableSubscriptions, ID: sub.ID})
+	return nil
+}
+
+// UpdateSubscription ändert Name, Quelle, Abstand, Farbe und Erinnerung.
+// Bei geänderter Quelle wird der Kalender beim nächsten Mal sofort abgerufen.
+func (s *Store) UpdateSubscription(sub Subscription) error {
+	if err := sub.validate(); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`
+		UPDATE subscriptions
+		SET name = ?, refresh_minutes = ?, color = ?, remind = ?,
+			last_refresh = CASE WHEN source = ? THEN last_refresh ELSE '' END,
+			source = ?
+		WHERE id = ?`,
+		sub.Name, int(sub.Refresh.Minutes()), sub.Color, sub.Remind, sub.Source, sub.Source, sub.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Aktualisieren des Kalender-Abos: %v", err)
+	}
+	s.notify(Change{Table: TableSubscriptions, ID: sub.ID})
+	return nil
+}
+
+// DeleteSubscription entfernt einen abonnierten Kalender samt seiner Termine
+func (s *Store) DeleteSubscription(id int64) error {
+	if err := s.ReplaceSubscriptionAppointments(id, nil); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM subscriptions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("Fehler beim Löschen des Kalender-Abos: %v", err)
+	}
+	s.notify(Change{Table: TableSubscriptions, ID: id})
+	return nil
+}
+
+// SetSubscriptionStatus vermerkt Zeitpunkt und Ergebnis eines Abrufs
+func (s *Store) SetSubscriptionStatus(id int64, refreshed time.Time, refreshErr error) error {
+	message := ""
+	if refreshErr != nil {
+		message = refreshErr.Error()
+	}
+	_, err := s.db.Exec("UPDATE subscriptions SET last_refresh = ?, last_error = ? WHERE id = ?",
+		refreshed.Format(time.RFC3339), message, id)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Aktualisieren des Kalender-Abos: %v", err)
+	}
+	s.notify(Change{Table: TableSubscriptions, ID: id})
+	return nil
+}
+
+// ReplaceSubscriptionAppointments ersetzt alle Termine eines abonnierten
+// Kalenders in einer Transaktion. Termine mit gleicher UID werden an Ort und
+// Stelle aktualisiert, damit ihre ID und die Bestätigungen erhalten bleiben.
+func (s *Store) ReplaceSubscriptionAppointments(id int64, appointments []Appointment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	existing := make(map[string][]int64)
+	var stale []int64
+	rows, err := tx.Query("SELECT id, uid FROM appointments WHERE subscription_id = ? ORDER BY id", id)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Abrufen der abonnierten Termine: %v", err)
+	}
+	for rows.Next() {
+		var rowID int64
+		var uid string
+		if err := rows.Scan(&rowID, &uid); err != nil {
+			rows.Close()
+			return err
+		}
+		if uid == "" {
+			stale = append(stale, rowID)
+		} else {
+			existing[uid] = append(existing[uid], rowID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var changed []int64
+	for i := range appointments {
+		a := &appointments[i]
+		a.SubscriptionID = id
+		if ids := existing[a.UID]; a.UID != "" && len(ids) > 0 {
+			a.ID, existing[a.UID] = ids[0], ids[1:]
+			_, err := tx.Exec(`
+				UPDATE appointments
+				SET title = ?, date = ?, time = ?, priority = ?, notes = ?, tags = ?, end_time = ?, alarms = ?
+				WHERE id = ?`,
+				a.Title, a.Date, nullString(a.Time), a.Priority, a.Notes, JoinTags(a.Tags), a.EndTime,
+				priority.FormatAlarms(a.Alarms), a.ID)
+			if err != nil {
+				return fmt.Errorf("Fehler beim Aktualisieren des Termins %q: %v", a.Title, err)
+			}
+		} else {
+			res, err := tx.Exec(insertAppointmentSQL, insertAppointmentArgs(a)...)
+			if err != nil {
+				return fmt.Errorf("Fehler beim Speichern des Termins %q: %v", a.Title, err)
+			}
+			if a.ID, err = res.LastInsertId(); err != nil {
+				return err
+			}
+		}
+		changed = append(changed, a.ID)
+	}
+
+	// Was nicht mehr im Kalender steht, wird samt Bestätigungen gelöscht
+	for _, ids := range existing {
+		stale = append(stale, ids...)
+	}
+	for _, rowID := range stale {
+		for _, stmt := range []string{
+			"DELETE FROM reminder_acks WHERE appointment_id = ?",
+			"DELETE FROM appointments WHERE id = ?",
+		} {
+			if _, err := tx.Exec(stmt, rowID); err != nil {
+				return fmt.Errorf("Fehler beim Ersetzen der abonnierten Termine: %v", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, rowID := range append(changed, stale...) {
+		s.reindex(TableAppointments, rowID)
+	}
+	s.notify(Change{Table: TableAppointments})
+	return nil
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Struktur für Aufgaben
+type Task struct {
+	ID        int64
+	Title     string
+	Completed bool
+	Priority  *int
+	DueDate   string // YYYY-MM-DD, leer wenn nicht gesetzt
+	Notes     string
+	Tags      []string
+	UID       string // iCalendar-UID, leer bei hier angelegten Aufgaben
+	Sync      SyncState
+}
+
+const taskColumns = "id, title, completed, priority, due_date, notes, tags, uid, href, dirty, modified"
+
+func scanTask(row interface{ Scan(...interface{}) error }) (Task, error) {
+	var t Task
+	var title, dueDate sql.NullString
+	var completed sql.NullBool
+	var priority sql.NullInt64
+	var tags, modified string
+	if err := row.Scan(&t.ID, &title, &completed, &priority, &dueDate, &t.Notes, &tags, &t.UID,
+		&t.Sync.Href, &t.Sync.Dirty, &modified); err != nil {
+		return t, err
+	}
+	t.Sync.Modified = parseModified(modified)
+	t.Title = title.String
+	t.Completed = completed.Bool
+	t.DueDate = dueDate.String
+	if priority.Valid {
+		p := int(priority.Int64)
+		t.Priority = &p
+	}
+	t.Tags = SplitTags(tags)
+	return t, nil
+}
+
+// QueryTasks liefert alle Aufgaben, die zum Filter passen
+func (s *Store) QueryTasks(f Filter) ([]Task, error) {
+	clause, args := s.buildQuery(TableTasks, "due_date", f)
+	rows, err := s.db.Query("SELECT "+taskColumns+" FROM tasks"+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Fehler beim Abrufen der Aufgaben: %v", err)
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Fehler beim Scannen der Aufgaben: %v", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// GetTask lädt eine einzelne Aufgabe
+func (s *Store) GetTask(id int64) (Task, error) {
+	row := s.db.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ?", id)
+	return scanTask(row)
+}
+
+// TaskByUID sucht eine Aufgabe anhand ihrer iCalendar-UID und liefert
+// sql.ErrNoRows, wenn es keine gibt
+func (s *Store) TaskByUID(uid string) (Task, error) {
+	row := s.db.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE uid = ? AND uid != '' LIMIT 1", uid)
+	return scanTask(row)
+}
+
+const insertTaskSQL = `
+	INSERT INTO tasks (title, completed, priority, due_date, notes, tags, uid, href, dirty, modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func insertTaskArgs(t *Task) []interface{} {
+	return []interface{}{t.Title, t.Completed, t.Priority, nullString(t.DueDate), t.Notes, JoinTags(t.Tags), t.UID,
+		t.Sync.Href, t.Sync.Dirty, formatModified(t.Sync.Modified)}
+}
+
+// AddTask speichert eine neue Aufgabe und setzt deren ID
+func (s *Store) AddTask(t *Task) error {
+	t.Sync = SyncState{Modified: time.Now()}
+	res, err := s.db.Exec(insertTaskSQL, insertTaskArgs(t)...)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Speichern der Aufgabe: %v", err)
+	}
+	t.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.reindex(TableTasks, t.ID)
+	s.notify(Change{Table: TableTasks, ID: t.ID})
+	s.record(Operation{Kind: OpCreate, Table: TableTasks, Title: t.Title, Count: 1}, snapshot{}, snapshot{Tasks: []Task{*t}})
+	return nil
+}
+
+// UpdateTask überschreibt eine bestehende Aufgabe
+func (s *Store) UpdateTask(t Task) error {
+	before, journalErr := s.GetTask(t.ID)
+	if err := writeTask(s.db, t); err != nil {
+		return err
+	}
+	s.reindex(TableTasks, t.ID)
+	s.notify(Change{Table: TableTasks, ID: t.ID})
+	if after, err := s.GetTask(t.ID); journalErr == nil && err == nil {
+		kind := OpEdit
+		if t.Completed && !before.Completed {
+			kind = OpComplete
+		}
+		s.record(Operation{Kind: kind, Table: TableTasks, Title: t.Title, Count: 1}, snapshot{Tasks: []Task{before}}, snapshot{Tasks: []Task{after}})
+	}
+	return nil
+}
+
+// writeTask schreibt die Inhalte einer bestehenden Aufgabe und markiert sie
+// für den CalDAV-Abgleich
+func writeTask(db execer, t Task) error {
+	_, err := db.Exec(`
+		UPDATE tasks
+		SET title = ?, completed = ?, priority = ?, due_date = ?, notes = ?, tags = ?, uid = ?,
+			dirty = 1, modified = ?
+		WHERE id = ?`,
+		t.Title, t.Completed, t.Priority, nullString(t.DueDate), t.Notes, JoinTags(t.Tags), t.UID,
+		formatModified(time.Now()), t.ID)
+	if err != nil {
+		return fmt.Errorf("Fehler beim Aktualisieren der Aufgabe: %v", err)
+	}
+	return nil
+}
+
+// DeleteTask löscht eine Aufgabe und liefert den Eintrag im
+// Änderungsjournal, wie DeleteAppointment
+func (s *Store) DeleteTask(id int64) (Operation, error) {
+	op := Operation{Kind: OpDelete, Table: TableTasks, Count: 1}
+	before, journalErr := s.GetTask(id)
+	if err := removeTask(s.db, id); err != nil {
+		return op, err
+	}
+	s.reindex(TableTasks, id)
+	s.notify(Change{Table: TableTasks, ID: id})
+	if journalErr == nil {
+		op.Title = before.Title
+		op = s.record(op, snapshot{Tasks: []Task{before}}, snapshot{})
+	}
+	return op, nil
+}
+
+func removeTask(db execer, id int64) error {
+	if _, err := db.Exec("DELETE FROM tasks WHERE id = ?", id); err != nil {
+		return fmt.Errorf("Fehler beim Löschen der Aufgabe: %v", err)
+	}
+	return nil
+}
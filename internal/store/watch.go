@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Tabellennamen, wie sie in Change.Table verwendet werden
+const (
+	TableAppointments   = "appointments"
+	TableTasks          = "tasks"
+	TablePriorityLevels = "priority_levels"
+	TableReminderAcks   = "reminder_acks"
+	TableSettings       = "settings"
+	TableSubscriptions  = "subscriptions"
+)
+
+// Change beschreibt eine Änderung am Datenbestand. Bei Änderungen durch
+// andere Prozesse ist nicht bekannt, was sich geändert hat; dann sind
+// Table und ID leer.
+type Change struct {
+	Table string
+	ID    int64
+}
+
+// Affects gibt an, ob die Änderung die angegebene Tabelle betreffen kann
+func (c Change) Affects(table string) bool {
+	return c.Table == "" || c.Table == table
+}
+
+// Subscribe liefert einen Kanal, über den alle Änderungen gemeldet werden.
+// Die zurückgegebene Funktion beendet das Abonnement.
+func (s *Store) Subscribe() (<-chan Change, func()) {
+	ch := make(chan Change, 16)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// notify meldet eine Änderung durch diesen Prozess
+func (s *Store) notify(c Change) {
+	s.wrote()
+	s.publish(c)
+}
+
+// wrote teilt Watch mit, dass dieser Prozess geschrieben hat
+func (s *Store) wrote() {
+	select {
+	case s.written <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Store) publish(c Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- c:
+		default:
+			// Abonnent ist beschäftigt: die älteste Meldung weicht einer
+			// ohne Tabelle, damit er danach alles neu lädt und keine
+			// Änderung verpasst. Nur publish sendet, und das unter s.mu,
+			// daher ist danach Platz.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- Change{}:
+			default:
+			}
+		}
+	}
+}
+
+// Notify meldet eine Änderung, die ein anderer Prozess mitgeteilt hat,
+// z.B. reminderd über seine Schnittstelle
+func (s *Store) Notify(c Change) {
+	s.publish(c)
+}
+
+// Watch erkennt Schreibzugriffe anderer Prozesse (reminderd, reminderctl)
+// über PRAGMA data_version und meldet sie als Change ohne Tabelle.
+// Der Wert ändert sich für Commits aller anderen Verbindungen, auch der
+// übrigen dieses Prozesses, daher wird eine eigene Verbindung dafür
+// reserviert. Eigene Änderungen hat notify schon gemeldet; nach ihnen
+// übernimmt Watch nur den neuen Stand.
+func (s *Store) Watch(interval time.Duration) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	dataVersion := func() (int64, error) {
+		var version int64
+		err := conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version)
+		return version, err
+	}
+	last, err := dataVersion()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	go func() {
+		defer conn.Close()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.written:
+				if version, err := dataVersion(); err == nil {
+					last = version
+				}
+			case <-ticker.C:
+				version, err := dataVersion()
+				if err != nil {
+					log.Printf("Fehler beim Prüfen auf Datenbankänderungen: %v", err)
+					continue
+				}
+				if version == last {
+					continue
+				}
+				last = version
+				select {
+				case <-s.written:
+					// gerade eben selbst geändert
+				default:
+					s.publish(Change{})
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
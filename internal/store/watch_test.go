@@ -0,0 +1,89 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotifyOverflow(t *testing.T) {
+	s := openTest(t)
+	changes, cancel := s.Subscribe()
+	defer cancel()
+
+	for id := int64(1); id <= 20; id++ {
+		s.notify(Change{Table: TableTasks, ID: id})
+	}
+
+	// Die übrigen Meldungen fehlen, dafür kommt eine für alle Tabellen
+	reloadAll := false
+	for i := 0; i < cap(changes); i++ {
+		c := <-changes
+		if c.Table == "" {
+			reloadAll = true
+		}
+	}
+	if !reloadAll {
+		t.Error("Bei vollem Puffer fehlt die Meldung ohne Tabelle")
+	}
+	select {
+	case c := <-changes:
+		t.Errorf("Mehr Meldungen als Platz im Puffer: %+v", c)
+	default:
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Watch(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	changes, cancel := s.Subscribe()
+	defer cancel()
+
+	// Eigene Änderungen werden nur einmal gemeldet, mit Tabelle
+	for i := 0; i < 5; i++ {
+		if err := s.AddTask(&Task{Title: "Einkaufen"}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	timeout := time.After(100 * time.Millisecond)
+	for n := 0; ; n++ {
+		select {
+		case c := <-changes:
+			if c.Table != TableTasks {
+				t.Fatalf("Eigene Änderung als %+v gemeldet", c)
+			}
+			continue
+		case <-timeout:
+		}
+		if n != 5 {
+			t.Errorf("%d Meldungen für 5 Änderungen", n)
+		}
+		break
+	}
+
+	// Änderungen eines anderen Prozesses ohne Tabelle
+	other, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := other.AddTask(&Task{Title: "Arzt anrufen"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		if c.Table != "" {
+			t.Errorf("Fremde Änderung als %+v gemeldet", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Fremde Änderung nicht gemeldet")
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/reminder"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// So viele Zeilen springen Bild auf und Bild ab in den Listen
+const tablePage = 10
+
+// Tabelle, die sich vollständig mit der Tastatur bedienen lässt: Pfeiltasten,
+// Bild auf/ab, Pos1 und Ende wählen eine Zeile, Enter bearbeitet sie und
+// Entf löscht sie
+type keyTable struct {
+	widget.Table
+
+	row      int // gewählte Zeile, -1 = keine
+	onEdit   func()
+	onDelete func()
+}
+
+func newKeyTable(length func() (int, int), create func() fyne.CanvasObject, update func(widget.TableCellID, fyne.CanvasObject)) *keyTable {
+	t := &keyTable{row: -1}
+	t.Length = length
+	t.CreateCell = create
+	t.UpdateCell = update
+	t.OnSelected = func(id widget.TableCellID) { t.row = id.Row }
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+// Tapped wählt die Zelle wie bei widget.Table und fokussiert die Tabelle;
+// widget.Table selbst kennt nur sich, nicht diese Erweiterung
+func (t *keyTable) Tapped(e *fyne.PointEvent) {
+	t.Table.Tapped(e)
+	if c := fyne.CurrentApp().Driver().CanvasForObject(t); c != nil {
+		c.Focus(t)
+	}
+}
+
+func (t *keyTable) TypedKey(e *fyne.KeyEvent) {
+	rows, _ := t.Length()
+	switch e.Name {
+	case fyne.KeyUp:
+		t.move(-1)
+	case fyne.KeyDown:
+		t.move(1)
+	case fyne.KeyPageUp:
+		t.move(-tablePage)
+	case fyne.KeyPageDown:
+		t.move(tablePage)
+	case fyne.KeyHome:
+		t.move(-rows)
+	case fyne.KeyEnd:
+		t.move(rows)
+	case fyne.KeyReturn, fyne.KeyEnter:
+		if t.onEdit != nil {
+			t.onEdit()
+		}
+	case fyne.KeyDelete:
+		if t.onDelete != nil {
+			t.onDelete()
+		}
+	default:
+		t.Table.TypedKey(e)
+	}
+}
+
+// Bewegt Fokus und Auswahl um delta Zeilen, begrenzt auf die Tabelle. Der
+// Fokusrahmen von widget.Table folgt über dessen eigene Pfeiltasten.
+func (t *keyTable) move(delta int) {
+	rows, _ := t.Length()
+	if rows == 0 {
+		return
+	}
+	if t.row < 0 {
+		// Ohne Auswahl steht der Fokus noch auf der ersten Zeile
+		t.selectRow(0)
+		return
+	}
+	target := min(max(t.row+delta, 0), rows-1)
+	steps, key := target-t.row, fyne.KeyDown
+	if steps < 0 {
+		steps, key = -steps, fyne.KeyUp
+	}
+	for ; steps > 0; steps-- {
+		t.Table.TypedKey(&fyne.KeyEvent{Name: key})
+	}
+	t.selectRow(target)
+}
+
+func (t *keyTable) selectRow(row int) {
+	t.row = row
+	t.Select(widget.TableCellID{Row: row, Col: 0})
+}
+
+// Tastenkürzel der Menüeinträge nach ShortcutName. Die Menüs zeigen sie nur
+// an, ausgelöst werden sie über die Canvas des Fensters.
+var windowShortcuts = map[string]bool{}
+
+// Meldet die Tastenkürzel aller Einträge der Menüs beim Fenster an, außer
+// den Tasten der Listen
+func addShortcuts(w fyne.Window, menus ...*fyne.Menu) {
+	for _, m := range menus {
+		for _, item := range m.Items {
+			if item.Shortcut == nil || item.Action == nil {
+				continue
+			}
+			if _, ok := item.Shortcut.(*listKey); ok {
+				continue
+			}
+			action := item.Action
+			w.Canvas().AddShortcut(item.Shortcut, func(fyne.Shortcut) { action() })
+			windowShortcuts[item.Shortcut.ShortcutName()] = true
+		}
+	}
+}
+
+// Eingabefeld, das die Tastenkürzel des Fensters durchlässt; ein normales
+// Entry verschluckt sie, solange es den Fokus hat. Rückgängig und
+// Wiederherstellen bleiben beim Eingabefeld für dessen Text.
+type shortcutEntry struct {
+	widget.Entry
+}
+
+func newShortcutEntry() *shortcutEntry {
+	e := &shortcutEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *shortcutEntry) TypedShortcut(s fyne.Shortcut) {
+	if _, custom := s.(*desktop.CustomShortcut); custom && windowShortcuts[s.ShortcutName()] {
+		if c, ok := fyne.CurrentApp().Driver().CanvasForObject(e).(fyne.Shortcutable); ok {
+			c.TypedShortcut(s)
+			return
+		}
+	}
+	e.Entry.TypedShortcut(s)
+}
+
+// Tastenkürzel mit Strg, auf macOS mit Cmd
+func ctrl(key fyne.KeyName) fyne.Shortcut {
+	return &desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierShortcutDefault}
+}
+
+// Taste ohne Modifikator, die die Tabelle selbst auswertet; im Menü nur
+// zur Anzeige
+type listKey struct {
+	desktop.CustomShortcut
+}
+
+func plainKey(name fyne.KeyName) fyne.Shortcut {
+	return &listKey{desktop.CustomShortcut{KeyName: name}}
+}
+
+// Was beide Listen über die Tastatur anbieten
+type listView interface {
+	editSelected()
+	deleteSelected()
+	focusSearch()
+}
+
+// Menü „Aktionen“ mit den Tastenkürzeln des Hauptfensters
+func newActionsMenu(w fyne.Window, tabs *container.AppTabs, appointments *appointmentsView, tasks *tasksView) *fyne.Menu {
+	current := func() listView {
+		if tabs.SelectedIndex() == 1 {
+			return tasks
+		}
+		return appointments
+	}
+	item := func(label string, shortcut fyne.Shortcut, action func()) *fyne.MenuItem {
+		i := fyne.NewMenuItem(label, action)
+		i.Shortcut = shortcut
+		return i
+	}
+	return fyne.NewMenu(i18n.T("actions.menu"),
+		item(i18n.T("main.newAppointment"), ctrl(fyne.KeyN), func() { addAppointment(w) }),
+		item(i18n.T("main.newTask"), ctrl(fyne.KeyT), func() { addTask(w) }),
+		item(i18n.T("actions.search"), ctrl(fyne.KeyF), func() { current().focusSearch() }),
+		fyne.NewMenuItemSeparator(),
+		item(i18n.T("undo.undo"), &fyne.ShortcutUndo{}, func() { undo(w) }),
+		item(i18n.T("undo.redo"), &fyne.ShortcutRedo{}, func() { redo(w) }),
+		fyne.NewMenuItemSeparator(),
+		item(i18n.T("actions.edit"), plainKey(fyne.KeyReturn), func() { current().editSelected() }),
+		item(i18n.T("actions.delete"), plainKey(fyne.KeyDelete), func() { current().deleteSelected() }),
+		fyne.NewMenuItemSeparator(),
+		item(i18n.T("actions.showAppointments"), ctrl(fyne.Key1), func() { tabs.SelectIndex(0) }),
+		item(i18n.T("actions.showTasks"), ctrl(fyne.Key2), func() { tabs.SelectIndex(1) }),
+		item(i18n.T("actions.doNotDisturb"), ctrl(fyne.KeyD), func() {
+			on, _, _ := reminder.DoNotDisturb(dataStore, time.Now())
+			setDoNotDisturb(!on)
+		}),
+		item(i18n.T("main.settings"), ctrl(fyne.KeyComma), func() { showSettings(w) }),
+		fyne.NewMenuItemSeparator(),
+		item(i18n.T("actions.palette"), ctrl(fyne.KeyK), func() { showCommandPalette(w) }),
+	)
+}
+
+// Anzeigetext eines Tastenkürzels, z.B. „Strg+N“
+func shortcutText(s fyne.Shortcut) string {
+	ks, ok := s.(fyne.KeyboardShortcut)
+	if !ok {
+		return ""
+	}
+	var parts []string
+	for _, m := range []struct {
+		mod fyne.KeyModifier
+		id  string
+	}{
+		{fyne.KeyModifierControl, "keys.ctrl"},
+		{fyne.KeyModifierSuper, "keys.super"},
+		{fyne.KeyModifierAlt, "keys.alt"},
+		{fyne.KeyModifierShift, "keys.shift"},
+	} {
+		if ks.Mod()&m.mod != 0 {
+			parts = append(parts, i18n.T(m.id))
+		}
+	}
+	name := string(ks.Key())
+	switch ks.Key() {
+	case fyne.KeyReturn, fyne.KeyEnter:
+		name = i18n.T("keys.enter")
+	case fyne.KeyDelete:
+		name = i18n.T("keys.delete")
+	}
+	return strings.Join(append(parts, name), "+")
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"Reminder_Erinnerungs_App/assets"
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Dauerhaft sichtbare Terminliste, die sich bei jeder Änderung im Store neu lädt
+type appointmentsView struct {
+	window fyne.Window
+	table  *keyTable
+	search *shortcutEntry
+
+	// Die Filterleiste schreibt den Filter im UI, reload liest ihn auch
+	// auf dem Goroutine von watchChanges
+	mu            sync.Mutex
+	filter        store.Filter
+	items         []store.Appointment
+	subscriptions map[int64]store.Subscription
+}
+
+func newAppointmentsView(window fyne.Window) *appointmentsView {
+	v := &appointmentsView{window: window, filter: store.Filter{SortBy: store.DefaultSort(store.TableAppointments)}}
+	v.table = newKeyTable(
+		func() (int, int) {
+			return v.len(), 7
+		},
+		func() fyne.CanvasObject {
+			// Erstelle einen Container mit einem Label für Text-Spalten und einem Button für Aktions-Spalten;
+			// die Farbmarkierung kennzeichnet Termine aus abonnierten Kalendern, das
+			// Symbol die Prioritätsstufe
+			return container.NewHBox(
+				newColorMarker(),
+				widget.NewIcon(nil),
+				widget.NewLabel(""),
+				widget.NewButton("", nil), // Platzhalter für Buttons
+			)
+		},
+		v.updateCell,
+	)
+	v.table.onEdit = v.editSelected
+	v.table.onDelete = v.deleteSelected
+	setSortableHeader(&v.table.Table,
+		[]string{i18n.T("common.title"), i18n.T("common.date"), i18n.T("common.time"), i18n.T("common.priority"), i18n.T("common.tags")},
+		[]string{store.SortTitle, store.SortDate, store.SortTime, store.SortPriority},
+		&v.filter, &v.mu, v.reload)
+
+	v.table.SetColumnWidth(0, 200)
+	v.table.SetColumnWidth(1, 100)
+	v.table.SetColumnWidth(2, 80)
+	v.table.SetColumnWidth(3, 110)
+	v.table.SetColumnWidth(4, 120)
+	v.table.SetColumnWidth(5, 80)
+	v.table.SetColumnWidth(6, 80)
+	return v
+}
+
+func (v *appointmentsView) len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.items)
+}
+
+func (v *appointmentsView) item(row int) (store.Appointment, store.Subscription, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if row < 0 || row >= len(v.items) {
+		return store.Appointment{}, store.Subscription{}, false
+	}
+	a := v.items[row]
+	return a, v.subscriptions[a.SubscriptionID], true
+}
+
+func (v *appointmentsView) updateCell(id widget.TableCellID, cell fyne.CanvasObject) {
+	container := cell.(*fyne.Container)
+	marker := container.Objects[0].(*canvas.Rectangle)
+	icon := container.Objects[1].(*widget.Icon)
+	label := container.Objects[2].(*widget.Label)
+	button := container.Objects[3].(*widget.Button)
+
+	// Standardmäßig alles ausblenden
+	marker.Hide()
+	icon.Hide()
+	label.Hide()
+	button.Hide()
+
+	appointment, subscription, ok := v.item(id.Row)
+	if !ok {
+		return
+	}
+
+	// Termine aus Abos sind nur lesbar: Farbe des Kalenders statt Buttons
+	if appointment.ReadOnly() && id.Col >= 5 {
+		if id.Col == 5 {
+			label.SetText(subscription.Name)
+			label.Show()
+		}
+		return
+	}
+
+	switch id.Col {
+	case 0:
+		if appointment.ReadOnly() {
+			marker.FillColor = subscriptionColor(subscription)
+			marker.Refresh()
+			marker.Show()
+		}
+		label.SetText(appointment.Title)
+	case 1:
+		// Datum in der eingestellten Sprache anzeigen
+		label.SetText(dates.Format(appointment.Date))
+	case 2:
+		// Setze einen Standardwert für die Zeit, wenn sie leer ist
+		if appointment.Time == "" {
+			label.SetText(i18n.T("lists.noTime"))
+		} else {
+			label.SetText(dates.FormatTime(appointment.Time))
+		}
+	case 3:
+		showPriorityIcon(icon, appointment.Priority)
+		label.SetText(formatPriority(appointment.Priority))
+	case 4:
+		label.SetText(strings.Join(appointment.Tags, ", "))
+	case 5:
+		// Löschen-Button
+		button.Show()
+		button.SetText(i18n.T("lists.delete"))
+		button.OnTapped = func() {
+			deleteAppointment(appointment.ID, v.window)
+		}
+	case 6:
+		// Ändern-Button
+		button.Show()
+		button.SetText(i18n.T("lists.edit"))
+		button.OnTapped = func() {
+			editAppointment(appointment, v.window)
+		}
+	}
+	if id.Col < 5 {
+		label.Show()
+	}
+}
+
+// Lädt die Termine mit dem aktuellen Filter neu
+func (v *appointmentsView) reload() {
+	v.mu.Lock()
+	filter := v.filter
+	v.mu.Unlock()
+	appointments, err := dataStore.QueryAppointments(filter)
+	if err != nil {
+		log.Printf("Fehler beim Aktualisieren der Termine: %v", err)
+		return
+	}
+	subs, err := dataStore.Subscriptions()
+	if err != nil {
+		log.Printf("Fehler beim Abrufen der Kalender-Abos: %v", err)
+	}
+	subscriptions := make(map[int64]store.Subscription, len(subs))
+	for _, sub := range subs {
+		subscriptions[sub.ID] = sub
+	}
+	v.mu.Lock()
+	v.items = appointments
+	v.subscriptions = subscriptions
+	v.mu.Unlock()
+	v.table.Refresh()
+}
+
+func (v *appointmentsView) content() fyne.CanvasObject {
+	// Erstelle den "Alle Termine löschen" Button
+	deleteAllButton := widget.NewButton(i18n.T("lists.deleteAllAppointments"), func() {
+		reminderService.DeleteAllAppointments()
+	})
+	deleteAllButton.Importance = widget.DangerImportance
+
+	// Such- und Filterleiste mit dem Button rechts daneben; Enter in der
+	// Suche springt in die Tabelle
+	var bar fyne.CanvasObject
+	bar, v.search = newFilterBar(&v.filter, &v.mu, v.reload)
+	v.search.OnSubmitted = func(string) { v.window.Canvas().Focus(v.table) }
+	top := container.NewBorder(nil, nil, nil,
+		container.NewVBox(layout.NewSpacer(), deleteAllButton),
+		bar,
+	)
+
+	// Filter oben, Tabelle nimmt den restlichen Platz ein
+	return container.NewPadded(container.NewBorder(top, nil, nil, nil, v.table))
+}
+
+// Bearbeitet den gewählten Termin; Termine aus Abos sind nur lesbar
+func (v *appointmentsView) editSelected() {
+	if appointment, _, ok := v.item(v.table.row); ok && !appointment.ReadOnly() {
+		editAppointment(appointment, v.window)
+	}
+}
+
+func (v *appointmentsView) deleteSelected() {
+	if appointment, _, ok := v.item(v.table.row); ok && !appointment.ReadOnly() {
+		deleteAppointment(appointment.ID, v.window)
+	}
+}
+
+func (v *appointmentsView) focusSearch() {
+	v.window.Canvas().Focus(v.search)
+}
+
+// Dauerhaft sichtbare Aufgabenliste
+type tasksView struct {
+	window fyne.Window
+	table  *keyTable
+	search *shortcutEntry
+
+	mu     sync.Mutex // wie bei appointmentsView
+	filter store.Filter
+	items  []store.Task
+}
+
+func newTasksView(window fyne.Window) *tasksView {
+	v := &tasksView{window: window, filter: store.Filter{SortBy: store.DefaultSort(store.TableTasks)}}
+	v.table = newKeyTable(
+		func() (int, int) {
+			return v.len(), 7
+		},
+		func() fyne.CanvasObject {
+			return container.NewHBox(
+				widget.NewIcon(nil),
+				widget.NewLabel(""),
+				widget.NewButton("", nil),
+			)
+		},
+		v.updateCell,
+	)
+	v.table.onEdit = v.editSelected
+	v.table.onDelete = v.deleteSelected
+	setSortableHeader(&v.table.Table,
+		[]string{i18n.T("common.title"), i18n.T("common.status"), i18n.T("common.priority"), i18n.T("lists.dueColumn"), i18n.T("common.tags")},
+		[]string{store.SortTitle, store.SortCompleted, store.SortPriority, store.SortDate},
+		&v.filter, &v.mu, v.reload)
+
+	v.table.SetColumnWidth(0, 250)
+	v.table.SetColumnWidth(1, 130)
+	v.table.SetColumnWidth(2, 110)
+	v.table.SetColumnWidth(3, 100)
+	v.table.SetColumnWidth(4, 120)
+	v.table.SetColumnWidth(5, 80)
+	v.table.SetColumnWidth(6, 80)
+	return v
+}
+
+func (v *tasksView) len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.items)
+}
+
+func (v *tasksView) item(row int) (store.Task, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if row < 0 || row >= len(v.items) {
+		return store.Task{}, false
+	}
+	return v.items[row], true
+}
+
+func (v *tasksView) updateCell(id widget.TableCellID, cell fyne.CanvasObject) {
+	container := cell.(*fyne.Container)
+	icon := container.Objects[0].(*widget.Icon)
+	label := container.Objects[1].(*widget.Label)
+	button := container.Objects[2].(*widget.Button)
+
+	icon.Hide()
+	label.Hide()
+	button.Hide()
+
+	task, ok := v.item(id.Row)
+	if !ok {
+		return
+	}
+
+	switch id.Col {
+	case 0:
+		label.SetText(task.Title)
+	case 1:
+		if task.Completed {
+			label.SetText(i18n.T("common.completed"))
+		} else {
+			label.SetText(i18n.T("lists.notCompleted"))
+		}
+	case 2:
+		showPriorityIcon(icon, task.Priority)
+		label.SetText(formatPriority(task.Priority))
+	case 3:
+		label.SetText(dates.Format(task.DueDate))
+	case 4:
+		label.SetText(strings.Join(task.Tags, ", "))
+	case 5:
+		button.Show()
+		button.SetText(i18n.T("lists.delete"))
+		button.OnTapped = func() {
+			deleteTask(task.ID, v.window)
+		}
+	case 6:
+		button.Show()
+		button.SetText(i18n.T("lists.edit"))
+		button.OnTapped = func() {
+			editTask(task, v.window)
+		}
+	}
+	if id.Col < 5 {
+		label.Show()
+	}
+}
+
+// Lädt die Aufgaben mit dem aktuellen Filter neu
+func (v *tasksView) reload() {
+	v.mu.Lock()
+	filter := v.filter
+	v.mu.Unlock()
+	tasks, err := dataStore.QueryTasks(filter)
+	if err != nil {
+		log.Printf("Fehler beim Aktualisieren der Aufgaben: %v", err)
+		return
+	}
+	v.mu.Lock()
+	v.items = tasks
+	v.mu.Unlock()
+	v.table.Refresh()
+}
+
+func (v *tasksView) content() fyne.CanvasObject {
+	var top fyne.CanvasObject
+	top, v.search = newFilterBar(&v.filter, &v.mu, v.reload, newCompletedSelect(&v.filter, &v.mu, v.reload))
+	v.search.OnSubmitted = func(string) { v.window.Canvas().Focus(v.table) }
+	return container.NewPadded(container.NewBorder(top, nil, nil, nil, v.table))
+}
+
+func (v *tasksView) editSelected() {
+	if task, ok := v.item(v.table.row); ok {
+		editTask(task, v.window)
+	}
+}
+
+func (v *tasksView) deleteSelected() {
+	if task, ok := v.item(v.table.row); ok {
+		deleteTask(task.ID, v.window)
+	}
+}
+
+func (v *tasksView) focusSearch() {
+	v.window.Canvas().Focus(v.search)
+}
+
+// Zeigt das Fähnchen einer Prioritätsstufe an, ohne Priorität keines
+func showPriorityIcon(icon *widget.Icon, p *int) {
+	if p == nil {
+		return
+	}
+	icon.SetResource(assets.PriorityIcon(p))
+	icon.Show()
+}
+
+// Hält beide Listen und die Einstellungen aktuell, solange der Store Änderungen meldet
+func watchChanges(appointments *appointmentsView, tasks *tasksView, onSettings func()) {
+	changes, _ := dataStore.Subscribe()
+	go func() {
+		for c := range changes {
+			if c.Affects(store.TablePriorityLevels) {
+				loadPriorities()
+			}
+			if c.Affects(store.TableAppointments) {
+				appointments.reload()
+			}
+			if c.Affects(store.TableTasks) {
+				tasks.reload()
+			}
+			if c.Affects(store.TableSettings) {
+				onSettings()
+			}
+		}
+	}()
+}
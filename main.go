@@ -1,40 +1,35 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
+	"Reminder_Erinnerungs_App/assets"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/paths"
+	"Reminder_Erinnerungs_App/internal/priority"
 	"Reminder_Erinnerungs_App/internal/reminder"
+	"Reminder_Erinnerungs_App/internal/store"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	_ "github.com/mattn/go-sqlite3"
 )
 
-// Struktur für Termine
-type Appointment struct {
-	Title    string
-	Time     string
-	Priority *int // Priority als Zeiger auf int
-}
-
-// Struktur für Aufgaben
-type Task struct {
-	Title     string
-	Completed bool
-}
-
 // Benutzerdefinierter Entry für Datumsauswahl
 type DateEntry struct {
 	widget.Entry
@@ -45,7 +40,7 @@ type DateEntry struct {
 func NewDateEntry(window fyne.Window) *DateEntry {
 	entry := &DateEntry{window: window}
 	entry.ExtendBaseWidget(entry)
-	entry.SetText("Klicken für Datumsauswahl")
+	entry.SetText(i18n.T("picker.clickForDate"))
 	return entry
 }
 
@@ -58,7 +53,7 @@ func (e *DateEntry) FocusGained() {
 
 	// Prüfe ob Zenity installiert ist
 	if _, err := exec.LookPath("zenity"); err != nil {
-		dialog.ShowError(fmt.Errorf("Zenity ist nicht installiert. Bitte installieren Sie es mit 'sudo apt-get install zenity'"), e.window)
+		dialog.ShowError(errors.New(i18n.T("picker.zenityMissing")), e.window)
 		return
 	}
 
@@ -67,7 +62,7 @@ func (e *DateEntry) FocusGained() {
 
 	// Ignoriere bestimmte Fehler (exit status 1 bei Abbruch)
 	if err != nil && !strings.Contains(err.Error(), "exit status 1") {
-		dialog.ShowError(fmt.Errorf("Fehler beim Ausführen von Zenity"), e.window)
+		dialog.ShowError(errors.New(i18n.T("picker.zenityFailed")), e.window)
 		return
 	}
 
@@ -77,7 +72,7 @@ func (e *DateEntry) FocusGained() {
 	datePattern := regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
 	if match := datePattern.FindString(outputStr); match != "" {
 		e.justSelected = true
-		e.SetText(convertToGermanDate(match))
+		e.SetText(dates.Format(match))
 	}
 
 	e.Entry.FocusGained()
@@ -93,7 +88,7 @@ type TimeEntry struct {
 func NewTimeEntry(window fyne.Window) *TimeEntry {
 	entry := &TimeEntry{window: window}
 	entry.ExtendBaseWidget(entry)
-	entry.SetText("Klicken für Zeitauswahl")
+	entry.SetText(i18n.T("picker.clickForTime"))
 	return entry
 }
 
@@ -106,7 +101,7 @@ func (e *TimeEntry) FocusGained() {
 
 	// Prüfe ob YAD installiert ist
 	if _, err := exec.LookPath("yad"); err != nil {
-		dialog.ShowError(fmt.Errorf("YAD ist nicht installiert. Bitte installieren Sie es mit 'sudo apt-get install yad'"), e.window)
+		dialog.ShowError(errors.New(i18n.T("picker.yadMissing")), e.window)
 		return
 	}
 
@@ -129,11 +124,11 @@ func (e *TimeEntry) FocusGained() {
 	hoursStr := strings.Join(hours, "!")
 	minutesStr := strings.Join(minutes, "!")
 
-	cmd := exec.Command("yad", "--title=Zeit auswählen",
+	cmd := exec.Command("yad", "--title="+i18n.T("picker.chooseTime"),
 		"--form",
-		"--field=Stunde:CB", hoursStr,
-		"--field=Minute:CB", minutesStr,
-		"--button=Auswählen:0",
+		"--field="+i18n.T("picker.hour")+":CB", hoursStr,
+		"--field="+i18n.T("picker.minute")+":CB", minutesStr,
+		"--button="+i18n.T("picker.choose")+":0",
 		"--button=gtk-cancel:1",
 		"--width=300",
 		"--height=150",
@@ -148,8 +143,8 @@ func (e *TimeEntry) FocusGained() {
 			return
 		}
 		// Bei anderen Fehlern zeige eine Fehlermeldung
-		errMsg := fmt.Sprintf("Fehler beim Ausführen von YAD: %v\nOutput: %s", err, string(output))
-		dialog.ShowError(fmt.Errorf(errMsg), e.window)
+		errMsg := i18n.T("picker.yadFailed", "Error", err, "Output", string(output))
+		dialog.ShowError(errors.New(errMsg), e.window)
 		return
 	}
 
@@ -168,66 +163,68 @@ func (e *TimeEntry) FocusGained() {
 }
 
 var (
-	db                *sql.DB
-	appointmentsTable *widget.Table
-	tasksTable        *widget.Table
-	appointmentsList  [][]string
-	tasksList         [][]string
-	reminderService   *reminder.ReminderService
+	dataStore       *store.Store
+	reminderService *reminder.ReminderService
+	priorities      = priority.Defaults()
+	appConfig       = config.Default()
+	configFlags     *config.Flags // -config und -db, zum Neuladen nach den Einstellungen
+	dbPath          string
+
+	// Zeigt den Stand des CalDAV-Abgleichs neu an, z.B. nach geänderten Einstellungen
+	refreshSyncStatus = func() {}
 )
 
-// Neue Hilfsfunktionen für die Datumskonvertierung
-func convertToGermanDate(isoDate string) string {
-	// Konvertiert von YYYY-MM-DD zu DD.MM.YYYY
-	if len(isoDate) != 10 {
-		return isoDate
+// Anzeigetext für eine optionale Priorität
+func formatPriority(p *int) string {
+	if p == nil {
+		return i18n.T("common.noPriority")
 	}
-	parts := strings.Split(isoDate, "-")
-	if len(parts) != 3 {
-		return isoDate
+	return priorities.Lookup(p).Name
+}
+
+// Erstellt eine ComboBox mit den Namen der Prioritätsstufen
+func newPrioritySelect(p *int) *widget.Select {
+	prioritySelect := widget.NewSelect(priorities.Names(), nil)
+	if p != nil {
+		prioritySelect.SetSelected(priorities.Lookup(p).Name)
 	}
-	return fmt.Sprintf("%s.%s.%s", parts[2], parts[1], parts[0])
+	prioritySelect.PlaceHolder = i18n.T("common.choosePriority")
+	return prioritySelect
 }
 
-func convertToISODate(germanDate string) string {
-	// Konvertiert von DD.MM.YYYY zu YYYY-MM-DD
-	if len(germanDate) != 10 {
-		return germanDate
+// Liest die gewählte Priorität aus einer ComboBox
+func selectedPriority(prioritySelect *widget.Select) *int {
+	if prioritySelect.Selected == "" {
+		return nil
+	}
+	p, err := priorities.Parse(prioritySelect.Selected)
+	if err != nil {
+		return nil
 	}
-	parts := strings.Split(germanDate, ".")
-	if len(parts) != 3 {
-		return germanDate
+	return &p
+}
+
+// Lädt die Prioritätsstufen aus der Datenbank
+func loadPriorities() {
+	levels, err := dataStore.PriorityLevels()
+	if err != nil {
+		log.Printf("Fehler beim Laden der Prioritäten: %v", err)
+		return
 	}
-	return fmt.Sprintf("%s-%s-%s", parts[2], parts[1], parts[0])
+	priorities = levels
 }
 
 // Funktion zum Initialisieren der Datenbank
 func initDB() {
 	var err error
-	db, err = sql.Open("sqlite3", "./reminder.db")
-	if err != nil {
+	if dbPath, err = paths.Database(appConfig.DBPath); err != nil {
 		log.Fatal(err)
 	}
-
-	// Tabellen erstellen, falls sie nicht existieren
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS appointments (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT,
-		date TEXT,  -- Separates Datumsfeld
-		time TEXT,  -- Separates Zeitfeld
-		priority INTEGER
-	);
-	CREATE TABLE IF NOT EXISTS tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT,
-		completed BOOLEAN
-	);
-	`
-	_, err = db.Exec(createTableSQL)
+	dataStore, err = store.Open(dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
+	loadPriorities()
 }
 
 // Funktion zum Hinzufügen eines Termins
@@ -235,512 +232,335 @@ func addAppointment(myWindow fyne.Window) {
 	titleEntry := widget.NewEntry()
 	dateEntry := NewDateEntry(myWindow)
 	timeEntry := NewTimeEntry(myWindow)
+	tagsEntry := widget.NewEntry()
+	tagsEntry.PlaceHolder = i18n.T("common.tagsPlaceholder")
+	notesEntry := widget.NewMultiLineEntry()
 
-	// Aktuelles Datum im ISO-Format
+	// Aktuelles Datum in der eingestellten Sprache
 	now := time.Now()
-	dateEntry.SetText(convertToGermanDate(now.Format("2006-01-02")))
+	dateEntry.SetText(dates.Format(now.Format("2006-01-02")))
 
 	// Aktuelle Zeit (gerundet auf die nächste Viertelstunde)
-	roundedMinutes := ((now.Minute() + 14) / 15) * 15
-	roundedTime := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), roundedMinutes, 0, 0, now.Location())
-	timeEntry.SetText(roundedTime.Format("15:04"))
-
-	// Erstelle ComboBox für Priorität mit Vorauswahl 1
-	prioritySelect := widget.NewSelect([]string{"1", "2", "3"}, nil)
-	prioritySelect.SetSelected("1") // Setze Priorität 1 als Standard
-	prioritySelect.PlaceHolder = "Priorität wählen"
-
-	dialog.ShowForm("Neuen Termin hinzufügen", "Hinzufügen", "Abbrechen", []*widget.FormItem{
-		widget.NewFormItem("Titel", titleEntry),
-		widget.NewFormItem("Datum", dateEntry),
-		widget.NewFormItem("Uhrzeit", timeEntry),
-		widget.NewFormItem("Priorität", prioritySelect),
+	timeEntry.SetText(nextQuarterHour(now).Format("15:04"))
+
+	// Erstelle ComboBox für Priorität mit Vorauswahl "Normal"
+	defaultPriority := priority.Normal
+	prioritySelect := newPrioritySelect(&defaultPriority)
+
+	dialog.ShowForm(i18n.T("main.newAppointment"), i18n.T("common.add"), i18n.T("common.cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("common.title"), titleEntry),
+		widget.NewFormItem(i18n.T("common.date"), dateEntry),
+		widget.NewFormItem(i18n.T("common.time"), timeEntry),
+		widget.NewFormItem(i18n.T("common.priority"), prioritySelect),
+		widget.NewFormItem(i18n.T("common.tags"), tagsEntry),
+		widget.NewFormItem(i18n.T("common.notes"), notesEntry),
 	}, func(submitted bool) {
 		if submitted {
-			title := titleEntry.Text
-			date := convertToISODate(dateEntry.Text) // Konvertiere zurück zu ISO für DB
-			time := timeEntry.Text
-
-			// Priorität aus ComboBox
-			var priority *int
-			if prioritySelect.Selected != "" {
-				p, _ := strconv.Atoi(prioritySelect.Selected)
-				priority = &p
+			appointment := store.Appointment{
+				Title:    titleEntry.Text,
+				Date:     dates.Parse(dateEntry.Text), // Konvertiere zurück zu ISO für DB
+				Time:     timeEntry.Text,
+				Priority: selectedPriority(prioritySelect),
+				Notes:    notesEntry.Text,
+				Tags:     store.SplitTags(tagsEntry.Text),
 			}
 
 			// Speichern des Termins in der Datenbank
-			_, err := db.Exec("INSERT INTO appointments (title, date, time, priority) VALUES (?, ?, ?, ?)",
-				title, date, time, priority)
-			if err != nil {
+			if err := backend().AddAppointment(&appointment); err != nil {
 				log.Printf("Fehler beim Speichern des Termins: %v", err)
-				dialog.ShowInformation("Fehler", "Fehler beim Speichern des Termins: "+err.Error(), myWindow)
+				dialog.ShowInformation(i18n.T("common.error"), err.Error(), myWindow)
 				return
 			}
-			dialog.ShowInformation("Termin hinzugefügt",
-				fmt.Sprintf("Titel: %s\nDatum: %s\nUhrzeit: %s\nPriorität: %s",
-					title,
-					dateEntry.Text, // Zeigt deutsches Format
-					timeEntry.Text,
-					func() string {
-						if priority == nil {
-							return "Keine"
-						}
-						return fmt.Sprintf("%d", *priority)
-					}()),
+			dialog.ShowInformation(i18n.T("main.appointmentAdded"),
+				i18n.T("main.appointmentSummary",
+					"Title", appointment.Title,
+					"Date", dateEntry.Text, // wie eingegeben, in der eingestellten Sprache
+					"Time", dates.FormatTime(timeEntry.Text),
+					"Priority", formatPriority(appointment.Priority)),
 				myWindow)
 		}
 	}, myWindow)
 }
 
+// nextQuarterHour rundet t auf die nächste Viertelstunde auf
+func nextQuarterHour(t time.Time) time.Time {
+	roundedMinutes := ((t.Minute() + 14) / 15) * 15
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), roundedMinutes, 0, 0, t.Location())
+}
+
 // Funktion zum Hinzufügen einer Aufgabe
 func addTask(myWindow fyne.Window) {
 	titleEntry := widget.NewEntry()
-
-	dialog.ShowForm("Neue Aufgabe hinzufügen", "Hinzufügen", "Abbrechen", []*widget.FormItem{
-		widget.NewFormItem("Titel", titleEntry),
+	prioritySelect := newPrioritySelect(nil)
+	dueEntry := widget.NewEntry()
+	dueEntry.PlaceHolder = i18n.T("common.dueDatePlaceholder")
+	tagsEntry := widget.NewEntry()
+	tagsEntry.PlaceHolder = i18n.T("common.tagsPlaceholder")
+	notesEntry := widget.NewMultiLineEntry()
+
+	dialog.ShowForm(i18n.T("main.newTask"), i18n.T("common.add"), i18n.T("common.cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("common.title"), titleEntry),
+		widget.NewFormItem(i18n.T("common.priority"), prioritySelect),
+		widget.NewFormItem(i18n.T("common.due"), dueEntry),
+		widget.NewFormItem(i18n.T("common.tags"), tagsEntry),
+		widget.NewFormItem(i18n.T("common.notes"), notesEntry),
 	}, func(submitted bool) {
 		if submitted {
-			title := titleEntry.Text
+			task := store.Task{
+				Title:    titleEntry.Text,
+				Priority: selectedPriority(prioritySelect),
+				DueDate:  dates.Parse(dueEntry.Text),
+				Notes:    notesEntry.Text,
+				Tags:     store.SplitTags(tagsEntry.Text),
+			}
 
 			// Speichern der Aufgabe in der Datenbank
-			_, err := db.Exec("INSERT INTO tasks (title, completed) VALUES (?, ?)", title, false)
-			if err != nil {
+			if err := backend().AddTask(&task); err != nil {
 				log.Printf("Fehler beim Speichern der Aufgabe: %v", err) // Debugging-Information
-				dialog.ShowInformation("Fehler", "Fehler beim Speichern der Aufgabe: "+err.Error(), myWindow)
+				dialog.ShowInformation(i18n.T("common.error"), err.Error(), myWindow)
 				return
 			}
-			dialog.ShowInformation("Aufgabe hinzugefügt", "Titel: "+title, myWindow)
+			dialog.ShowInformation(i18n.T("main.taskAdded"), i18n.T("main.taskSummary", "Title", task.Title), myWindow)
 		}
 	}, myWindow)
 }
 
-// Funktion zum Anzeigen aller Termine in einem neuen Fenster
-func showAppointments(myWindow fyne.Window, myApp fyne.App) {
-	rows, err := db.Query("SELECT title, date, time, priority FROM appointments")
-	if err != nil {
-		log.Printf("Fehler beim Abrufen der Termine: %v", err)
-		dialog.ShowInformation("Fehler", "Fehler beim Abrufen der Termine: "+err.Error(), myWindow)
-		return
-	}
-	defer rows.Close()
-
-	var appointments [][]string
-	for rows.Next() {
-		var title, date string
-		var timeStr sql.NullString
-		var priority sql.NullInt64
-		if err := rows.Scan(&title, &date, &timeStr, &priority); err != nil {
-			log.Printf("Fehler beim Scannen der Termine: %v", err)
-			dialog.ShowInformation("Fehler", "Fehler beim Scannen der Termine: "+err.Error(), myWindow)
-			return
-		}
-
-		// Setze einen Standardwert für die Zeit, wenn sie NULL ist
-		time := "Keine Zeit"
-		if timeStr.Valid {
-			time = timeStr.String
-		}
-
-		priorityValue := "Keine Priorität"
-		if priority.Valid {
-			priorityValue = fmt.Sprintf("%d", priority.Int64)
-		}
-
-		// Konvertiere das Datum ins deutsche Format für die Anzeige
-		germanDate := convertToGermanDate(date)
-		appointments = append(appointments, []string{title, germanDate, time, priorityValue})
-	}
-
-	if len(appointments) == 0 {
-		dialog.ShowInformation("Termine", "Keine Termine gefunden.", myWindow)
-		return
-	}
-
-	appointmentsList = appointments
-	appointmentsTable = widget.NewTable(
-		func() (int, int) {
-			return len(appointmentsList), 5
-		},
-		func() fyne.CanvasObject {
-			// Erstelle einen Container mit einem Label für Text-Spalten und einem Button für Aktions-Spalten
-			return container.NewHBox(
-				widget.NewLabel(""),
-				widget.NewButton("", nil), // Platzhalter für Buttons
-			)
-		},
-		func(id widget.TableCellID, cell fyne.CanvasObject) {
-			container := cell.(*fyne.Container)
-			label := container.Objects[0].(*widget.Label)
-			button := container.Objects[1].(*widget.Button)
-
-			// Standardmäßig alles ausblenden
-			label.Hide()
-			button.Hide()
-
-			if id.Col < 3 {
-				// Text-Spalten (Titel, Zeit, Priorität)
-				label.Show()
-				label.SetText(appointmentsList[id.Row][id.Col])
-			} else if id.Col == 3 {
-				// Löschen-Button
-				button.Show()
-				button.SetText("Löschen")
-				button.OnTapped = func() {
-					deleteAppointment(appointmentsList[id.Row][0], myWindow)
-				}
-			} else if id.Col == 4 {
-				// Ändern-Button
-				button.Show()
-				button.SetText("Ändern")
-				button.OnTapped = func() {
-					editAppointment(appointmentsList[id.Row][0], appointmentsList[id.Row][1], appointmentsList[id.Row][2], myWindow)
-				}
-			}
-		},
-	)
-
-	appointmentsTable.SetColumnWidth(0, 200)
-	appointmentsTable.SetColumnWidth(1, 100)
-	appointmentsTable.SetColumnWidth(2, 80)
-	appointmentsTable.SetColumnWidth(3, 80)
-	appointmentsTable.SetColumnWidth(4, 80)
-
-	scrollContainer := container.NewScroll(appointmentsTable)
-
-	// Erstelle den "Alle Termine löschen" Button
-	deleteAllButton := widget.NewButton("Alle Termine löschen", func() {
-		reminderService.DeleteAllAppointments()
-		refreshAppointmentsTable()
-	})
-	deleteAllButton.Importance = widget.DangerImportance
-
-	// Erstelle einen horizontalen Container für den Button (rechts ausgerichtet)
-	buttonContainer := container.NewHBox(
-		layout.NewSpacer(), // Drückt den Button nach rechts
-		deleteAllButton,
-	)
-
-	// Hauptcontainer mit Button oben und Tabelle darunter
-	content := container.NewBorder(
-		buttonContainer, // Top
-		nil,             // Bottom
-		nil,             // Left
-		nil,             // Right
-		scrollContainer, // Center (nimmt den restlichen Platz ein)
-	)
-
-	content = container.NewPadded(content)
-
-	d := dialog.NewCustom("Alle Termine", "Schließen", content, myWindow)
-	d.Resize(fyne.NewSize(800, 400))
-	d.Show()
-}
-
-// Funktion zum Anzeigen aller Aufgaben in einem neuen Fenster
-func showTasks(myWindow fyne.Window, myApp fyne.App) {
-	rows, err := db.Query("SELECT title, completed FROM tasks")
-	if err != nil {
-		log.Printf("Fehler beim Abrufen der Aufgaben: %v", err) // Debugging-Information
-		dialog.ShowInformation("Fehler", "Fehler beim Abrufen der Aufgaben: "+err.Error(), myWindow)
-		return
-	}
-	defer rows.Close()
-
-	var tasks [][]string
-	for rows.Next() {
-		var title string
-		var completed bool
-		if err := rows.Scan(&title, &completed); err != nil {
-			log.Printf("Fehler beim Scannen der Aufgaben: %v", err) // Debugging-Information
-			dialog.ShowInformation("Fehler", "Fehler beim Scannen der Aufgaben: "+err.Error(), myWindow)
-			return
-		}
-		status := "Nicht abgeschlossen"
-		if completed {
-			status = "Abgeschlossen"
-		}
-		tasks = append(tasks, []string{title, status})
-	}
-
-	if len(tasks) == 0 {
-		dialog.ShowInformation("Aufgaben", "Keine Aufgaben gefunden.", myWindow)
-		return
-	}
-
-	tasksList = tasks
-	tasksTable = widget.NewTable(
-		func() (int, int) {
-			return len(tasksList), 4
-		},
-		func() fyne.CanvasObject {
-			return container.NewHBox(widget.NewLabel(""))
-		},
-		func(id widget.TableCellID, cell fyne.CanvasObject) {
-			if id.Col < 2 {
-				cell.(*fyne.Container).Objects[0].(*widget.Label).SetText(tasksList[id.Row][id.Col])
-			} else if id.Col == 2 {
-				deleteBtn := widget.NewButton("Löschen", func() {
-					deleteTask(tasksList[id.Row][0], myWindow)
-				})
-				cell.(*fyne.Container).Objects = []fyne.CanvasObject{deleteBtn}
-			} else if id.Col == 3 {
-				editBtn := widget.NewButton("Ändern", func() {
-					editTask(tasksList[id.Row][0], tasksList[id.Row][1], myWindow)
-				})
-				cell.(*fyne.Container).Objects = []fyne.CanvasObject{editBtn}
-			}
-		},
-	)
-
-	tasksTable.SetColumnWidth(0, 250)
-	tasksTable.SetColumnWidth(1, 130)
-	tasksTable.SetColumnWidth(2, 80)
-	tasksTable.SetColumnWidth(3, 80)
-
-	scrollContainer := container.NewScroll(tasksTable)
-	content := container.NewPadded(scrollContainer)
-
-	d := dialog.NewCustom("Alle Aufgaben", "Schließen", content, myWindow)
-	d.Resize(fyne.NewSize(800, 400))
-	d.Show()
-}
-
 // Termin löschen
-func deleteAppointment(title string, myWindow fyne.Window) {
-	dialog.ShowConfirm("Löschen bestätigen",
-		"Möchten Sie diesen Termin wirklich löschen?",
+func deleteAppointment(id int64, myWindow fyne.Window) {
+	dialog.ShowConfirm(i18n.T("common.confirmDelete"),
+		i18n.T("main.deleteAppointment"),
 		func(confirm bool) {
 			if confirm {
-				_, err := db.Exec("DELETE FROM appointments WHERE title = ?", title)
+				op, err := backend().DeleteAppointment(id)
 				if err != nil {
 					dialog.ShowError(err, myWindow)
 					return
 				}
-				// Aktualisiere die Tabelle
-				refreshAppointmentsTable()
+				showUndoToast(i18n.T("undo.appointmentDeleted"), op, myWindow)
 			}
 		}, myWindow)
 }
 
 // Termin bearbeiten
-func editAppointment(title, time, priority string, myWindow fyne.Window) {
+func editAppointment(appointment store.Appointment, myWindow fyne.Window) {
 	titleEntry := widget.NewEntry()
-	titleEntry.SetText(title)
+	titleEntry.SetText(appointment.Title)
 
 	// Verwende die benutzerdefinierten Entries für Datum und Zeit
 	dateEntry := NewDateEntry(myWindow)
 	timeEntry := NewTimeEntry(myWindow)
-
-	// Wenn time ein Datum enthält, parse es und setze die Werte
-	if len(time) > 0 {
-		dateEntry.SetText(time) // Zeit wird im deutschen Format angezeigt
-		if t := strings.Split(time, " "); len(t) > 1 {
-			timeEntry.SetText(t[1])
-		}
+	dateEntry.SetText(dates.Format(appointment.Date)) // Datum in der eingestellten Sprache
+	if appointment.Time != "" {
+		timeEntry.SetText(appointment.Time)
 	}
 
 	// Erstelle ComboBox für Priorität
-	prioritySelect := widget.NewSelect([]string{"1", "2", "3"}, nil)
-	if priority != "Keine Priorität" {
-		prioritySelect.SetSelected(priority)
-	}
-	prioritySelect.PlaceHolder = "Priorität wählen"
+	prioritySelect := newPrioritySelect(appointment.Priority)
 
-	dialog.ShowForm("Termin bearbeiten", "Speichern", "Abbrechen",
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetText(strings.Join(appointment.Tags, ", "))
+	notesEntry := widget.NewMultiLineEntry()
+	notesEntry.SetText(appointment.Notes)
+
+	dialog.ShowForm(i18n.T("main.editAppointment"), i18n.T("common.save"), i18n.T("common.cancel"),
 		[]*widget.FormItem{
-			widget.NewFormItem("Titel", titleEntry),
-			widget.NewFormItem("Datum", dateEntry),
-			widget.NewFormItem("Uhrzeit", timeEntry),
-			widget.NewFormItem("Priorität", prioritySelect),
+			widget.NewFormItem(i18n.T("common.title"), titleEntry),
+			widget.NewFormItem(i18n.T("common.date"), dateEntry),
+			widget.NewFormItem(i18n.T("common.time"), timeEntry),
+			widget.NewFormItem(i18n.T("common.priority"), prioritySelect),
+			widget.NewFormItem(i18n.T("common.tags"), tagsEntry),
+			widget.NewFormItem(i18n.T("common.notes"), notesEntry),
 		},
 		func(submitted bool) {
 			if submitted {
-				// Priorität aus ComboBox
-				var priorityInt *int
-				if prioritySelect.Selected != "" {
-					p, _ := strconv.Atoi(prioritySelect.Selected)
-					priorityInt = &p
-				}
-
+				appointment.Title = titleEntry.Text
 				// Konvertiere das Datum zurück ins ISO-Format für die DB
-				date := convertToISODate(dateEntry.Text)
+				appointment.Date = dates.Parse(dateEntry.Text)
+				appointment.Time = timeEntry.Text
+				appointment.Priority = selectedPriority(prioritySelect)
+				appointment.Tags = store.SplitTags(tagsEntry.Text)
+				appointment.Notes = notesEntry.Text
 
-				_, err := db.Exec(`
-					UPDATE appointments 
-					SET title = ?, date = ?, time = ?, priority = ? 
-					WHERE title = ?`,
-					titleEntry.Text, date, timeEntry.Text, priorityInt, title)
-				if err != nil {
+				if err := backend().UpdateAppointment(appointment); err != nil {
 					dialog.ShowError(err, myWindow)
 					return
 				}
 
 				// Zeige Bestätigung
-				dialog.ShowInformation("Termin aktualisiert",
-					fmt.Sprintf("Titel: %s\nDatum: %s\nUhrzeit: %s\nPriorität: %s",
-						titleEntry.Text,
-						dateEntry.Text,
-						timeEntry.Text,
-						func() string {
-							if priorityInt == nil {
-								return "Keine"
-							}
-							return fmt.Sprintf("%d", *priorityInt)
-						}()),
+				dialog.ShowInformation(i18n.T("main.appointmentUpdated"),
+					i18n.T("main.appointmentSummary",
+						"Title", titleEntry.Text,
+						"Date", dateEntry.Text,
+						"Time", dates.FormatTime(timeEntry.Text),
+						"Priority", formatPriority(appointment.Priority)),
 					myWindow)
-
-				// Aktualisiere die Tabelle
-				refreshAppointmentsTable()
 			}
 		}, myWindow)
 }
 
 // Aufgabe löschen
-func deleteTask(title string, myWindow fyne.Window) {
-	dialog.ShowConfirm("Löschen bestätigen",
-		"Möchten Sie diese Aufgabe wirklich löschen?",
+func deleteTask(id int64, myWindow fyne.Window) {
+	dialog.ShowConfirm(i18n.T("common.confirmDelete"),
+		i18n.T("main.deleteTask"),
 		func(confirm bool) {
 			if confirm {
-				_, err := db.Exec("DELETE FROM tasks WHERE title = ?", title)
+				op, err := backend().DeleteTask(id)
 				if err != nil {
 					dialog.ShowError(err, myWindow)
 					return
 				}
-				// Aktualisiere die Tabelle
-				refreshTasksTable()
+				showUndoToast(i18n.T("undo.taskDeleted"), op, myWindow)
 			}
 		}, myWindow)
 }
 
 // Aufgabe bearbeiten
-func editTask(title, status string, myWindow fyne.Window) {
+func editTask(task store.Task, myWindow fyne.Window) {
 	titleEntry := widget.NewEntry()
-	titleEntry.SetText(title)
-	completed := status == "Abgeschlossen"
-	completedCheck := widget.NewCheck("Abgeschlossen", nil)
-	completedCheck.Checked = completed
-
-	dialog.ShowForm("Aufgabe bearbeiten", "Speichern", "Abbrechen",
+	titleEntry.SetText(task.Title)
+	completedCheck := widget.NewCheck(i18n.T("common.completed"), nil)
+	completedCheck.Checked = task.Completed
+
+	prioritySelect := newPrioritySelect(task.Priority)
+	dueEntry := widget.NewEntry()
+	dueEntry.PlaceHolder = i18n.T("common.dueDatePlaceholder")
+	dueEntry.SetText(dates.Format(task.DueDate))
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetText(strings.Join(task.Tags, ", "))
+	notesEntry := widget.NewMultiLineEntry()
+	notesEntry.SetText(task.Notes)
+
+	dialog.ShowForm(i18n.T("main.editTask"), i18n.T("common.save"), i18n.T("common.cancel"),
 		[]*widget.FormItem{
-			widget.NewFormItem("Titel", titleEntry),
-			widget.NewFormItem("Status", completedCheck),
+			widget.NewFormItem(i18n.T("common.title"), titleEntry),
+			widget.NewFormItem(i18n.T("common.status"), completedCheck),
+			widget.NewFormItem(i18n.T("common.priority"), prioritySelect),
+			widget.NewFormItem(i18n.T("common.due"), dueEntry),
+			widget.NewFormItem(i18n.T("common.tags"), tagsEntry),
+			widget.NewFormItem(i18n.T("common.notes"), notesEntry),
 		},
 		func(submitted bool) {
 			if submitted {
-				_, err := db.Exec("UPDATE tasks SET title = ?, completed = ? WHERE title = ?",
-					titleEntry.Text, completedCheck.Checked, title)
-				if err != nil {
+				task.Title = titleEntry.Text
+				task.Completed = completedCheck.Checked
+				task.Priority = selectedPriority(prioritySelect)
+				task.DueDate = dates.Parse(dueEntry.Text)
+				task.Tags = store.SplitTags(tagsEntry.Text)
+				task.Notes = notesEntry.Text
+
+				if err := backend().UpdateTask(task); err != nil {
 					dialog.ShowError(err, myWindow)
 					return
 				}
-				// Aktualisiere die Tabelle
-				refreshTasksTable()
 			}
 		}, myWindow)
 }
 
-// Hilfsfunktionen zum Aktualisieren der Tabellen
-func refreshAppointmentsTable() {
-	rows, err := db.Query("SELECT title, date, time, priority FROM appointments")
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	appointmentsList = appointmentsList[:0] // Liste leeren
-	for rows.Next() {
-		var title, date string
-		var timeStr sql.NullString
-		var priority sql.NullInt64
-		if err := rows.Scan(&title, &date, &timeStr, &priority); err != nil {
-			continue
-		}
-
-		// Setze einen Standardwert für die Zeit, wenn sie NULL ist
-		time := "Keine Zeit"
-		if timeStr.Valid {
-			time = timeStr.String
-		}
-
-		priorityValue := "Keine Priorität"
-		if priority.Valid {
-			priorityValue = fmt.Sprintf("%d", priority.Int64)
-		}
-
-		// Konvertiere das Datum ins deutsche Format für die Anzeige
-		germanDate := convertToGermanDate(date)
-		appointmentsList = append(appointmentsList, []string{title, germanDate, time, priorityValue})
-	}
-	if appointmentsTable != nil {
-		appointmentsTable.Refresh()
-	}
-}
-
-func refreshTasksTable() {
-	rows, err := db.Query("SELECT title, completed FROM tasks")
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	tasksList = tasksList[:0] // Liste leeren
-	for rows.Next() {
-		var title string
-		var completed bool
-		if err := rows.Scan(&title, &completed); err != nil {
-			continue
-		}
-		status := "Nicht abgeschlossen"
-		if completed {
-			status = "Abgeschlossen"
-		}
-		tasksList = append(tasksList, []string{title, status})
-	}
-	if tasksTable != nil {
-		tasksTable.Refresh()
-	}
-}
-
 func main() {
 	// Unterdrücke Mesa-Fehlermeldungen
 	os.Setenv("MESA_DEBUG", "silent")
 
+	configFlags = config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	var err error
+	if appConfig, err = configFlags.Load(); err != nil {
+		log.Fatal(err)
+	}
+	i18n.SetLanguage(appConfig.Locale)
+
 	initDB()
-	defer db.Close()
+	defer dataStore.Close()
+
+	// Beim Daemon bekannt machen, welche Datenbank die GUI verwendet
+	unregister, err := paths.Register("gui", dbPath)
+	if err != nil {
+		log.Printf("Fehler beim Anmelden der GUI: %v", err)
+	}
+	defer unregister()
 
 	myApp := app.New()
-	myWindow := myApp.NewWindow("Reminder App")
-	myWindow.Resize(fyne.NewSize(600, 400))
+	myApp.SetIcon(assets.AppIcon)
+	myApp.Settings().SetTheme(assets.NewTheme(appConfig.Window.Theme))
+	myWindow := myApp.NewWindow(i18n.T("main.appTitle"))
+	windowSize := fyne.NewSize(float32(appConfig.Window.Width), float32(appConfig.Window.Height))
+	myWindow.Resize(windowSize)
 
 	// Reminder Service nach der Fenster-Erstellung initialisieren
-	reminderService = reminder.NewReminderService(db, myWindow)
-	reminderService.Start()
-	defer reminderService.Stop()
+	reminderService = reminder.NewReminderService(dataStore, myWindow)
+	if err := reminderService.SetConfig(appConfig); err != nil {
+		log.Fatal(err)
+	}
+
+	// Mit reminderd verbinden oder selbst erinnern, wenn er nicht läuft
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go connectDaemon(ctx)
 
-	// Positioniere das Hauptfenster auf dem zweiten Monitor (x > 1920)
+	// Positioniere das Hauptfenster, z.B. auf dem zuletzt verwendeten Monitor
 	myWindow.Show() // Fenster muss sichtbar sein, bevor wir es positionieren
-	myWindow.Resize(fyne.NewSize(600, 400))
-	x, y := 2000, 200 // x > 1920 für zweiten Monitor
-	myWindow.Canvas().Content().Move(fyne.NewPos(float32(x), float32(y)))
+	myWindow.Resize(windowSize)
+	placeMainWindow(myWindow)
+
+	appointments := newAppointmentsView(myWindow)
+	tasks := newTasksView(myWindow)
+	appointments.reload()
+	tasks.reload()
+	dndToggle, refreshDoNotDisturb := newDoNotDisturbToggle()
+	var syncStatus fyne.CanvasObject
+	syncStatus, refreshSyncStatus = newSyncStatus(myWindow)
+	watchChanges(appointments, tasks, func() {
+		refreshDoNotDisturb()
+		refreshSyncStatus()
+	})
 
-	hello := widget.NewLabel("Reminder - Erinnerungs - App!")
-	content := container.New(layout.NewVBoxLayout(),
+	hello := widget.NewLabel(i18n.T("main.greeting"))
+	toolbar := container.New(layout.NewHBoxLayout(),
 		hello,
-		widget.NewButton("Neuen Termin hinzufügen", func() {
+		layout.NewSpacer(),
+		syncStatus,
+		dndToggle,
+		widget.NewButtonWithIcon(i18n.T("main.newAppointment"), assets.AppointmentIcon, func() {
 			addAppointment(myWindow)
 		}),
-		widget.NewButton("Neue Aufgabe hinzufügen", func() {
+		widget.NewButtonWithIcon(i18n.T("main.newTask"), assets.TaskIcon, func() {
 			addTask(myWindow)
 		}),
-		widget.NewButton("Alle Termine anzeigen", func() {
-			showAppointments(myWindow, myApp)
-		}),
-		widget.NewButton("Alle Aufgaben anzeigen", func() {
-			showTasks(myWindow, myApp)
+		widget.NewButtonWithIcon(i18n.T("main.settings"), theme.SettingsIcon(), func() {
+			showSettings(myWindow)
 		}),
 	)
-	leftAligned := container.New(layout.NewHBoxLayout(), content, layout.NewSpacer())
+	tabs := container.NewAppTabs(
+		container.NewTabItemWithIcon(i18n.T("main.appointments"), assets.AppointmentIcon, appointments.content()),
+		container.NewTabItemWithIcon(i18n.T("main.tasks"), assets.TaskIcon, tasks.content()),
+	)
 
-	myWindow.SetContent(leftAligned)
+	// Mit Tray-Symbol schließt das Fenster nur in den Tray, die Erinnerungen
+	// laufen weiter; beenden über das Tray-Menü oder „Datei“
+	tray := runTray(ctx, myApp, myWindow)
+	myWindow.SetCloseIntercept(func() {
+		rememberMainWindow(myWindow)
+		if tray && appConfig.Window.CloseToTray {
+			myWindow.Hide()
+			return
+		}
+		myWindow.Close()
+	})
+
+	// Tastenkürzel und Befehlspalette (Strg+K) kommen aus den Menüs
+	fileMenu := newFileMenu(myWindow)
+	actionsMenu := newActionsMenu(myWindow, tabs, appointments, tasks)
+	addShortcuts(myWindow, fileMenu, actionsMenu)
+	myWindow.SetMainMenu(fyne.NewMainMenu(fileMenu, actionsMenu))
+	// Nach dem Löschen wird „Rückgängig“ am unteren Rand angeboten
+	var toast fyne.CanvasObject
+	toast, showToast = newToast()
+	reminderService.SetUndoHint(func(op store.Operation) {
+		showUndoToast(i18n.T("undo.appointmentsDeleted"), op, myWindow)
+	})
+	myWindow.SetContent(container.NewBorder(toolbar, toast, nil, nil, tabs))
+	if warning := paths.CheckShared("reminderd", dbPath); warning != "" {
+		log.Printf("Warnung: %s", warning)
+		dialog.ShowInformation(i18n.T("main.differentDatabases"), warning, myWindow)
+	}
 	myWindow.ShowAndRun()
 }
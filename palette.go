@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Eintrag der Befehlspalette
+type paletteCommand struct {
+	label    string
+	menu     string
+	shortcut string
+	run      func()
+}
+
+// Sammelt die Befehle aus allen Menüs des Fensters
+func paletteCommands(menus []*fyne.Menu) []paletteCommand {
+	var commands []paletteCommand
+	for _, m := range menus {
+		for _, item := range m.Items {
+			if item.IsSeparator || item.Disabled || item.Action == nil {
+				continue
+			}
+			c := paletteCommand{label: item.Label, menu: m.Label, run: item.Action}
+			if item.Shortcut != nil {
+				c.shortcut = shortcutText(item.Shortcut)
+			}
+			commands = append(commands, c)
+		}
+	}
+	return commands
+}
+
+// Ein Befehl passt, wenn jedes Wort der Suche in seinem Namen oder dem des
+// Menüs vorkommt
+func (c paletteCommand) matches(query string) bool {
+	text := strings.ToLower(c.label + " " + c.menu)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Suchfeld der Befehlspalette: Pfeiltasten wählen den Befehl, Escape schließt
+type paletteEntry struct {
+	widget.Entry
+	onKey func(fyne.KeyName) bool // true = Taste behandelt
+}
+
+func (e *paletteEntry) TypedKey(k *fyne.KeyEvent) {
+	if e.onKey(k.Name) {
+		return
+	}
+	e.Entry.TypedKey(k)
+}
+
+// Zeigt alle Befehle der Menüs mit ihren Tastenkürzeln. Tippen filtert,
+// Enter oder ein Klick führt den gewählten Befehl aus.
+func showCommandPalette(w fyne.Window) {
+	if w.MainMenu() == nil {
+		return
+	}
+	commands := paletteCommands(w.MainMenu().Items)
+	visible, selected := commands, 0
+
+	var d dialog.Dialog
+	run := func(i int) {
+		if i < 0 || i >= len(visible) {
+			return
+		}
+		d.Hide()
+		visible[i].run()
+	}
+
+	list := widget.NewList(
+		func() int { return len(visible) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(widget.NewLabel(""), layout.NewSpacer(), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			c := o.(*fyne.Container)
+			label := c.Objects[0].(*widget.Label)
+			label.SetText(visible[id].label)
+			label.Importance = widget.MediumImportance
+			if id == selected {
+				label.Importance = widget.HighImportance
+			}
+			label.Refresh()
+			shortcut := c.Objects[2].(*widget.Label)
+			shortcut.Importance = widget.LowImportance
+			shortcut.SetText(visible[id].shortcut)
+		},
+	)
+	// Per Maus gleich ausführen; die Tastatur markiert nur, siehe selected
+	list.OnSelected = func(id widget.ListItemID) {
+		list.UnselectAll()
+		run(id)
+	}
+
+	query := &paletteEntry{}
+	query.ExtendBaseWidget(query)
+	query.PlaceHolder = i18n.T("palette.placeholder")
+	query.OnChanged = func(s string) {
+		visible = nil
+		for _, c := range commands {
+			if c.matches(s) {
+				visible = append(visible, c)
+			}
+		}
+		selected = 0
+		list.Refresh()
+		list.ScrollToTop()
+	}
+	query.OnSubmitted = func(string) { run(selected) }
+	query.onKey = func(k fyne.KeyName) bool {
+		switch k {
+		case fyne.KeyUp, fyne.KeyDown:
+			if len(visible) == 0 {
+				return true
+			}
+			if k == fyne.KeyUp {
+				selected = (selected + len(visible) - 1) % len(visible)
+			} else {
+				selected = (selected + 1) % len(visible)
+			}
+			list.ScrollTo(selected)
+			list.Refresh()
+		case fyne.KeyEscape:
+			d.Hide()
+		default:
+			return false
+		}
+		return true
+	}
+
+	d = dialog.NewCustomWithoutButtons(i18n.T("palette.title"),
+		container.NewBorder(query, nil, nil, nil, list), w)
+	d.Resize(fyne.NewSize(480, 400))
+	d.Show()
+	w.Canvas().Focus(query)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+
+	"Reminder_Erinnerungs_App/internal/screens"
+
+	"fyne.io/fyne/v2"
+)
+
+// Einstellungen zur Lage des Hauptfensters: zuletzt verwendeter Monitor und
+// je Monitor Position (Pixel) und Größe (wie width/height unter [window])
+const (
+	settingWindowMonitor  = "window.monitor"
+	settingWindowGeometry = "window.geometry." // + Name des Monitors
+)
+
+// placeMainWindow stellt das sichtbare Hauptfenster auf den Monitor aus
+// [window] monitor oder den zuletzt verwendeten, mit der dort zuletzt
+// genutzten Position und Größe. Fehlt der Monitor, kommt es auf den
+// Hauptmonitor; ohne xrandr gilt nur x/y aus der Konfiguration.
+func placeMainWindow(w fyne.Window) {
+	x, y := appConfig.Window.X, appConfig.Window.Y
+	want := appConfig.Window.Monitor
+	if want == "" {
+		want, _ = dataStore.Setting(settingWindowMonitor, "")
+	}
+	monitors, err := screens.List()
+	if err != nil || want == "" {
+		if err != nil && want != "" {
+			log.Printf("%v", err)
+		}
+		// 0/0 = vom Fenstermanager platzieren lassen
+		if x != 0 || y != 0 {
+			moveWindow(w, x, y)
+		}
+		return
+	}
+
+	m := screens.Choose(monitors, want)
+	if value, _ := dataStore.Setting(settingWindowGeometry+m.Name, ""); value != "" {
+		r, err := screens.ParseRect(value)
+		if err == nil && m.Contains(r.X, r.Y) {
+			w.Resize(fyne.NewSize(float32(r.Width), float32(r.Height)))
+			moveWindow(w, r.X, r.Y)
+			return
+		}
+	}
+	if (x == 0 && y == 0) || !m.Contains(x, y) {
+		r, err := screens.Geometry(w)
+		if err != nil {
+			log.Printf("%v", err)
+			return
+		}
+		x, y = m.Center(r.Width, r.Height)
+	}
+	moveWindow(w, x, y)
+}
+
+func moveWindow(w fyne.Window, x, y int) {
+	if err := screens.Move(w, x, y); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// rememberMainWindow merkt sich Monitor, Position und Größe des sichtbaren
+// Hauptfensters für den nächsten Start
+func rememberMainWindow(w fyne.Window) {
+	r, err := screens.Geometry(w)
+	if err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	monitors, err := screens.List()
+	if err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	m, ok := screens.At(monitors, r.X+r.Width/2, r.Y+r.Height/2)
+	if !ok {
+		return
+	}
+	size := w.Canvas().Size()
+	r.Width, r.Height = int(size.Width), int(size.Height)
+	if err := backend().SetSetting(settingWindowGeometry+m.Name, r.String()); err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	if err := backend().SetSetting(settingWindowMonitor, m.Name); err != nil {
+		log.Printf("%v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Erstellt die Such- und Filterleiste über den Listen. Jede Änderung wird
+// unter mu in den Filter geschrieben und löst onChange aus; mu schützt den
+// Filter auch beim Neuladen nach Änderungen im Store. Das Suchfeld wird
+// für Strg+F mitgeliefert.
+func newFilterBar(f *store.Filter, mu sync.Locker, onChange func(), extra ...fyne.CanvasObject) (fyne.CanvasObject, *shortcutEntry) {
+	set := filterSetter(mu, onChange)
+	searchEntry := newShortcutEntry()
+	searchEntry.PlaceHolder = i18n.T("search.placeholder")
+	searchEntry.SetText(f.Text)
+	searchEntry.OnChanged = func(s string) {
+		set(func() { f.Text = s })
+	}
+
+	fromEntry := newShortcutEntry()
+	fromEntry.PlaceHolder = i18n.T("search.from")
+	fromEntry.OnChanged = func(s string) {
+		set(func() { f.From = filterDate(s) })
+	}
+
+	toEntry := newShortcutEntry()
+	toEntry.PlaceHolder = i18n.T("search.to")
+	toEntry.OnChanged = func(s string) {
+		set(func() { f.To = filterDate(s) })
+	}
+
+	prioritySelect := widget.NewSelect(append([]string{i18n.T("search.all")}, priorities.Names()...), func(s string) {
+		p, err := priorities.Parse(s)
+		set(func() {
+			f.Priority = nil
+			if err == nil {
+				f.Priority = &p
+			}
+		})
+	})
+	prioritySelect.PlaceHolder = i18n.T("common.priority")
+
+	tagEntry := newShortcutEntry()
+	tagEntry.PlaceHolder = i18n.T("search.tag")
+	tagEntry.OnChanged = func(s string) {
+		set(func() { f.Tag = s })
+	}
+
+	filters := container.NewGridWithColumns(4+len(extra),
+		append([]fyne.CanvasObject{fromEntry, toEntry, prioritySelect, tagEntry}, extra...)...)
+	return container.NewVBox(searchEntry, filters), searchEntry
+}
+
+// Auswahl für den Erledigt-Status von Aufgaben, wie newFilterBar
+func newCompletedSelect(f *store.Filter, mu sync.Locker, onChange func()) *widget.Select {
+	set := filterSetter(mu, onChange)
+	all, done := i18n.T("search.all"), i18n.T("common.completed")
+	statusSelect := widget.NewSelect([]string{all, i18n.T("lists.notCompleted"), done}, func(s string) {
+		set(func() {
+			f.Completed = nil
+			if s != all {
+				completed := s == done
+				f.Completed = &completed
+			}
+		})
+	})
+	statusSelect.PlaceHolder = i18n.T("common.status")
+	return statusSelect
+}
+
+// filterSetter liefert eine Funktion, die den Filter unter mu ändert und
+// danach onChange aufruft
+func filterSetter(mu sync.Locker, onChange func()) func(change func()) {
+	return func(change func()) {
+		mu.Lock()
+		change()
+		mu.Unlock()
+		onChange()
+	}
+}
+
+// Wandelt eine (eventuell unvollständige) Datumseingabe in ISO um.
+// Unvollständige Eingaben filtern nicht.
+func filterDate(s string) string {
+	iso := dates.Parse(s)
+	if _, err := time.Parse("2006-01-02", iso); err != nil {
+		return ""
+	}
+	return iso
+}
+
+// Richtet eine Kopfzeile ein, deren Spalten sich per Klick sortieren lassen.
+// Spalten ohne Sortierschlüssel (leerer String) sind nicht klickbar. Der
+// Filter wird wie bei newFilterBar unter mu geändert.
+func setSortableHeader(table *widget.Table, titles, sortKeys []string, f *store.Filter, mu sync.Locker, onChange func()) {
+	set := filterSetter(mu, onChange)
+	table.ShowHeaderRow = true
+	table.CreateHeader = func() fyne.CanvasObject {
+		b := widget.NewButton("", nil)
+		b.Importance = widget.LowImportance
+		return b
+	}
+	table.UpdateHeader = func(id widget.TableCellID, cell fyne.CanvasObject) {
+		b := cell.(*widget.Button)
+		if id.Col < 0 || id.Col >= len(titles) {
+			b.SetText("")
+			b.Disable()
+			return
+		}
+
+		key := ""
+		if id.Col < len(sortKeys) {
+			key = sortKeys[id.Col]
+		}
+		text := titles[id.Col]
+		mu.Lock()
+		sortBy, desc := f.SortBy, f.Desc
+		mu.Unlock()
+		if key != "" && sortBy == key {
+			if desc {
+				text += " ▼"
+			} else {
+				text += " ▲"
+			}
+		}
+		b.SetText(text)
+
+		if key == "" {
+			b.OnTapped = nil
+			b.Disable()
+			return
+		}
+		b.Enable()
+		b.OnTapped = func() {
+			set(func() {
+				if f.SortBy == key {
+					f.Desc = !f.Desc
+				} else {
+					f.SortBy = key
+					f.Desc = false
+				}
+			})
+		}
+	}
+}
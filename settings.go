@@ -0,0 +1,305 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/assets"
+	"Reminder_Erinnerungs_App/internal/config"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/paths"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/screens"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Zeigt die Einstellungen aus der Konfigurationsdatei an und schreibt sie
+// beim Speichern zurück. Umgebungsvariablen und -db werden dabei nicht in
+// die Datei übernommen.
+func showSettings(w fyne.Window) {
+	configPath := configFlags.Path()
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	dbEntry := widget.NewEntry()
+	dbEntry.PlaceHolder = paths.DefaultDB()
+	dbEntry.SetText(cfg.DBPath)
+	localeSelect := widget.NewSelect(localeLabels(), nil)
+	localeSelect.SetSelected(localeLabel(cfg.Locale))
+	themeSelect := widget.NewSelect(themes.labels(), nil)
+	themeSelect.SetSelected(themes.label(cfg.Window.Theme))
+	quietEntry := widget.NewEntry()
+	quietEntry.PlaceHolder = i18n.T("settings.quietHoursPlaceholder")
+	quietEntry.SetText(cfg.QuietHours)
+	notifiersEntry := widget.NewEntry()
+	notifiersEntry.SetText(strings.Join(cfg.Notifiers, ", "))
+	alarmsEntry := widget.NewEntry()
+	alarmsEntry.PlaceHolder = i18n.T("settings.alarmsPlaceholder")
+	alarmsEntry.SetText(formatMinutes(cfg.Reminders.Alarms))
+	autoCloseEntry := newNumberEntry(cfg.Reminders.AutoClose)
+	widthEntry := newNumberEntry(cfg.Window.Width)
+	heightEntry := newNumberEntry(cfg.Window.Height)
+	xEntry := newNumberEntry(cfg.Window.X)
+	yEntry := newNumberEntry(cfg.Window.Y)
+	windowMonitorEntry := newMonitorEntry(cfg.Window.Monitor, i18n.T("settings.monitorPlaceholder"))
+	reminderMonitorEntry := newMonitorEntry(cfg.Reminders.Monitor, i18n.T("settings.reminderMonitorPlaceholder"))
+	focusCheck := widget.NewCheck(i18n.T("settings.focusCheck"), nil)
+	focusCheck.SetChecked(cfg.Reminders.StealFocus)
+	trayCheck := widget.NewCheck(i18n.T("settings.trayCheck"), nil)
+	trayCheck.SetChecked(cfg.Window.CloseToTray)
+	davURLEntry := widget.NewEntry()
+	davURLEntry.PlaceHolder = i18n.T("settings.caldavServerPlaceholder")
+	davURLEntry.SetText(cfg.CalDAV.URL)
+	davUserEntry := widget.NewEntry()
+	davUserEntry.SetText(cfg.CalDAV.Username)
+	davPasswordEntry := widget.NewPasswordEntry()
+	davPasswordEntry.SetText(cfg.CalDAV.Password)
+	davCalendarEntry := widget.NewEntry()
+	davCalendarEntry.PlaceHolder = i18n.T("settings.calendarPlaceholder")
+	davCalendarEntry.SetText(cfg.CalDAV.Calendar)
+	davTasksEntry := widget.NewEntry()
+	davTasksEntry.PlaceHolder = i18n.T("settings.tasksCalendarPlaceholder")
+	davTasksEntry.SetText(cfg.CalDAV.TasksCalendar)
+	davIntervalEntry := newNumberEntry(cfg.CalDAV.Interval)
+	davConflictSelect := widget.NewSelect(conflicts.labels(), nil)
+	davConflictSelect.SetSelected(conflicts.label(cfg.CalDAV.Conflict))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("settings.database"), dbEntry),
+		widget.NewFormItem(i18n.T("settings.language"), localeSelect),
+		widget.NewFormItem(i18n.T("settings.theme"), themeSelect),
+		widget.NewFormItem(i18n.T("settings.quietHours"), quietEntry),
+		widget.NewFormItem(i18n.T("settings.notifiers"), notifiersEntry),
+		widget.NewFormItem(i18n.T("settings.alarms"), alarmsEntry),
+		widget.NewFormItem(i18n.T("settings.autoClose"), autoCloseEntry),
+		widget.NewFormItem(i18n.T("settings.reminderMonitor"), reminderMonitorEntry),
+		widget.NewFormItem(i18n.T("settings.focus"), focusCheck),
+		widget.NewFormItem(i18n.T("settings.windowWidth"), widthEntry),
+		widget.NewFormItem(i18n.T("settings.windowHeight"), heightEntry),
+		widget.NewFormItem(i18n.T("settings.windowX"), xEntry),
+		widget.NewFormItem(i18n.T("settings.windowY"), yEntry),
+		widget.NewFormItem(i18n.T("settings.monitor"), windowMonitorEntry),
+		widget.NewFormItem(i18n.T("settings.tray"), trayCheck),
+		widget.NewFormItem(i18n.T("settings.caldavServer"), davURLEntry),
+		widget.NewFormItem(i18n.T("settings.user"), davUserEntry),
+		widget.NewFormItem(i18n.T("settings.password"), davPasswordEntry),
+		widget.NewFormItem(i18n.T("settings.calendar"), davCalendarEntry),
+		widget.NewFormItem(i18n.T("settings.tasksCalendar"), davTasksEntry),
+		widget.NewFormItem(i18n.T("settings.syncInterval"), davIntervalEntry),
+		widget.NewFormItem(i18n.T("settings.conflicts"), davConflictSelect),
+	}
+
+	d := dialog.NewForm(i18n.T("settings.title"), i18n.T("common.save"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		oldDBPath, oldLanguage := cfg.DBPath, i18n.Match(cfg.Locale)
+		cfg.DBPath = strings.TrimSpace(dbEntry.Text)
+		cfg.Locale = localeValue(localeSelect.Selected)
+		cfg.Window.Theme = themes.value(themeSelect.Selected)
+		cfg.QuietHours = strings.TrimSpace(quietEntry.Text)
+		cfg.Notifiers = splitList(notifiersEntry.Text)
+		cfg.Window.CloseToTray = trayCheck.Checked
+		cfg.Reminders.StealFocus = focusCheck.Checked
+		cfg.Window.Monitor = strings.TrimSpace(windowMonitorEntry.Text)
+		cfg.Reminders.Monitor = strings.TrimSpace(reminderMonitorEntry.Text)
+		cfg.CalDAV.URL = strings.TrimSpace(davURLEntry.Text)
+		cfg.CalDAV.Username = strings.TrimSpace(davUserEntry.Text)
+		cfg.CalDAV.Password = davPasswordEntry.Text
+		cfg.CalDAV.Calendar = strings.TrimSpace(davCalendarEntry.Text)
+		cfg.CalDAV.TasksCalendar = strings.TrimSpace(davTasksEntry.Text)
+		cfg.CalDAV.Conflict = conflicts.value(davConflictSelect.Selected)
+
+		var err error
+		if cfg.Reminders.Alarms, err = parseMinutes(alarmsEntry.Text); err == nil {
+			for _, f := range []struct {
+				entry *widget.Entry
+				value *int
+			}{
+				{autoCloseEntry, &cfg.Reminders.AutoClose},
+				{widthEntry, &cfg.Window.Width},
+				{heightEntry, &cfg.Window.Height},
+				{xEntry, &cfg.Window.X},
+				{yEntry, &cfg.Window.Y},
+				{davIntervalEntry, &cfg.CalDAV.Interval},
+			} {
+				if *f.value, err = strconv.Atoi(strings.TrimSpace(f.entry.Text)); err != nil {
+					err = errors.New(i18n.T("settings.invalidNumber", "Value", f.entry.Text))
+					break
+				}
+			}
+		}
+		if err == nil {
+			err = config.Save(configPath, cfg)
+		}
+		// Wirksam wird wie beim Start die Datei samt Umgebungsvariablen und -db
+		var live config.Config
+		if err == nil {
+			log.Printf("Konfiguration gespeichert: %s", configPath)
+			live, err = configFlags.Load()
+		}
+		if err == nil {
+			err = reminderService.SetConfig(live)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
+		appConfig = live
+		refreshSyncStatus()
+		// Das Farbschema gilt sofort für alle offenen Fenster
+		fyne.CurrentApp().Settings().SetTheme(assets.NewTheme(cfg.Window.Theme))
+		// SetConfig hat die Sprache umgestellt: neue Dialoge und Erinnerungen
+		// erscheinen sofort darin, bereits aufgebaute Fenster erst nach einem
+		// Neustart
+		var notes []string
+		if i18n.Language() != oldLanguage {
+			notes = append(notes, i18n.T("settings.restartLanguage"))
+		}
+		if cfg.DBPath != oldDBPath {
+			notes = append(notes, i18n.T("settings.restartDatabase"))
+		}
+		if len(notes) > 0 {
+			dialog.ShowInformation(i18n.T("settings.title"), strings.Join(notes, "\n"), w)
+		}
+	}, w)
+	d.Resize(fyne.NewSize(500, 750))
+	d.Show()
+}
+
+// Sprachen der Oberfläche mit ihrem eigenen Namen; leer = Systemsprache
+var languageNames = map[string]string{"de": "Deutsch", "en": "English"}
+
+func localeLabels() []string {
+	labels := []string{i18n.T("settings.languageAuto")}
+	for _, lang := range i18n.Languages {
+		labels = append(labels, languageNames[lang])
+	}
+	return labels
+}
+
+func localeLabel(locale string) string {
+	if locale == "" {
+		return i18n.T("settings.languageAuto")
+	}
+	return languageNames[i18n.Match(locale)]
+}
+
+func localeValue(label string) string {
+	for _, lang := range i18n.Languages {
+		if languageNames[lang] == label {
+			return lang
+		}
+	}
+	return ""
+}
+
+// Werte einer Auswahl mit der ID ihres Anzeigetexts; der erste ist der Standard
+type choices []struct{ value, label string }
+
+func (c choices) labels() []string {
+	labels := make([]string, len(c))
+	for i, o := range c {
+		labels[i] = i18n.T(o.label)
+	}
+	return labels
+}
+
+func (c choices) label(value string) string {
+	for _, o := range c {
+		if o.value == value {
+			return i18n.T(o.label)
+		}
+	}
+	return i18n.T(c[0].label)
+}
+
+func (c choices) value(label string) string {
+	for _, o := range c {
+		if i18n.T(o.label) == label {
+			return o.value
+		}
+	}
+	return c[0].value
+}
+
+// Konfliktregeln des CalDAV-Abgleichs
+var conflicts = choices{
+	{config.ConflictServer, "settings.conflictServer"},
+	{config.ConflictLocal, "settings.conflictLocal"},
+	{config.ConflictNewest, "settings.conflictNewest"},
+}
+
+// Farbschemata der Oberfläche
+var themes = choices{
+	{config.ThemeSystem, "settings.themeSystem"},
+	{config.ThemeLight, "settings.themeLight"},
+	{config.ThemeDark, "settings.themeDark"},
+	{config.ThemeHighContrast, "settings.themeHighContrast"},
+}
+
+// Auswahl eines Monitors: primary, mouse oder einer der angeschlossenen
+func newMonitorEntry(value, placeHolder string) *widget.SelectEntry {
+	options := []string{screens.Primary, screens.Mouse}
+	if monitors, err := screens.List(); err == nil {
+		for _, m := range monitors {
+			options = append(options, m.Name)
+		}
+	}
+	e := widget.NewSelectEntry(options)
+	e.PlaceHolder = placeHolder
+	e.SetText(value)
+	return e
+}
+
+func newNumberEntry(n int) *widget.Entry {
+	e := widget.NewEntry()
+	e.SetText(strconv.Itoa(n))
+	return e
+}
+
+// Wandelt eine kommagetrennte Liste in einzelne, getrimmte Einträge um
+func splitList(s string) []string {
+	var list []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+func formatMinutes(minutes []int) string {
+	alarms := make([]priority.Alarm, len(minutes))
+	for i, m := range minutes {
+		alarms[i] = priority.Alarm{Before: time.Duration(m) * time.Minute}
+	}
+	return priority.FormatAlarms(alarms)
+}
+
+// Vorwarnungen für alle Termine gehen nur auf den Bildschirm, andere Wege
+// lassen sich je Prioritätsstufe oder Termin wählen
+func parseMinutes(s string) ([]int, error) {
+	alarms, err := priority.ParseAlarms(s)
+	if err != nil {
+		return nil, err
+	}
+	minutes := make([]int, len(alarms))
+	for i, a := range alarms {
+		if a.Via != "" {
+			return nil, errors.New(i18n.T("settings.alarmWithVia", "Alarm", a))
+		}
+		minutes[i] = a.Minutes()
+	}
+	return minutes, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/feeds"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Standardfarbe für Abos ohne eigene Farbe
+var defaultSubscriptionColor = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+
+func subscriptionColor(sub store.Subscription) color.Color {
+	if sub.Color == "" {
+		return defaultSubscriptionColor
+	}
+	c, err := feeds.ParseColor(sub.Color)
+	if err != nil {
+		return defaultSubscriptionColor
+	}
+	return c
+}
+
+// Farbiges Kästchen vor dem Namen eines Abos bzw. vor abonnierten Terminen
+func newColorMarker() *canvas.Rectangle {
+	r := canvas.NewRectangle(color.Transparent)
+	r.SetMinSize(fyne.NewSize(6, 20))
+	return r
+}
+
+// Zeigt die abonnierten Kalender mit Status und Schaltflächen zum Ändern
+func showSubscriptions(w fyne.Window) {
+	var subs []store.Subscription
+	var d dialog.Dialog
+
+	list := widget.NewList(
+		func() int { return len(subs) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, newColorMarker(),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				),
+				container.NewVBox(widget.NewLabel(""), widget.NewLabel("")))
+		},
+		nil,
+	)
+	reload := func() {
+		var err error
+		if subs, err = dataStore.Subscriptions(); err != nil {
+			dialog.ShowError(err, w)
+		}
+		list.Refresh()
+	}
+	list.UpdateItem = func(i widget.ListItemID, o fyne.CanvasObject) {
+		sub := subs[i]
+		row := o.(*fyne.Container)
+		labels := row.Objects[0].(*fyne.Container)
+		marker := row.Objects[1].(*canvas.Rectangle)
+		buttons := row.Objects[2].(*fyne.Container)
+
+		marker.FillColor = subscriptionColor(sub)
+		marker.Refresh()
+		labels.Objects[0].(*widget.Label).SetText(sub.Name)
+		labels.Objects[1].(*widget.Label).SetText(subscriptionStatus(sub))
+
+		buttons.Objects[0].(*widget.Button).OnTapped = func() {
+			go func() {
+				if err := backend().RefreshSubscription(sub.ID); err != nil {
+					dialog.ShowError(err, w)
+				}
+				reload()
+			}()
+		}
+		buttons.Objects[1].(*widget.Button).OnTapped = func() {
+			editSubscription(w, sub, reload)
+		}
+		buttons.Objects[2].(*widget.Button).OnTapped = func() {
+			dialog.ShowConfirm(i18n.T("subscriptions.remove"),
+				i18n.T("subscriptions.removeConfirm", "Name", sub.Name),
+				func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := backend().DeleteSubscription(sub.ID); err != nil {
+						dialog.ShowError(err, w)
+					}
+					reload()
+				}, w)
+		}
+	}
+	reload()
+
+	add := widget.NewButtonWithIcon(i18n.T("subscriptions.subscribeButton"), theme.ContentAddIcon(), func() {
+		editSubscription(w, store.Subscription{Refresh: time.Hour}, reload)
+	})
+	d = dialog.NewCustom(i18n.T("subscriptions.title"), i18n.T("common.close"), container.NewBorder(nil, add, nil, nil, list), w)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+func subscriptionStatus(sub store.Subscription) string {
+	switch {
+	case sub.LastError != "":
+		return i18n.T("subscriptions.statusError", "Source", sub.Source, "Error", sub.LastError)
+	case sub.LastRefresh.IsZero():
+		return i18n.T("subscriptions.statusNever", "Source", sub.Source)
+	}
+	last := sub.LastRefresh.Local()
+	return i18n.T("subscriptions.statusFetched", "Source", sub.Source,
+		"Date", dates.Format(last.Format("2006-01-02")), "Time", dates.FormatTime(last.Format("15:04")))
+}
+
+// Formular zum Anlegen oder Ändern eines Abos; neue Abos werden sofort abgerufen
+func editSubscription(w fyne.Window, sub store.Subscription, done func()) {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(sub.Name)
+	nameEntry.PlaceHolder = i18n.T("subscriptions.namePlaceholder")
+	sourceEntry := widget.NewEntry()
+	sourceEntry.SetText(sub.Source)
+	sourceEntry.PlaceHolder = i18n.T("subscriptions.sourcePlaceholder")
+	refreshEntry := newNumberEntry(int(sub.Refresh.Minutes()))
+	colorEntry := widget.NewEntry()
+	colorEntry.SetText(sub.Color)
+	colorEntry.PlaceHolder = "#rrggbb"
+	colorButton := widget.NewButton(i18n.T("subscriptions.chooseColor"), func() {
+		picker := dialog.NewColorPicker(i18n.T("subscriptions.color"), "", func(c color.Color) {
+			r, g, b, _ := c.RGBA()
+			colorEntry.SetText(fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8))
+		}, w)
+		picker.Advanced = true
+		picker.Show()
+	})
+	remindCheck := widget.NewCheck(i18n.T("subscriptions.remind"), nil)
+	remindCheck.SetChecked(sub.Remind)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("subscriptions.name"), nameEntry),
+		widget.NewFormItem(i18n.T("subscriptions.source"), sourceEntry),
+		widget.NewFormItem(i18n.T("subscriptions.interval"), refreshEntry),
+		widget.NewFormItem(i18n.T("subscriptions.color"), container.NewBorder(nil, nil, nil, colorButton, colorEntry)),
+		widget.NewFormItem("", remindCheck),
+	}
+	title := i18n.T("subscriptions.subscribe")
+	if sub.ID != 0 {
+		title = i18n.T("subscriptions.edit")
+	}
+	d := dialog.NewForm(title, i18n.T("common.save"), i18n.T("common.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		sub.Source = strings.TrimSpace(sourceEntry.Text)
+		sub.Name = strings.TrimSpace(nameEntry.Text)
+		if sub.Name == "" {
+			sub.Name = feeds.Name(sub.Source)
+		}
+		sub.Color = strings.TrimSpace(colorEntry.Text)
+		sub.Remind = remindCheck.Checked
+
+		minutes, err := strconv.Atoi(strings.TrimSpace(refreshEntry.Text))
+		switch {
+		case sub.Source == "":
+			err = errors.New(i18n.T("subscriptions.sourceMissing"))
+		case err != nil || minutes < 1:
+			err = errors.New(i18n.T("subscriptions.invalidInterval", "Value", refreshEntry.Text))
+		default:
+			_, err = feeds.ParseColor(sub.Color)
+		}
+		if err == nil {
+			sub.Refresh = time.Duration(minutes) * time.Minute
+			if sub.ID == 0 {
+				err = backend().AddSubscription(&sub)
+			} else {
+				err = backend().UpdateSubscription(sub)
+			}
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		done()
+
+		// Neue oder geänderte Quelle gleich abrufen, statt auf den nächsten Durchlauf zu warten
+		go func() {
+			if sub, err := dataStore.GetSubscription(sub.ID); err == nil && sub.Due(time.Now()) {
+				if err := backend().RefreshSubscription(sub.ID); err != nil {
+					dialog.ShowError(err, w)
+				}
+				done()
+			}
+		}()
+	}, w)
+	d.Resize(fyne.NewSize(500, 320))
+	d.Show()
+}
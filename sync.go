@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/davsync"
+	"Reminder_Erinnerungs_App/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Stand des CalDAV-Abgleichs mit Knopf zum sofortigen Abgleich für die
+// Werkzeugleiste, bei laufendem reminderd gleicht er ab. Ohne
+// eingerichteten Server bleibt beides ausgeblendet.
+// refresh übernimmt Änderungen durch andere Prozesse.
+func newSyncStatus(w fyne.Window) (status fyne.CanvasObject, refresh func()) {
+	label := widget.NewLabel("")
+	var button *widget.Button
+	button = widget.NewButton(i18n.T("sync.now"), func() {
+		button.Disable()
+		label.SetText(i18n.T("sync.running"))
+		go func() {
+			defer button.Enable()
+			result, err := backend().SyncNow()
+			if err != nil {
+				dialog.ShowError(err, w)
+			} else {
+				log.Printf("CalDAV-Abgleich: %s", result)
+			}
+			refresh()
+		}()
+	})
+	box := container.NewHBox(label, button)
+
+	refresh = func() {
+		if !appConfig.CalDAV.Enabled() {
+			box.Hide()
+			return
+		}
+		st, err := davsync.ReadStatus(dataStore)
+		if err != nil {
+			log.Printf("%v", err)
+		}
+		label.SetText(syncStatusText(st))
+		box.Show()
+	}
+	refresh()
+	return box, refresh
+}
+
+// Anzeigetext für den Stand des Abgleichs in der eingestellten Sprache
+func syncStatusText(st davsync.Status) string {
+	switch {
+	case st.Error != "":
+		return i18n.T("sync.statusError", "Error", st.Error)
+	case st.LastSync.IsZero():
+		return i18n.T("sync.statusNever")
+	case st.Pending > 0:
+		return i18n.T("sync.statusPending", "Count", st.Pending)
+	}
+	last := st.LastSync.Local()
+	return i18n.T("sync.statusSynced", "Day", dates.FormatDay(last), "Time", dates.FormatTime(last.Format("15:04")))
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"Reminder_Erinnerungs_App/assets"
+	"Reminder_Erinnerungs_App/internal/dates"
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/priority"
+	"Reminder_Erinnerungs_App/internal/reminder"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Abstand, in dem der Countdown im Tray-Menü aktualisiert wird
+const trayRefresh = 30 * time.Second
+
+// So viele Tage im Voraus sucht das Tray-Menü den nächsten Termin
+const trayDays = 7
+
+// Dauer von „Erinnerungen pausieren“
+const pauseDuration = time.Hour
+
+// Ab so kurzer Zeit vor dem nächsten Termin zeigt das Tray-Symbol eine läutende Glocke
+const traySoon = 15 * time.Minute
+
+// runTray richtet das Tray-Symbol ein: nächster Termin mit Countdown,
+// Schnelleingabe, Nicht stören, Pause und Hauptfenster. Das Menü folgt
+// Änderungen, bis ctx beendet wird. Ohne Systemleiste liefert es false.
+func runTray(ctx context.Context, a fyne.App, w fyne.Window) bool {
+	desk, ok := a.(desktop.App)
+	if !ok {
+		return false
+	}
+
+	next := fyne.NewMenuItem("", func() { showWindow(w) })
+	dnd := fyne.NewMenuItem(i18n.T("common.doNotDisturb"), nil)
+	dnd.Action = func() { setDoNotDisturb(!dnd.Checked) }
+	pause := fyne.NewMenuItem("", nil)
+	menu := fyne.NewMenu(i18n.T("main.appTitle"),
+		next,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("tray.addAppointment"), func() { showQuickAdd(a) }),
+		dnd,
+		pause,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("tray.openWindow"), func() { showWindow(w) }),
+	)
+
+	var icon fyne.Resource
+	refresh := func() {
+		now := time.Now()
+		var start time.Time
+		next.Label, start = nextAppointmentLabel(now)
+
+		on, until, err := reminder.DoNotDisturb(dataStore, now)
+		if err != nil {
+			log.Printf("%v", err)
+		}
+		dnd.Checked = on
+
+		// Das Symbol zeigt Nicht stören und bald beginnende Termine an
+		state := assets.TrayIcon
+		switch {
+		case on:
+			state = assets.TrayQuietIcon
+		case !start.IsZero() && start.Sub(now) <= traySoon:
+			state = assets.TraySoonIcon
+		}
+		if state != icon {
+			icon = state
+			desk.SetSystemTrayIcon(icon)
+		}
+
+		// Eine Pause ist Nicht stören mit Ende; kritische Termine kommen durch
+		if on && !until.IsZero() {
+			pause.Label = i18n.T("tray.endPause", "Time", dates.FormatTime(until.Format("15:04")))
+			pause.Action = func() { setDoNotDisturb(false) }
+		} else {
+			pause.Label = i18n.T("tray.pauseHour")
+			pause.Action = func() {
+				if err := reminder.SetDoNotDisturb(backend(), true, pauseDuration); err != nil {
+					log.Printf("%v", err)
+				}
+			}
+		}
+		pause.Disabled = on && until.IsZero()
+		menu.Refresh()
+	}
+	refresh()
+	desk.SetSystemTrayMenu(menu)
+
+	changes, cancel := dataStore.Subscribe()
+	go func() {
+		defer cancel()
+		ticker := time.NewTicker(trayRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case c, ok := <-changes:
+				if !ok {
+					return
+				}
+				if c.Affects(store.TableAppointments) || c.Affects(store.TableSettings) {
+					refresh()
+				}
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+	return true
+}
+
+// showWindow holt das Hauptfenster aus dem Tray nach vorn
+func showWindow(w fyne.Window) {
+	w.Show()
+	w.RequestFocus()
+}
+
+// nextAppointmentLabel beschreibt den nächsten Termin mit Uhrzeit für das
+// Tray-Menü, z.B. „Zahnarzt um 09:30 – in 1 Std. 20 Min.“, und liefert
+// seinen Beginn; ohne Termin ist der Zeitpunkt leer.
+func nextAppointmentLabel(now time.Time) (string, time.Time) {
+	appointments, err := dataStore.QueryAppointments(store.Filter{
+		From:   now.Format("2006-01-02"),
+		To:     now.AddDate(0, 0, trayDays).Format("2006-01-02"),
+		SortBy: store.SortDate,
+	})
+	if err != nil {
+		log.Printf("Fehler beim Abrufen der Termine: %v", err)
+		return i18n.T("tray.unavailable"), time.Time{}
+	}
+	for _, a := range appointments {
+		if a.Time == "" {
+			continue
+		}
+		start, err := time.ParseInLocation("2006-01-02 15:04", a.Date+" "+a.Time, time.Local)
+		if err != nil || start.Before(now.Truncate(time.Minute)) {
+			continue
+		}
+		if a.Date == now.Format("2006-01-02") {
+			return i18n.T("tray.nextToday", "Title", a.Title,
+				"Time", dates.FormatTime(a.Time), "Countdown", countdown(start.Sub(now))), start
+		}
+		return i18n.T("tray.nextLater", "Title", a.Title, "Day", dates.FormatDay(start),
+			"Time", dates.FormatTime(a.Time), "Countdown", countdown(start.Sub(now))), start
+	}
+	return i18n.T("tray.none", "Count", trayDays), time.Time{}
+}
+
+// countdown beschreibt die Zeit bis zu einem Termin, z.B. „in 1 Std. 20 Min.“
+func countdown(d time.Duration) string {
+	minutes := int(math.Ceil(d.Minutes()))
+	switch {
+	case minutes <= 0:
+		return i18n.T("tray.now")
+	case minutes < 60:
+		return i18n.T("tray.inMinutes", "Count", minutes)
+	case minutes < 24*60:
+		if minutes%60 == 0 {
+			return i18n.T("tray.inHours", "Count", minutes/60)
+		}
+		return i18n.T("tray.inHoursMinutes", "Hours", minutes/60, "Minutes", minutes%60)
+	}
+	return i18n.T("tray.inDays", "Count", minutes/(24*60))
+}
+
+// showQuickAdd öffnet ein kleines Fenster, um aus dem Tray einen Termin
+// anzulegen, ohne das Hauptfenster zu öffnen. Enter im Titel speichert.
+func showQuickAdd(a fyne.App) {
+	w := a.NewWindow(i18n.T("tray.quickAdd"))
+
+	now := time.Now()
+	titleEntry := widget.NewEntry()
+	titleEntry.PlaceHolder = i18n.T("common.title")
+	dateEntry := widget.NewEntry()
+	dateEntry.PlaceHolder = i18n.T("common.datePlaceholder")
+	dateEntry.SetText(dates.Format(now.Format("2006-01-02")))
+	timeEntry := widget.NewEntry()
+	timeEntry.PlaceHolder = i18n.T("common.timePlaceholder")
+	timeEntry.SetText(nextQuarterHour(now).Format("15:04"))
+	defaultPriority := priority.Normal
+	prioritySelect := newPrioritySelect(&defaultPriority)
+
+	save := func() {
+		appointment := store.Appointment{
+			Title:    strings.TrimSpace(titleEntry.Text),
+			Date:     dates.Parse(strings.TrimSpace(dateEntry.Text)),
+			Time:     strings.TrimSpace(timeEntry.Text),
+			Priority: selectedPriority(prioritySelect),
+		}
+		var err error
+		switch {
+		case appointment.Title == "":
+			err = errors.New(i18n.T("tray.titleMissing"))
+		case !validDate(appointment.Date):
+			err = errors.New(i18n.T("tray.invalidDate", "Value", dateEntry.Text, "Format", i18n.T("common.datePlaceholder")))
+		case !validTime(appointment.Time):
+			err = errors.New(i18n.T("tray.invalidTime", "Value", timeEntry.Text))
+		default:
+			err = backend().AddAppointment(&appointment)
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		log.Printf("Termin ID=%d über das Tray-Menü hinzugefügt", appointment.ID)
+		w.Close()
+	}
+	titleEntry.OnSubmitted = func(string) { save() }
+
+	w.SetContent(&widget.Form{
+		Items: []*widget.FormItem{
+			widget.NewFormItem(i18n.T("common.title"), titleEntry),
+			widget.NewFormItem(i18n.T("common.date"), dateEntry),
+			widget.NewFormItem(i18n.T("common.time"), timeEntry),
+			widget.NewFormItem(i18n.T("common.priority"), prioritySelect),
+		},
+		OnSubmit:   save,
+		OnCancel:   w.Close,
+		SubmitText: i18n.T("common.add"),
+		CancelText: i18n.T("common.cancel"),
+	})
+	w.Resize(fyne.NewSize(360, 0))
+	w.Show()
+	w.Canvas().Focus(titleEntry)
+}
+
+func validDate(isoDate string) bool {
+	_, err := time.Parse("2006-01-02", isoDate)
+	return err == nil
+}
+
+func validTime(hhmm string) bool {
+	_, err := time.Parse("15:04", hhmm)
+	return err == nil && len(hhmm) == 5
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"Reminder_Erinnerungs_App/internal/i18n"
+	"Reminder_Erinnerungs_App/internal/store"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// So lange bleibt eine Meldung am unteren Fensterrand stehen
+const toastDuration = 8 * time.Second
+
+// Zeigt eine Meldung mit optionaler Schaltfläche am unteren Fensterrand,
+// gesetzt von newToast
+var showToast = func(text, action string, run func()) {}
+
+// Erstellt die Meldungsleiste am unteren Fensterrand. Sie verschwindet nach
+// toastDuration oder wenn die Schaltfläche gedrückt wird.
+func newToast() (bar fyne.CanvasObject, show func(text, action string, run func())) {
+	label := widget.NewLabel("")
+	button := widget.NewButton("", nil)
+	box := container.NewVBox(widget.NewSeparator(), container.NewHBox(label, layout.NewSpacer(), button))
+	box.Hide()
+
+	// Nur die zuletzt gezeigte Meldung blendet sich aus
+	var mu sync.Mutex
+	generation := 0
+	show = func(text, action string, run func()) {
+		mu.Lock()
+		generation++
+		current := generation
+		mu.Unlock()
+
+		label.SetText(text)
+		button.SetText(action)
+		button.OnTapped = func() {
+			box.Hide()
+			run()
+		}
+		if run == nil {
+			button.Hide()
+		} else {
+			button.Show()
+		}
+		box.Show()
+		time.AfterFunc(toastDuration, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if current == generation {
+				box.Hide()
+			}
+		})
+	}
+	return box, show
+}
+
+// Meldung nach einer Änderung mit „Rückgängig“ für genau diese Änderung
+// op, auch wenn inzwischen ein anderes Programm etwas geändert hat
+func showUndoToast(text string, op store.Operation, w fyne.Window) {
+	if op.ID == 0 {
+		showToast(text, "", nil)
+		return
+	}
+	showToast(text, i18n.T("undo.undo"), func() {
+		undoStep(w, func() (store.Operation, error) { return backend().UndoOp(op.ID) })
+	})
+}
+
+// Macht die letzte Änderung rückgängig und bietet an, sie wiederherzustellen
+func undo(w fyne.Window) {
+	undoStep(w, backend().Undo)
+}
+
+// Stellt die zuletzt rückgängig gemachte Änderung wieder her
+func redo(w fyne.Window) {
+	redoStep(w, backend().Redo)
+}
+
+// Rückgängig mit step; danach lässt sich genau diese Änderung wiederherstellen
+func undoStep(w fyne.Window, step func() (store.Operation, error)) {
+	op, err := step()
+	if errors.Is(err, store.ErrNothingToUndo) {
+		showToast(i18n.T("undo.nothingToUndo"), "", nil)
+		return
+	}
+	if errors.Is(err, store.ErrUndoConflict) {
+		showToast(i18n.T("undo.conflict"), "", nil)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	showToast(i18n.T("undo.undone", "What", op.String()), i18n.T("undo.redo"), func() {
+		redoStep(w, func() (store.Operation, error) { return backend().RedoOp(op.ID) })
+	})
+}
+
+// Wiederherstellen mit step; danach lässt sich genau diese Änderung wieder
+// rückgängig machen
+func redoStep(w fyne.Window, step func() (store.Operation, error)) {
+	op, err := step()
+	if errors.Is(err, store.ErrNothingToRedo) {
+		showToast(i18n.T("undo.nothingToRedo"), "", nil)
+		return
+	}
+	if errors.Is(err, store.ErrUndoConflict) {
+		showToast(i18n.T("undo.conflict"), "", nil)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	showToast(i18n.T("undo.redone", "What", op.String()), i18n.T("undo.undo"), func() {
+		undoStep(w, func() (store.Operation, error) { return backend().UndoOp(op.ID) })
+	})
+}